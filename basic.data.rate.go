@@ -1,8 +1,11 @@
 package lingxing
 
 import (
+	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/lingxing/constant"
 	jsoniter "github.com/json-iterator/go"
+	"strings"
 )
 
 // 费率
@@ -56,3 +59,79 @@ func (s basicDataService) Rates(params RatesQueryParams) (items []Rate, nextOffs
 	}
 	return
 }
+
+// CurrencyConverter 币种转换器
+// 基于月度汇率（兑人民币）进行币种转换，优先使用我的汇率，未设置时使用官方汇率
+type CurrencyConverter struct {
+	rates map[string]map[string]float64 // 汇率月份 => 币种 => 汇率
+}
+
+func NewCurrencyConverter(rates ...Rate) *CurrencyConverter {
+	c := &CurrencyConverter{rates: make(map[string]map[string]float64)}
+	return c.Add(rates...)
+}
+
+// Add 添加汇率数据，相同月份和币种的汇率将被覆盖
+func (c *CurrencyConverter) Add(rates ...Rate) *CurrencyConverter {
+	for _, rate := range rates {
+		month := rateMonth(rate.Date)
+		v := rate.MyRate
+		if v <= 0 {
+			v = rate.RateOrg
+		}
+		if month == "" || v <= 0 {
+			continue
+		}
+		if _, ok := c.rates[month]; !ok {
+			c.rates[month] = make(map[string]float64)
+		}
+		c.rates[month][strings.ToUpper(rate.Code)] = v
+	}
+	return c
+}
+
+// Rate 获取指定月份币种兑人民币的汇率，当月不存在时使用之前最近月份的汇率
+// month 支持 Y-m 格式，或者以 Y-m 开头的日期时间字符串
+func (c *CurrencyConverter) Rate(currency, month string) (rate float64, err error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == constant.CNY {
+		return 1, nil
+	}
+
+	month = rateMonth(month)
+	matchedMonth := ""
+	for m, rates := range c.rates {
+		if _, ok := rates[currency]; ok && m <= month && m > matchedMonth {
+			matchedMonth = m
+		}
+	}
+	if matchedMonth == "" {
+		return 0, fmt.Errorf("lingxing: %s 币种在 %s 月份的汇率不存在", currency, month)
+	}
+	return c.rates[matchedMonth][currency], nil
+}
+
+// Convert 将金额从 from 币种转换为 to 币种
+func (c *CurrencyConverter) Convert(amount float64, from, to, month string) (float64, error) {
+	if amount == 0 || strings.EqualFold(from, to) {
+		return amount, nil
+	}
+
+	fromRate, err := c.Rate(from, month)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := c.Rate(to, month)
+	if err != nil {
+		return 0, err
+	}
+	return amount * fromRate / toRate, nil
+}
+
+func rateMonth(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 7 {
+		return s[0:7]
+	}
+	return s
+}
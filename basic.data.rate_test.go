@@ -2,6 +2,8 @@ package lingxing
 
 import (
 	"github.com/hiscaler/gox/jsonx"
+	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -14,3 +16,33 @@ func TestBasicDataService_Rates(t *testing.T) {
 		t.Log(jsonx.ToPrettyJson(items))
 	}
 }
+
+func TestCurrencyConverter_Convert(t *testing.T) {
+	converter := NewCurrencyConverter(
+		Rate{Date: "2022-10", Code: "USD", RateOrg: 7},
+		Rate{Date: "2022-10", Code: "EUR", RateOrg: 7.7},
+	)
+	tests := []struct {
+		name     string
+		amount   float64
+		from     string
+		to       string
+		month    string
+		expected float64
+		hasError bool
+	}{
+		{"t0", 10, "USD", "CNY", "2022-10", 70, false},
+		{"t1", 70, "CNY", "USD", "2022-10-01 00:00:00", 10, false},
+		{"t2", 10, "EUR", "USD", "2022-12", 11, false},
+		{"t3", 10, "USD", "USD", "2021-01", 10, false},
+		{"t4", 10, "USD", "CNY", "2022-09", 0, true},
+		{"t5", 10, "JPY", "CNY", "2022-10", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := converter.Convert(tt.amount, tt.from, tt.to, tt.month)
+			assert.Equalf(t, tt.hasError, err != nil, "Convert(%v, %s, %s, %s) error", tt.amount, tt.from, tt.to, tt.month)
+			assert.Equalf(t, true, math.Abs(tt.expected-v) < 0.0001, "Convert(%v, %s, %s, %s) = %v", tt.amount, tt.from, tt.to, tt.month, v)
+		})
+	}
+}
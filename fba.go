@@ -4,4 +4,5 @@ package lingxing
 type fbaService struct {
 	Shipment   fbaShipmentService   // FBA 发货单
 	StorageFee fbaStorageFeeService // FBA 仓储费
+	Inventory  fbaInventoryService  // FBA 库存
 }
@@ -0,0 +1,251 @@
+package lingxing
+
+import (
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	jsoniter "github.com/json-iterator/go"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FBA 库存
+type fbaInventoryService service
+
+// FBAInventory FBA 库存
+type FBAInventory struct {
+	SID                         int    `json:"sid"`                            // 店铺 ID
+	SellerName                  string `json:"seller_name"`                    // 店铺名称
+	Country                     string `json:"country"`                        // 国家
+	ASIN                        string `json:"asin"`                           // ASIN
+	MSKU                        string `json:"msku"`                           // MSKU
+	FnSKU                       string `json:"fnsku"`                          // FNSKU
+	SKU                         string `json:"sku"`                            // 本地 SKU
+	ProductName                 string `json:"product_name"`                   // 品名
+	Condition                   string `json:"condition"`                      // 状况
+	AfnFulfillableQuantity      int    `json:"afn_fulfillable_quantity"`       // 可售
+	ReservedCustomerOrders      int    `json:"reserved_customerorders"`        // 待发货
+	ReservedFcTransfers         int    `json:"reserved_fc_transfers"`          // 待调仓
+	ReservedFcProcessing        int    `json:"reserved_fc_processing"`         // 调仓中
+	AfnInboundWorkingQuantity   int    `json:"afn_inbound_working_quantity"`   // 计划入库
+	AfnInboundShippedQuantity   int    `json:"afn_inbound_shipped_quantity"`   // 在途
+	AfnInboundReceivingQuantity int    `json:"afn_inbound_receiving_quantity"` // 入库中
+	AfnUnsellableQuantity       int    `json:"afn_unsellable_quantity"`        // 不可售
+	AfnResearchingQuantity      int    `json:"afn_researching_quantity"`       // 调查中
+	InvAge0To90Days             int    `json:"inv_age_0_to_90_days"`           // 库龄 0-90 天
+	InvAge91To180Days           int    `json:"inv_age_91_to_180_days"`         // 库龄 91-180 天
+	InvAge181To270Days          int    `json:"inv_age_181_to_270_days"`        // 库龄 181-270 天
+	InvAge271To365Days          int    `json:"inv_age_271_to_365_days"`        // 库龄 271-365 天
+	InvAge365PlusDays           int    `json:"inv_age_365_plus_days"`          // 库龄 365 天以上
+	UpdateTime                  string `json:"update_time"`                    // 更新时间
+}
+
+// ReservedQuantity 预留量（待发货 + 待调仓 + 调仓中）
+func (m FBAInventory) ReservedQuantity() int {
+	return m.ReservedCustomerOrders + m.ReservedFcTransfers + m.ReservedFcProcessing
+}
+
+// InboundQuantity 入库量（计划入库 + 在途 + 入库中）
+func (m FBAInventory) InboundQuantity() int {
+	return m.AfnInboundWorkingQuantity + m.AfnInboundShippedQuantity + m.AfnInboundReceivingQuantity
+}
+
+// TotalQuantity 亚马逊仓库中的库存总量（可售 + 预留 + 不可售 + 调查中，不含入库量）
+func (m FBAInventory) TotalQuantity() int {
+	return m.AfnFulfillableQuantity + m.ReservedQuantity() + m.AfnUnsellableQuantity + m.AfnResearchingQuantity
+}
+
+// AgedQuantity 库龄超过 181 天的库存量
+func (m FBAInventory) AgedQuantity() int {
+	return m.InvAge181To270Days + m.InvAge271To365Days + m.InvAge365PlusDays
+}
+
+type FBAInventoriesQueryParams struct {
+	Paging
+	SIDs  string `json:"sid,omitempty"`   // 店铺 ID（多个使用英文逗号分隔）
+	MSKU  string `json:"msku,omitempty"`  // MSKU
+	FnSKU string `json:"fnsku,omitempty"` // FNSKU
+	SKU   string `json:"sku,omitempty"`   // 本地 SKU
+}
+
+func (m FBAInventoriesQueryParams) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SIDs, validation.When(m.SIDs != "", validation.By(func(value interface{}) error {
+			for _, v := range strings.Split(value.(string), ",") {
+				if sid, err := strconv.Atoi(strings.TrimSpace(v)); err != nil || sid <= 0 {
+					return fmt.Errorf("无效的店铺 ID %s", v)
+				}
+			}
+			return nil
+		}))),
+	)
+}
+
+// All 查询 FBA 库存
+func (s fbaInventoryService) All(params FBAInventoriesQueryParams) (items []FBAInventory, nextOffset int, isLastPage bool, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	params.SetPagingVars()
+	res := struct {
+		NormalResponse
+		Data []FBAInventory `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(params).
+		Post("/routing/fba/fbaStock/fbaList")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		items = res.Data
+		nextOffset = params.nextOffset
+		isLastPage = len(items) < params.Limit
+	}
+	return
+}
+
+// FBA 库存快照
+
+// FBAInventorySnapshot FBA 库存快照
+type FBAInventorySnapshot struct {
+	Time  time.Time      `json:"time"`  // 快照时间
+	Items []FBAInventory `json:"items"` // 库存明细（按店铺 ID、MSKU、FNSKU、状况排序）
+}
+
+func fbaInventoryKey(item FBAInventory) string {
+	return strings.Join([]string{
+		strings.TrimSpace(strings.ToUpper(item.MSKU)),
+		strings.TrimSpace(strings.ToUpper(item.FnSKU)),
+		strings.TrimSpace(strings.ToUpper(item.Condition)),
+	}, "\x00")
+}
+
+// NewFBAInventorySnapshot 根据 FBA 库存生成快照
+func NewFBAInventorySnapshot(t time.Time, items ...FBAInventory) FBAInventorySnapshot {
+	snapshot := FBAInventorySnapshot{Time: t, Items: make([]FBAInventory, len(items))}
+	copy(snapshot.Items, items)
+	sort.SliceStable(snapshot.Items, func(i, j int) bool {
+		a, b := snapshot.Items[i], snapshot.Items[j]
+		if a.SID != b.SID {
+			return a.SID < b.SID
+		}
+		return fbaInventoryKey(a) < fbaInventoryKey(b)
+	})
+	return snapshot
+}
+
+// FBAInventoryChange FBA 库存变化（当前快照 - 上一快照）
+type FBAInventoryChange struct {
+	SID                     int    `json:"sid"`                       // 店铺 ID
+	MSKU                    string `json:"msku"`                      // MSKU
+	FnSKU                   string `json:"fnsku"`                     // FNSKU
+	Condition               string `json:"condition"`                 // 状况
+	SKU                     string `json:"sku"`                       // 本地 SKU
+	IsNew                   bool   `json:"is_new"`                    // 是否为新增（上一快照中不存在）
+	IsRemoved               bool   `json:"is_removed"`                // 是否已移除（当前快照中不存在）
+	FulfillableQuantity     int    `json:"fulfillable_quantity"`      // 可售变化量
+	ReservedQuantity        int    `json:"reserved_quantity"`         // 预留变化量
+	InboundQuantity         int    `json:"inbound_quantity"`          // 入库变化量
+	UnfulfillableQuantity   int    `json:"unfulfillable_quantity"`    // 不可售变化量
+	AgedQuantity            int    `json:"aged_quantity"`             // 库龄超过 181 天的库存变化量
+	TotalQuantity           int    `json:"total_quantity"`            // 库存总量变化量
+	PreviousTotalQuantity   int    `json:"previous_total_quantity"`   // 上一快照库存总量
+	CurrentTotalQuantity    int    `json:"current_total_quantity"`    // 当前快照库存总量
+	PreviousInboundQuantity int    `json:"previous_inbound_quantity"` // 上一快照入库量
+	CurrentInboundQuantity  int    `json:"current_inbound_quantity"`  // 当前快照入库量
+}
+
+// IsChanged 是否有变化
+func (c FBAInventoryChange) IsChanged() bool {
+	return c.IsNew || c.IsRemoved ||
+		c.FulfillableQuantity != 0 ||
+		c.ReservedQuantity != 0 ||
+		c.InboundQuantity != 0 ||
+		c.UnfulfillableQuantity != 0 ||
+		c.AgedQuantity != 0 ||
+		c.TotalQuantity != 0
+}
+
+func newFBAInventoryChange(previous, current FBAInventory) FBAInventoryChange {
+	item := current
+	if item.MSKU == "" && item.FnSKU == "" {
+		item = previous
+	}
+	return FBAInventoryChange{
+		SID:                     item.SID,
+		MSKU:                    item.MSKU,
+		FnSKU:                   item.FnSKU,
+		Condition:               item.Condition,
+		SKU:                     item.SKU,
+		FulfillableQuantity:     current.AfnFulfillableQuantity - previous.AfnFulfillableQuantity,
+		ReservedQuantity:        current.ReservedQuantity() - previous.ReservedQuantity(),
+		InboundQuantity:         current.InboundQuantity() - previous.InboundQuantity(),
+		UnfulfillableQuantity:   current.AfnUnsellableQuantity - previous.AfnUnsellableQuantity,
+		AgedQuantity:            current.AgedQuantity() - previous.AgedQuantity(),
+		TotalQuantity:           current.TotalQuantity() - previous.TotalQuantity(),
+		PreviousTotalQuantity:   previous.TotalQuantity(),
+		CurrentTotalQuantity:    current.TotalQuantity(),
+		PreviousInboundQuantity: previous.InboundQuantity(),
+		CurrentInboundQuantity:  current.InboundQuantity(),
+	}
+}
+
+// Diff 对比上一快照，返回有变化的库存（按店铺 ID、MSKU、FNSKU、状况排序）
+// 库存以店铺 ID + MSKU + FNSKU + 状况匹配，MSKU、FNSKU 和状况不区分大小写
+func (s FBAInventorySnapshot) Diff(previous FBAInventorySnapshot) []FBAInventoryChange {
+	type key struct {
+		sid int
+		k   string
+	}
+	previousItems := make(map[key]FBAInventory, len(previous.Items))
+	for _, item := range previous.Items {
+		previousItems[key{item.SID, fbaInventoryKey(item)}] = item
+	}
+
+	changes := make([]FBAInventoryChange, 0)
+	matched := make(map[key]bool, len(s.Items))
+	for _, item := range s.Items {
+		k := key{item.SID, fbaInventoryKey(item)}
+		matched[k] = true
+		previousItem, ok := previousItems[k]
+		change := newFBAInventoryChange(previousItem, item)
+		change.IsNew = !ok
+		if change.IsChanged() {
+			changes = append(changes, change)
+		}
+	}
+	for _, item := range previous.Items {
+		k := key{item.SID, fbaInventoryKey(item)}
+		if matched[k] {
+			continue
+		}
+		matched[k] = true
+		change := newFBAInventoryChange(item, FBAInventory{})
+		change.IsRemoved = true
+		changes = append(changes, change)
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.SID != b.SID {
+			return a.SID < b.SID
+		}
+		return fbaInventoryKey(FBAInventory{MSKU: a.MSKU, FnSKU: a.FnSKU, Condition: a.Condition}) < fbaInventoryKey(FBAInventory{MSKU: b.MSKU, FnSKU: b.FnSKU, Condition: b.Condition})
+	})
+	return changes
+}
+
+// Snapshot 查询所有符合条件的 FBA 库存并生成快照
+func (s fbaInventoryService) Snapshot(params FBAInventoriesQueryParams) (snapshot FBAInventorySnapshot, err error) {
+	inventories, err := queryAll(func(offset int) ([]FBAInventory, int, bool, error) {
+		params.Offset = offset
+		return s.All(params)
+	})
+	if err != nil {
+		return
+	}
+	return NewFBAInventorySnapshot(time.Now(), inventories...), nil
+}
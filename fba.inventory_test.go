@@ -0,0 +1,82 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFBAInventory_Quantities(t *testing.T) {
+	item := FBAInventory{
+		AfnFulfillableQuantity:      10,
+		ReservedCustomerOrders:      1,
+		ReservedFcTransfers:         2,
+		ReservedFcProcessing:        3,
+		AfnInboundWorkingQuantity:   4,
+		AfnInboundShippedQuantity:   5,
+		AfnInboundReceivingQuantity: 6,
+		AfnUnsellableQuantity:       7,
+		AfnResearchingQuantity:      1,
+		InvAge181To270Days:          2,
+		InvAge365PlusDays:           1,
+	}
+	assert.Equal(t, 6, item.ReservedQuantity())
+	assert.Equal(t, 15, item.InboundQuantity())
+	assert.Equal(t, 24, item.TotalQuantity())
+	assert.Equal(t, 3, item.AgedQuantity())
+}
+
+func TestFBAInventorySnapshot_Diff(t *testing.T) {
+	previous := NewFBAInventorySnapshot(time.Now().AddDate(0, 0, -1),
+		FBAInventory{SID: 1, MSKU: "A", FnSKU: "X1", AfnFulfillableQuantity: 10, AfnInboundShippedQuantity: 20},
+		FBAInventory{SID: 1, MSKU: "B", FnSKU: "X2", AfnFulfillableQuantity: 5},
+		FBAInventory{SID: 2, MSKU: "C", FnSKU: "X3", AfnFulfillableQuantity: 8},
+	)
+	current := NewFBAInventorySnapshot(time.Now(),
+		FBAInventory{SID: 2, MSKU: "D", FnSKU: "X4", AfnFulfillableQuantity: 3},
+		FBAInventory{SID: 1, MSKU: "a", FnSKU: "x1", AfnFulfillableQuantity: 25, AfnInboundShippedQuantity: 5},
+		FBAInventory{SID: 1, MSKU: "B", FnSKU: "X2", AfnFulfillableQuantity: 5},
+	)
+	assert.Equal(t, "B", current.Items[1].MSKU)
+
+	changes := current.Diff(previous)
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, "a", changes[0].MSKU)
+		assert.Equal(t, 15, changes[0].FulfillableQuantity)
+		assert.Equal(t, -15, changes[0].InboundQuantity)
+		assert.Equal(t, 15, changes[0].TotalQuantity)
+		assert.False(t, changes[0].IsNew)
+
+		assert.Equal(t, "C", changes[1].MSKU)
+		assert.True(t, changes[1].IsRemoved)
+		assert.Equal(t, -8, changes[1].TotalQuantity)
+
+		assert.Equal(t, "D", changes[2].MSKU)
+		assert.True(t, changes[2].IsNew)
+		assert.Equal(t, 3, changes[2].CurrentTotalQuantity)
+	}
+}
+
+func TestFBAInventorySnapshot_DiffByCondition(t *testing.T) {
+	previous := NewFBAInventorySnapshot(time.Now().AddDate(0, 0, -1),
+		FBAInventory{SID: 1, MSKU: "A", FnSKU: "X1", Condition: "New", AfnFulfillableQuantity: 10},
+		FBAInventory{SID: 1, MSKU: "A", FnSKU: "X1", Condition: "UsedLikeNew", AfnFulfillableQuantity: 2},
+	)
+	current := NewFBAInventorySnapshot(time.Now(),
+		FBAInventory{SID: 1, MSKU: "A", FnSKU: "X1", Condition: "new", AfnFulfillableQuantity: 10},
+		FBAInventory{SID: 1, MSKU: "A", FnSKU: "X1", Condition: "UsedLikeNew", AfnFulfillableQuantity: 5},
+	)
+	changes := current.Diff(previous)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "UsedLikeNew", changes[0].Condition)
+		assert.Equal(t, 3, changes[0].FulfillableQuantity)
+		assert.False(t, changes[0].IsNew)
+	}
+}
+
+func TestFBAInventoriesQueryParams_Validate(t *testing.T) {
+	assert.NoError(t, FBAInventoriesQueryParams{}.Validate())
+	assert.NoError(t, FBAInventoriesQueryParams{SIDs: "1, 2"}.Validate())
+	assert.Error(t, FBAInventoriesQueryParams{SIDs: "1,a"}.Validate())
+	assert.Error(t, FBAInventoriesQueryParams{SIDs: "0"}.Validate())
+}
@@ -0,0 +1,559 @@
+package lingxing
+
+import (
+	"fmt"
+	"github.com/hiscaler/gox/inx"
+	"github.com/hiscaler/lingxing/constant"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FBA 补货计划
+// 按店铺 + MSKU 根据日均销量、FBA 库存、入库中的库存和未发货的发货计划（发货单）计算建议发货量，
+// 再按本地 SKU 汇总本地仓库库存和采购交期计算建议采购量
+//
+// 可用供应量 = FBA 可用库存（可售 + 待调仓 + 调仓中）+ FBA 入库量（计划入库 + 在途 + 入库中）+ 未发货的发货计划和发货单数量
+// 建议发货量 = 日均销量 × （头程天数 + 目标可售天数 + 安全天数）- 可用供应量，受本地仓库可用量限制，按单箱数量取整
+// 建议采购量 = 日均销量合计 × （采购交期 + 头程天数 + 目标可售天数 + 安全天数）- 所有店铺的可用供应量 - 本地仓库可用量，按单箱数量向上取整
+//
+// 设置物料清单后，捆绑产品不再直接采购，其需求量、可用供应量和本地仓库可用量按组成数量展开为子产品和辅料的采购需求，
+// 捆绑产品的采购交期取子产品中最长的采购交期
+// 可售天数按可用供应量（包含入库中和未发货的数量）计算，产品表现中的可售天数预估仅基于 FBA 可售库存，只作为参考返回
+
+// ReplenishmentOptions 补货参数
+type ReplenishmentOptions struct {
+	ShippingDays int `json:"shipping_days"` // 头程天数
+	TargetDays   int `json:"target_days"`   // 目标可售天数
+	SafetyDays   int `json:"safety_days"`   // 安全天数
+}
+
+// ReplenishmentShipSuggestion 发货建议
+type ReplenishmentShipSuggestion struct {
+	SID              int     `json:"sid"`               // 店铺 ID
+	MSKU             string  `json:"msku"`              // MSKU
+	FnSKU            string  `json:"fnsku"`             // FNSKU
+	ASIN             string  `json:"asin"`              // ASIN
+	SKU              string  `json:"sku"`               // 本地 SKU
+	DailySales       float64 `json:"daily_sales"`       // 日均销量
+	FBAQuantity      int     `json:"fba_quantity"`      // FBA 可用库存
+	InboundQuantity  int     `json:"inbound_quantity"`  // FBA 入库量
+	PlannedQuantity  int     `json:"planned_quantity"`  // 未发货的发货计划和发货单数量
+	DaysOfCover      float64 `json:"days_of_cover"`     // 可售天数（可用供应量 / 日均销量，无销量时为 -1）
+	AvailableDays    float64 `json:"available_days"`    // 可售天数预估（产品表现）
+	RequiredQuantity int     `json:"required_quantity"` // 需补货量
+	QuantityInCase   int     `json:"quantity_in_case"`  // 单箱数量
+	ShipQuantity     int     `json:"ship_quantity"`     // 建议发货量
+	ShortageQuantity int     `json:"shortage_quantity"` // 本地库存不足的数量
+}
+
+// ReplenishmentPurchaseSuggestion 采购建议
+type ReplenishmentPurchaseSuggestion struct {
+	SKU              string  `json:"sku"`               // 本地 SKU
+	DailySales       float64 `json:"daily_sales"`       // 日均销量（所有店铺）
+	LeadTime         int     `json:"lead_time"`         // 采购交期（天）
+	SupplyQuantity   int     `json:"supply_quantity"`   // 所有店铺的可用供应量
+	LocalQuantity    int     `json:"local_quantity"`    // 本地仓库可用量
+	CgBoxPcs         int     `json:"cg_box_pcs"`        // 单箱数量
+	PurchaseQuantity int     `json:"purchase_quantity"` // 建议采购量
+}
+
+// ReplenishmentPlan 补货计划
+type ReplenishmentPlan struct {
+	Shipments []ReplenishmentShipSuggestion     `json:"shipments"` // 发货建议（按店铺 ID、MSKU 排序）
+	Purchases []ReplenishmentPurchaseSuggestion `json:"purchases"` // 采购建议（按 SKU 排序，仅包含建议采购量大于 0 的 SKU）
+}
+
+type replenishmentListing struct {
+	sid             int
+	msku            string
+	fnSKU           string
+	asin            string
+	sku             string
+	dailySales      float64
+	fbaQuantity     int
+	inboundQuantity int
+	plannedQuantity int
+	quantityInCase  int
+}
+
+type replenishmentProduct struct {
+	sku      string
+	leadTime int
+	cgBoxPcs int
+}
+
+// ReplenishmentPlanner 补货计划器，MSKU、SKU 不区分大小写
+type ReplenishmentPlanner struct {
+	options       ReplenishmentOptions
+	listings      map[string]*replenishmentListing // 店铺 ID + MSKU
+	products      map[string]*replenishmentProduct // SKU
+	localQuantity map[string]int                   // SKU => 本地仓库可用量
+	asinSales     asinSales                        // 店铺 ID + ASIN => 日均销量
+	availableDays map[string]float64               // 店铺 ID + ASIN => 可售天数预估
+	bom           *BOM                             // 物料清单
+}
+
+func NewReplenishmentPlanner(options ReplenishmentOptions) *ReplenishmentPlanner {
+	return &ReplenishmentPlanner{
+		options:       options,
+		listings:      make(map[string]*replenishmentListing),
+		products:      make(map[string]*replenishmentProduct),
+		localQuantity: make(map[string]int),
+		asinSales:     make(asinSales),
+		availableDays: make(map[string]float64),
+	}
+}
+
+func (p *ReplenishmentPlanner) listing(sid int, msku string) *replenishmentListing {
+	key := sidKey(sid, msku)
+	l, ok := p.listings[key]
+	if !ok {
+		l = &replenishmentListing{sid: sid, msku: msku}
+		p.listings[key] = l
+	}
+	return l
+}
+
+func (p *ReplenishmentPlanner) product(sku string) *replenishmentProduct {
+	key := skuKey(sku)
+	v, ok := p.products[key]
+	if !ok {
+		v = &replenishmentProduct{sku: sku}
+		p.products[key] = v
+	}
+	return v
+}
+
+// AddListings 添加店铺的 Listing，用于获取 FBA 库存、入库量和本地 SKU
+func (p *ReplenishmentPlanner) AddListings(sid int, listings ...Listing) {
+	for _, listing := range listings {
+		if listing.SellerSKU == "" || listing.IsDelete {
+			continue
+		}
+		l := p.listing(sid, listing.SellerSKU)
+		l.fnSKU = listing.FnSKU
+		l.asin = listing.ASIN
+		l.sku = listing.LocalSKU
+		l.fbaQuantity = listing.AfnFulfillableQuantity + listing.ReservedFcTransfers + listing.ReservedFcProcessing
+		l.inboundQuantity = listing.AfnInboundWorkingQuantity + listing.AfnInboundShippedQuantity + listing.AfnInboundReceivingQuantity
+	}
+}
+
+// AddProductReports 添加产品表现，days 为产品表现的统计天数，日均销量 = 销量 / days
+// 产品表现按 ASIN 统计，同一店铺下多个 MSKU 对应同一 ASIN 时销量平均分配到各 MSKU
+func (p *ReplenishmentPlanner) AddProductReports(days int, reports ...ProductReport) {
+	if days <= 0 {
+		return
+	}
+	p.asinSales.add(days, reports...)
+	for _, report := range reports {
+		if report.ASIN != "" && report.AvailableDays > 0 {
+			p.availableDays[sidKey(report.SID, report.ASIN)] = report.AvailableDays
+		}
+	}
+}
+
+// SetBOM 设置物料清单，用于将捆绑产品的采购需求展开为子产品和辅料的采购需求
+func (p *ReplenishmentPlanner) SetBOM(bom *BOM) error {
+	if bom != nil {
+		if err := bom.Validate(); err != nil {
+			return err
+		}
+	}
+	p.bom = bom
+	return nil
+}
+
+// components 获取 sku 每单位的采购组成，未设置物料清单或不存在组成关系时返回其自身
+func (p *ReplenishmentPlanner) components(sku string) []BOMComponent {
+	if p.bom != nil {
+		// 物料清单已在 SetBOM 中校验，展开单位数量不会失败
+		if components, err := p.bom.Explode(sku, 1); err == nil {
+			return components
+		}
+	}
+	return []BOMComponent{{SKU: sku, Quantity: 1}}
+}
+
+// SetDailySales 设置店铺 MSKU 的日均销量，设置后将不再使用产品表现计算的日均销量
+func (p *ReplenishmentPlanner) SetDailySales(sid int, msku string, dailySales float64) {
+	l := p.listing(sid, msku)
+	l.dailySales = dailySales
+}
+
+// AddShipmentPlans 添加 FBA 发货计划，待审核和待处理的发货计划计入未发货数量，发货计划的单箱数量用于发货量取整
+func (p *ReplenishmentPlanner) AddShipmentPlans(plans ...FBAShipmentPlan) {
+	for _, plan := range plans {
+		for _, item := range plan.List {
+			if item.MSKU == "" {
+				continue
+			}
+			l := p.listing(item.SID, item.MSKU)
+			if item.QuantityInCase > 0 {
+				l.quantityInCase = item.QuantityInCase
+			}
+			if item.Status == FBAShipmentPlanStatusPendingReview || item.Status == FBAShipmentPlanStatusPending {
+				l.plannedQuantity += item.ShipmentPlanQuantity
+			}
+		}
+	}
+}
+
+// AddShipments 添加 FBA 发货单，待配货和待发货的发货单计入未发货数量（已发货的数量已包含在 FBA 入库量中）
+func (p *ReplenishmentPlanner) AddShipments(shipments ...FBAShipment) {
+	for _, shipment := range shipments {
+		if shipment.Status != ShipmentSheetStatusPendingAllocation && shipment.Status != ShipmentSheetStatusPendingShipment {
+			continue
+		}
+		for _, item := range shipment.RelateList {
+			if item.MSKU == "" {
+				continue
+			}
+			p.listing(item.SId, item.MSKU).plannedQuantity += item.Num
+		}
+	}
+}
+
+// AddWarehouseInventories 添加本地仓库库存，可用量按 SKU 汇总
+func (p *ReplenishmentPlanner) AddWarehouseInventories(inventories ...WarehouseInventory) {
+	for _, inventory := range inventories {
+		if inventory.SKU == "" {
+			continue
+		}
+		p.localQuantity[skuKey(inventory.SKU)] += inventory.ProductValidNum
+	}
+}
+
+// AddProducts 添加本地产品，用于获取采购交期
+func (p *ReplenishmentPlanner) AddProducts(products ...Product) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		p.product(product.SKU).leadTime = product.CgDelivery
+	}
+}
+
+// AddProductDetails 添加本地产品详情，用于获取采购交期和单箱数量
+func (p *ReplenishmentPlanner) AddProductDetails(products ...ProductDetail) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		v := p.product(product.SKU)
+		v.leadTime = product.CgDelivery
+		v.cgBoxPcs = product.CgBoxPcs
+	}
+}
+
+// replenishmentRoundUp 按单箱数量向上取整
+func replenishmentRoundUp(quantity, pcs int) int {
+	if pcs <= 1 || quantity <= 0 {
+		return quantity
+	}
+	return (quantity + pcs - 1) / pcs * pcs
+}
+
+// Plan 生成补货计划
+// 同一 SKU 的本地库存不足时，优先分配给可售天数较少的 MSKU
+func (p *ReplenishmentPlanner) Plan() ReplenishmentPlan {
+	plan := ReplenishmentPlan{
+		Shipments: make([]ReplenishmentShipSuggestion, 0, len(p.listings)),
+		Purchases: make([]ReplenishmentPurchaseSuggestion, 0),
+	}
+
+	asins := make([]string, 0, len(p.listings))
+	for _, l := range p.listings {
+		if l.asin != "" {
+			asins = append(asins, sidKey(l.sid, l.asin))
+		}
+	}
+	asinSales := p.asinSales.split(asins...)
+
+	shipDays := p.options.ShippingDays + p.options.TargetDays + p.options.SafetyDays
+	for _, l := range p.listings {
+		dailySales := l.dailySales
+		availableDays := 0.0
+		if l.asin != "" {
+			key := sidKey(l.sid, l.asin)
+			availableDays = p.availableDays[key]
+			if dailySales == 0 {
+				dailySales = asinSales[key]
+			}
+		}
+		supply := l.fbaQuantity + l.inboundQuantity + l.plannedQuantity
+		s := ReplenishmentShipSuggestion{
+			SID:             l.sid,
+			MSKU:            l.msku,
+			FnSKU:           l.fnSKU,
+			ASIN:            l.asin,
+			SKU:             l.sku,
+			DailySales:      dailySales,
+			FBAQuantity:     l.fbaQuantity,
+			InboundQuantity: l.inboundQuantity,
+			PlannedQuantity: l.plannedQuantity,
+			DaysOfCover:     -1,
+			AvailableDays:   availableDays,
+			QuantityInCase:  l.quantityInCase,
+		}
+		if dailySales > 0 {
+			s.DaysOfCover = float64(supply) / dailySales
+			if required := int(math.Ceil(dailySales*float64(shipDays))) - supply; required > 0 {
+				s.RequiredQuantity = required
+			}
+		}
+		if s.QuantityInCase == 0 && l.sku != "" {
+			if v, ok := p.products[skuKey(l.sku)]; ok {
+				s.QuantityInCase = v.cgBoxPcs
+			}
+		}
+		plan.Shipments = append(plan.Shipments, s)
+	}
+
+	// 按可售天数升序分配本地库存
+	sort.SliceStable(plan.Shipments, func(i, j int) bool {
+		a, b := plan.Shipments[i], plan.Shipments[j]
+		if a.DaysOfCover != b.DaysOfCover {
+			return a.DaysOfCover < b.DaysOfCover
+		}
+		if a.SID != b.SID {
+			return a.SID < b.SID
+		}
+		return strings.ToUpper(a.MSKU) < strings.ToUpper(b.MSKU)
+	})
+	localQuantity := make(map[string]int, len(p.localQuantity))
+	for sku, quantity := range p.localQuantity {
+		localQuantity[sku] = quantity
+	}
+	for i, s := range plan.Shipments {
+		if s.RequiredQuantity == 0 {
+			continue
+		}
+		key := skuKey(s.SKU)
+		available := localQuantity[key]
+		quantity := replenishmentRoundUp(s.RequiredQuantity, s.QuantityInCase)
+		if quantity > available {
+			// 本地库存不足时按整箱向下取整
+			quantity = available
+			if s.QuantityInCase > 1 {
+				quantity = available / s.QuantityInCase * s.QuantityInCase
+			}
+		}
+		localQuantity[key] = available - quantity
+		plan.Shipments[i].ShipQuantity = quantity
+		if quantity < s.RequiredQuantity {
+			plan.Shipments[i].ShortageQuantity = s.RequiredQuantity - quantity
+		}
+	}
+	sort.SliceStable(plan.Shipments, func(i, j int) bool {
+		a, b := plan.Shipments[i], plan.Shipments[j]
+		if a.SID != b.SID {
+			return a.SID < b.SID
+		}
+		return strings.ToUpper(a.MSKU) < strings.ToUpper(b.MSKU)
+	})
+
+	// 按本地 SKU 汇总销量和可用供应量
+	type replenishmentDemand struct {
+		sku        string
+		dailySales float64
+		supply     int
+	}
+	demands := make(map[string]*replenishmentDemand)
+	for _, s := range plan.Shipments {
+		if s.SKU == "" {
+			continue
+		}
+		key := skuKey(s.SKU)
+		d, ok := demands[key]
+		if !ok {
+			d = &replenishmentDemand{sku: s.SKU}
+			demands[key] = d
+		}
+		d.dailySales += s.DailySales
+		d.supply += s.FBAQuantity + s.InboundQuantity + s.PlannedQuantity
+	}
+
+	// 按组成展开为采购需求，捆绑产品的本地仓库可用量计入子产品和辅料的可用供应量
+	purchases := make(map[string]*ReplenishmentPurchaseSuggestion)
+	required := make(map[string]int)
+	for key, d := range demands {
+		components := p.components(d.sku)
+		leadTime := 0
+		for _, c := range components {
+			if c.IsAuxMaterial {
+				continue
+			}
+			if product, ok := p.products[skuKey(c.SKU)]; ok && product.leadTime > leadTime {
+				leadTime = product.leadTime
+			}
+		}
+		quantity := int(math.Ceil(d.dailySales * float64(leadTime+shipDays)))
+		for _, c := range components {
+			k := skuKey(c.SKU)
+			v, ok := purchases[k]
+			if !ok {
+				v = &ReplenishmentPurchaseSuggestion{SKU: c.SKU, LocalQuantity: p.localQuantity[k]}
+				if product, ok := p.products[k]; ok {
+					v.LeadTime = product.leadTime
+					v.CgBoxPcs = product.cgBoxPcs
+				}
+				purchases[k] = v
+			}
+			supply := d.supply
+			if k != key {
+				supply += p.localQuantity[key]
+			}
+			v.DailySales += d.dailySales * float64(c.Quantity)
+			v.SupplyQuantity += supply * c.Quantity
+			required[k] += quantity * c.Quantity
+		}
+	}
+	for key, v := range purchases {
+		quantity := required[key] - v.SupplyQuantity - v.LocalQuantity
+		if quantity <= 0 {
+			continue
+		}
+		v.PurchaseQuantity = replenishmentRoundUp(quantity, v.CgBoxPcs)
+		plan.Purchases = append(plan.Purchases, *v)
+	}
+	sort.Slice(plan.Purchases, func(i, j int) bool {
+		return strings.ToUpper(plan.Purchases[i].SKU) < strings.ToUpper(plan.Purchases[j].SKU)
+	})
+	return plan
+}
+
+// Load 从接口加载店铺的 Listing、指定日期范围（Y-m-d，开始日期为闭区间，结束日期为开区间）内的产品表现、
+// 待审核和待处理的 FBA 发货计划、店铺待配货和待发货的 FBA 发货单、本地产品详情和本地仓库库存
+// 发货计划按状态查询，不限创建日期；本地产品详情需要逐个查询，已设置物料清单时同时加载捆绑产品组成的产品详情
+func (p *ReplenishmentPlanner) Load(client *LingXing, sids []int, startDate, endDate string) error {
+	start, err := time.Parse(constant.DateFormat, startDate)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(constant.DateFormat, endDate)
+	if err != nil {
+		return err
+	}
+	days := int(end.Sub(start).Hours() / 24)
+
+	sellers, err := client.Services.BasicData.Sellers()
+	if err != nil {
+		return err
+	}
+	mids := make([]string, 0)
+	for _, seller := range sellers {
+		if !inx.IntIn(seller.SID, sids...) {
+			continue
+		}
+		if mid := strconv.Itoa(seller.MID); !inx.StringIn(mid, mids...) {
+			mids = append(mids, mid)
+		}
+	}
+
+	skus := make(map[string]string)
+	sidValues := make([]string, 0, len(sids))
+	for _, sid := range sids {
+		sidValues = append(sidValues, strconv.Itoa(sid))
+		listingParams := ListingsQueryParams{SID: sid}
+		listings, e := queryAll(func(offset int) ([]Listing, int, bool, error) {
+			listingParams.Offset = offset
+			return client.Services.Sale.Listing.All(listingParams)
+		})
+		if e != nil {
+			return e
+		}
+		p.AddListings(sid, listings...)
+		for _, listing := range listings {
+			if listing.LocalSKU != "" && !listing.IsDelete {
+				skus[skuKey(listing.LocalSKU)] = listing.LocalSKU
+			}
+		}
+
+		reportParams := ProductStatisticQueryParams{SID: sid, StartDate: startDate, EndDate: endDate}
+		reports, e := queryAll(func(offset int) ([]ProductReport, int, bool, error) {
+			reportParams.Offset = offset
+			return client.Services.Statistic.Products(reportParams)
+		})
+		if e != nil {
+			return e
+		}
+		p.AddProductReports(days, reports...)
+	}
+
+	warehouses, err := client.Services.Warehouse.allWarehouses()
+	if err != nil {
+		return err
+	}
+	wids := make([]string, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		wids = append(wids, strconv.Itoa(warehouse.WID))
+	}
+	planParams := FBAShipmentPlansQueryParams{
+		SIDs:            strings.Join(sidValues, ","),
+		WID:             strings.Join(wids, ","),
+		SearchFieldTime: FBAShipmentPlanSearchFieldTimeCreate,
+		Status:          fmt.Sprintf("%d,%d", FBAShipmentPlanStatusPendingReview, FBAShipmentPlanStatusPending),
+		MIDs:            strings.Join(mids, ","),
+		StartDate:       time.Unix(0, 0).Format(constant.DateFormat),
+		EndDate:         time.Now().AddDate(0, 0, 1).Format(constant.DateFormat),
+	}
+	plans, err := queryAll(func(offset int) ([]FBAShipmentPlan, int, bool, error) {
+		planParams.Offset = offset
+		return client.Services.FBA.Shipment.Plans(planParams)
+	})
+	if err != nil {
+		return err
+	}
+	p.AddShipmentPlans(plans...)
+
+	for _, status := range []ShipmentSheetStatus{ShipmentSheetStatusPendingAllocation, ShipmentSheetStatusPendingShipment} {
+		shipmentParams := FBAShipmentsQueryParams{SIds: sidValues, Status: &status}
+		shipments, e := queryAll(func(offset int) ([]FBAShipment, int, bool, error) {
+			shipmentParams.Offset = offset
+			return client.Services.FBA.Shipment.All(shipmentParams)
+		})
+		if e != nil {
+			return e
+		}
+		p.AddShipments(shipments...)
+	}
+
+	if p.bom != nil {
+		for _, sku := range skus {
+			for _, c := range p.components(sku) {
+				skus[skuKey(c.SKU)] = c.SKU
+			}
+		}
+	}
+	values := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		values = append(values, sku)
+	}
+	sort.Strings(values)
+	products, err := client.Services.Product.BySKUs(values...)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		detail, e := client.Services.Product.One(product.ID)
+		if e != nil {
+			return e
+		}
+		p.AddProductDetails(detail)
+	}
+
+	inventoryParams := WarehouseInventoriesQueryParams{}
+	inventories, err := queryAll(func(offset int) ([]WarehouseInventory, int, bool, error) {
+		inventoryParams.Offset = offset
+		return client.Services.Warehouse.Inventories(inventoryParams)
+	})
+	if err != nil {
+		return err
+	}
+	p.AddWarehouseInventories(inventories...)
+	return nil
+}
@@ -0,0 +1,109 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReplenishmentPlanner_Plan(t *testing.T) {
+	p := NewReplenishmentPlanner(ReplenishmentOptions{ShippingDays: 30, TargetDays: 20, SafetyDays: 10})
+	p.AddListings(1,
+		Listing{SellerSKU: "A-US", FnSKU: "X1", ASIN: "B01", LocalSKU: "A", AfnFulfillableQuantity: 100, ReservedFcTransfers: 20, AfnInboundShippedQuantity: 30},
+		Listing{SellerSKU: "A-US-2", FnSKU: "X2", ASIN: "B01", LocalSKU: "A", AfnFulfillableQuantity: 50},
+		Listing{SellerSKU: "B-US", FnSKU: "X3", ASIN: "B02", LocalSKU: "B", AfnFulfillableQuantity: 10},
+		Listing{SellerSKU: "C-US", FnSKU: "X4", ASIN: "B03", LocalSKU: "C", IsDelete: true},
+	)
+	// B01 日均销量 10，两个 MSKU 各 5；B02 日均销量 2
+	p.AddProductReports(30, ProductReport{SID: 1, ASIN: "B01", Volume: 300}, ProductReport{SID: 1, ASIN: "B02", Volume: 60})
+	p.AddShipmentPlans(FBAShipmentPlan{List: []FBAShipmentPlanItem{
+		{SID: 1, MSKU: "A-US-2", Status: FBAShipmentPlanStatusPending, ShipmentPlanQuantity: 40, QuantityInCase: 25},
+		{SID: 1, MSKU: "A-US-2", Status: FBAShipmentPlanStatusProcessed, ShipmentPlanQuantity: 100},
+	}})
+	p.AddShipments(
+		FBAShipment{Status: ShipmentSheetStatusPendingShipment, RelateList: []FBAShipmentRelateItem{{SId: 1, MSKU: "b-us", Num: 10}}},
+		FBAShipment{Status: ShipmentSheetStatusShipped, RelateList: []FBAShipmentRelateItem{{SId: 1, MSKU: "B-US", Num: 100}}},
+	)
+	p.AddWarehouseInventories(WarehouseInventory{SKU: "A", ProductValidNum: 300}, WarehouseInventory{SKU: "b", ProductValidNum: 50})
+	p.AddProductDetails(ProductDetail{SKU: "A", CgDelivery: 15, CgBoxPcs: 50}, ProductDetail{SKU: "B", CgDelivery: 10, CgBoxPcs: 20})
+
+	plan := p.Plan()
+	if assert.Len(t, plan.Shipments, 3) {
+		// A-US：供应 150，需求 5 × 60 = 300，需补 150，本地库存优先分配给 A-US-2 后剩余 75，按 50 向下取整
+		a := plan.Shipments[0]
+		assert.Equal(t, "A-US", a.MSKU)
+		assert.Equal(t, 5.0, a.DailySales)
+		assert.Equal(t, 30.0, a.DaysOfCover)
+		assert.Equal(t, 150, a.RequiredQuantity)
+		assert.Equal(t, 50, a.ShipQuantity)
+		assert.Equal(t, 100, a.ShortageQuantity)
+
+		// A-US-2：供应 90，需补 210，按发货计划单箱数量 25 取整为 225（可售天数更少，优先分配本地库存）
+		a2 := plan.Shipments[1]
+		assert.Equal(t, "A-US-2", a2.MSKU)
+		assert.Equal(t, 40, a2.PlannedQuantity)
+		assert.Equal(t, 18.0, a2.DaysOfCover)
+		assert.Equal(t, 210, a2.RequiredQuantity)
+		assert.Equal(t, 225, a2.ShipQuantity)
+		assert.Equal(t, 0, a2.ShortageQuantity)
+
+		// B-US：供应 20，需补 100，本地 50，按 20 向下取整为 40
+		b := plan.Shipments[2]
+		assert.Equal(t, 20, b.PlannedQuantity+b.FBAQuantity)
+		assert.Equal(t, 100, b.RequiredQuantity)
+		assert.Equal(t, 40, b.ShipQuantity)
+		assert.Equal(t, 60, b.ShortageQuantity)
+	}
+
+	if assert.Len(t, plan.Purchases, 2) {
+		// A：10 × 75 = 750 - 240 - 300 = 210，按 50 取整为 250
+		assert.Equal(t, "A", plan.Purchases[0].SKU)
+		assert.Equal(t, 250, plan.Purchases[0].PurchaseQuantity)
+		// B：2 × 70 = 140 - 20 - 50 = 70，按 20 取整为 80
+		assert.Equal(t, "B", plan.Purchases[1].SKU)
+		assert.Equal(t, 80, plan.Purchases[1].PurchaseQuantity)
+	}
+}
+
+func TestReplenishmentPlanner_PlanWithBOM(t *testing.T) {
+	p := NewReplenishmentPlanner(ReplenishmentOptions{ShippingDays: 30, TargetDays: 20, SafetyDays: 10})
+	p.AddListings(1,
+		Listing{SellerSKU: "SET-US", ASIN: "B01", LocalSKU: "SET", AfnFulfillableQuantity: 100},
+		Listing{SellerSKU: "A-US", ASIN: "B02", LocalSKU: "A", AfnFulfillableQuantity: 20},
+	)
+	// SET 日均销量 5，A 日均销量 1
+	p.AddProductReports(30,
+		ProductReport{SID: 1, ASIN: "B01", Volume: 150, AvailableDays: 18},
+		ProductReport{SID: 1, ASIN: "B02", Volume: 30},
+	)
+	p.AddWarehouseInventories(
+		WarehouseInventory{SKU: "SET", ProductValidNum: 10},
+		WarehouseInventory{SKU: "A", ProductValidNum: 30},
+	)
+	p.AddProductDetails(
+		ProductDetail{SKU: "A", CgDelivery: 10, CgBoxPcs: 100},
+		ProductDetail{SKU: "B", CgDelivery: 20},
+	)
+	bom := NewBOM()
+	bom.AddBundledProducts(BundledProduct{SKU: "SET", BundledProducts: []BundledProductItem{
+		{SKU: "A", Quantity: 2},
+		{SKU: "B", Quantity: 1},
+	}})
+	assert.NoError(t, p.SetBOM(bom))
+
+	plan := p.Plan()
+	if assert.Len(t, plan.Shipments, 2) {
+		assert.Equal(t, "SET-US", plan.Shipments[1].MSKU)
+		assert.Equal(t, 18.0, plan.Shipments[1].AvailableDays)
+		assert.Equal(t, 20.0, plan.Shipments[1].DaysOfCover)
+	}
+	if assert.Len(t, plan.Purchases, 2) {
+		// SET 按最长交期 20 天：5 × 80 = 400，供应 100 + 本地 10
+		// A：1 × 70 + 400 × 2 = 870 - 20 - 110 × 2 - 30 = 600，按 100 取整
+		assert.Equal(t, "A", plan.Purchases[0].SKU)
+		assert.Equal(t, 11.0, plan.Purchases[0].DailySales)
+		assert.Equal(t, 600, plan.Purchases[0].PurchaseQuantity)
+		// B：400 - 110 = 290
+		assert.Equal(t, "B", plan.Purchases[1].SKU)
+		assert.Equal(t, 290, plan.Purchases[1].PurchaseQuantity)
+	}
+}
@@ -0,0 +1,442 @@
+package lingxing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hiscaler/lingxing/constant"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 到岸成本
+// 单位到岸成本 = 单位采购成本 + 单位头程费用 + 单位关税，金额均为人民币
+// 单位采购成本优先使用已完成入库单的单位入库成本（按入库量加权平均），未入库时使用产品的采购价格和采购运输成本
+// 单位头程费用根据发货单的头程费分配方式分摊到各产品后按发货量加权平均，未发货时使用产品物流关联中的默认头程成本
+// 单位关税 = 单位采购成本 × 产品物流关联中的报关税率（百分比）/ 100
+// 非人民币的头程费用按发货时间所在月份的汇率转换，发货时间为空的头程费用和默认头程成本按计算器的汇率月份转换
+
+// 头程费分配方式
+const (
+	HeadFeeTypeChargeableWeight = 0 // 按计费重
+	HeadFeeTypeActualWeight     = 1 // 按实重
+	HeadFeeTypeVolumetricWeight = 2 // 按体积重
+	HeadFeeTypeQuantity         = 3 // 按 SKU 数量
+	HeadFeeTypeCustom           = 4 // 自定义
+	HeadFeeTypeBoxVolume        = 5 // 按箱子体积
+)
+
+// LandedCostShipmentItem 发货产品
+type LandedCostShipmentItem struct {
+	SKU                  string  `json:"sku"`                    // SKU
+	FnSKU                string  `json:"fnsku"`                  // FNSKU（同一 SKU 发往多个国家时用于匹配装箱产品）
+	Country              string  `json:"country"`                // 国家代码
+	Quantity             int     `json:"quantity"`               // 发货量
+	UnitWeight           float64 `json:"unit_weight"`            // 单位实重（kg）
+	UnitVolumetricWeight float64 `json:"unit_volumetric_weight"` // 单位体积重（kg）
+	CustomFee            float64 `json:"custom_fee"`             // 自定义头程费用（自定义分配方式时使用，币种与头程费用一致）
+}
+
+// LandedCostShipmentBoxItem 装箱产品
+type LandedCostShipmentBoxItem struct {
+	SKU      string `json:"sku"`      // SKU
+	FnSKU    string `json:"fnsku"`    // FNSKU（为空时仅按 SKU 匹配发货产品）
+	Quantity int    `json:"quantity"` // 装箱量
+}
+
+// LandedCostShipmentBox 箱子
+type LandedCostShipmentBox struct {
+	BoxNo  string                      `json:"box_no"` // 箱号
+	Volume float64                     `json:"volume"` // 体积（cm³）
+	Items  []LandedCostShipmentBoxItem `json:"items"`  // 装箱产品
+}
+
+// chargeableWeight 计费重，取实重和体积重中的较大者
+func (item LandedCostShipmentItem) chargeableWeight() float64 {
+	if item.UnitVolumetricWeight > item.UnitWeight {
+		return item.UnitVolumetricWeight
+	}
+	return item.UnitWeight
+}
+
+// LandedCostShipment 发货单
+type LandedCostShipment struct {
+	ShipmentSN      string                   `json:"shipment_sn"`       // 发货单号
+	ShipmentTime    string                   `json:"shipment_time"`     // 发货时间（用于确定汇率月份）
+	HeadFeeType     int                      `json:"head_fee_type"`     // 头程费分配方式（0：按计费重、1：按实重、2：按体积重、3：按SKU数量、4：自定义、5：按箱子体积）
+	HeadFee         float64                  `json:"head_fee"`          // 头程费用（自定义分配方式时不使用）
+	HeadFeeCurrency string                   `json:"head_fee_currency"` // 头程费用币种（为空时为人民币）
+	Items           []LandedCostShipmentItem `json:"items"`             // 发货产品
+	Boxes           []LandedCostShipmentBox  `json:"boxes"`             // 箱子（按箱子体积分配时使用）
+}
+
+// NewLandedCostShipment 根据 FBA 发货单详情生成到岸成本计算使用的发货单，头程费用为人民币
+// countryCodes 为店铺 ID => 国家代码，products 用于计算单位实重（产品毛重）和单位体积重（包装规格长 × 宽 × 高 / 6000），
+// 仅在按重量分配头程费用时需要，自定义分配方式使用发货产品的分摊头程费用
+func NewLandedCostShipment(detail FBAShipmentDetail, countryCodes map[int]string, products ...ProductDetail) LandedCostShipment {
+	details := make(map[string]ProductDetail, len(products))
+	for _, product := range products {
+		details[skuKey(product.SKU)] = product
+	}
+	dimension := func(s string) float64 {
+		v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return v
+	}
+
+	shipment := LandedCostShipment{
+		ShipmentSN:      detail.ShipmentSN,
+		ShipmentTime:    detail.ShipmentTime,
+		HeadFeeType:     detail.HeadFeeType,
+		HeadFee:         detail.HeadFee,
+		HeadFeeCurrency: constant.CNY,
+		Items:           make([]LandedCostShipmentItem, len(detail.Items)),
+		Boxes:           make([]LandedCostShipmentBox, len(detail.Boxes)),
+	}
+	for i, item := range detail.Items {
+		v := LandedCostShipmentItem{
+			SKU:       item.SKU,
+			FnSKU:     item.FnSKU,
+			Country:   countryCodes[item.SID],
+			Quantity:  item.Num,
+			CustomFee: item.HeadFee,
+		}
+		if product, ok := details[skuKey(item.SKU)]; ok {
+			v.UnitWeight = product.CgProductGrossWeight / 1000
+			v.UnitVolumetricWeight = dimension(product.CgPackageLength) * dimension(product.CgPackageWidth) * dimension(product.CgPackageHeight) / 6000
+		}
+		shipment.Items[i] = v
+	}
+	for i, box := range detail.Boxes {
+		v := LandedCostShipmentBox{
+			BoxNo:  box.BoxNo,
+			Volume: box.Length * box.Width * box.Height,
+			Items:  make([]LandedCostShipmentBoxItem, len(box.Items)),
+		}
+		for j, item := range box.Items {
+			v.Items[j] = LandedCostShipmentBoxItem{SKU: item.SKU, FnSKU: item.FnSKU, Quantity: item.Quantity}
+		}
+		shipment.Boxes[i] = v
+	}
+	return shipment
+}
+
+// boxItemIndex 装箱产品对应的发货产品索引，不存在时返回 -1
+func (s LandedCostShipment) boxItemIndex(item LandedCostShipmentBoxItem) int {
+	for i, v := range s.Items {
+		if !strings.EqualFold(v.SKU, item.SKU) {
+			continue
+		}
+		if item.FnSKU == "" || v.FnSKU == "" || strings.EqualFold(v.FnSKU, item.FnSKU) {
+			return i
+		}
+	}
+	return -1
+}
+
+// allocateHeadFeeByBoxVolume 按箱子体积将头程费用分摊到各箱子，箱内按装箱量分摊到各产品
+func allocateHeadFeeByBoxVolume(shipment LandedCostShipment) (fees []float64, err error) {
+	fees = make([]float64, len(shipment.Items))
+	total := 0.0
+	for _, box := range shipment.Boxes {
+		if box.Volume < 0 {
+			return nil, fmt.Errorf("lingxing: 箱子 %s 的体积不能小于 0", box.BoxNo)
+		}
+		total += box.Volume
+	}
+	if shipment.HeadFee == 0 {
+		return
+	}
+	if total == 0 {
+		return nil, errors.New("lingxing: 箱子体积合计为 0")
+	}
+	for _, box := range shipment.Boxes {
+		quantity := 0
+		for _, item := range box.Items {
+			if item.Quantity < 0 {
+				return nil, fmt.Errorf("lingxing: 箱子 %s 中 %s 的装箱量不能小于 0", box.BoxNo, item.SKU)
+			}
+			quantity += item.Quantity
+		}
+		if box.Volume == 0 {
+			continue
+		}
+		if quantity == 0 {
+			return nil, fmt.Errorf("lingxing: 箱子 %s 没有装箱产品", box.BoxNo)
+		}
+		boxFee := shipment.HeadFee * box.Volume / total
+		for _, item := range box.Items {
+			i := shipment.boxItemIndex(item)
+			if i < 0 {
+				return nil, fmt.Errorf("lingxing: 箱子 %s 中的 %s 不在发货产品中", box.BoxNo, item.SKU)
+			}
+			fees[i] += boxFee * float64(item.Quantity) / float64(quantity)
+		}
+	}
+	return
+}
+
+// AllocateHeadFee 根据头程费分配方式将头程费用分摊到各产品，返回各产品分摊的头程费用（与 Items 顺序一致，币种与头程费用一致）
+// 自定义分配方式直接使用各产品的自定义头程费用，按箱子体积分配时先按体积分摊到各箱子，再按装箱量分摊到箱内产品
+func AllocateHeadFee(shipment LandedCostShipment) (fees []float64, err error) {
+	headFeeType, headFee, items := shipment.HeadFeeType, shipment.HeadFee, shipment.Items
+	fees = make([]float64, len(items))
+	switch headFeeType {
+	case HeadFeeTypeCustom:
+		for i, item := range items {
+			fees[i] = item.CustomFee
+		}
+		return
+	case HeadFeeTypeBoxVolume:
+		return allocateHeadFeeByBoxVolume(shipment)
+	}
+
+	bases := make([]float64, len(items))
+	total := 0.0
+	for i, item := range items {
+		quantity := float64(item.Quantity)
+		switch headFeeType {
+		case HeadFeeTypeChargeableWeight:
+			bases[i] = item.chargeableWeight() * quantity
+		case HeadFeeTypeActualWeight:
+			bases[i] = item.UnitWeight * quantity
+		case HeadFeeTypeVolumetricWeight:
+			bases[i] = item.UnitVolumetricWeight * quantity
+		case HeadFeeTypeQuantity:
+			bases[i] = quantity
+		default:
+			return nil, fmt.Errorf("lingxing: 无效的头程费分配方式 %d", headFeeType)
+		}
+		if bases[i] < 0 {
+			return nil, fmt.Errorf("lingxing: %s 的头程费分摊基数不能小于 0", item.SKU)
+		}
+		total += bases[i]
+	}
+	if headFee == 0 {
+		return
+	}
+	if total == 0 {
+		return nil, errors.New("lingxing: 头程费分摊基数合计为 0")
+	}
+	for i := range items {
+		fees[i] = headFee * bases[i] / total
+	}
+	return
+}
+
+// LandedCost 单位到岸成本（RMB）
+type LandedCost struct {
+	SKU          string  `json:"sku"`           // SKU
+	Country      string  `json:"country"`       // 国家代码
+	Quantity     int     `json:"quantity"`      // 发货量
+	PurchaseCost float64 `json:"purchase_cost"` // 单位采购成本
+	HeadFee      float64 `json:"head_fee"`      // 单位头程费用
+	Tax          float64 `json:"tax"`           // 单位关税
+	UnitCost     float64 `json:"unit_cost"`     // 单位到岸成本
+	TotalCost    float64 `json:"total_cost"`    // 到岸成本合计（单位到岸成本 × 发货量）
+}
+
+// landedCostAverage 加权平均值
+type landedCostAverage struct {
+	quantity int
+	amount   float64
+}
+
+func (a *landedCostAverage) add(quantity int, amount float64) {
+	a.quantity += quantity
+	a.amount += amount
+}
+
+func (a landedCostAverage) value() float64 {
+	if a.quantity == 0 {
+		return 0
+	}
+	return a.amount / float64(a.quantity)
+}
+
+// LandedCostCalculator 到岸成本计算器
+type LandedCostCalculator struct {
+	converter     *CurrencyConverter                       // 用于转换头程费用和默认头程成本的币种
+	month         string                                   // 汇率月份（Y-m）
+	purchaseCosts map[string]*landedCostAverage            // SKU => 入库成本
+	productCosts  map[string]float64                       // SKU => 采购价格 + 采购运输成本
+	logistics     map[string]ProductLogistic               // SKU => 物流关联
+	headFees      map[string]map[string]*landedCostAverage // SKU => 国家代码 => 头程费用
+	skus          map[string]string                        // 发货单中的 SKU
+}
+
+// NewLandedCostCalculator 创建到岸成本计算器，converter 用于将非人民币的头程费用和默认头程成本转换为人民币，可以为 nil
+// month 为汇率月份（Y-m），用于转换默认头程成本和发货时间为空的头程费用，相同的数据和汇率月份计算结果一致
+func NewLandedCostCalculator(converter *CurrencyConverter, month string) *LandedCostCalculator {
+	return &LandedCostCalculator{
+		converter:     converter,
+		month:         rateMonth(month),
+		purchaseCosts: make(map[string]*landedCostAverage),
+		productCosts:  make(map[string]float64),
+		logistics:     make(map[string]ProductLogistic),
+		headFees:      make(map[string]map[string]*landedCostAverage),
+		skus:          make(map[string]string),
+	}
+}
+
+// AddInboundOrders 添加入库单，仅已完成的入库单参与计算
+// 单位入库成本为空时使用单价 + 单位费用
+func (c *LandedCostCalculator) AddInboundOrders(orders ...InboundOrder) {
+	for _, order := range orders {
+		if order.Status != InboundOrderStatusCompleted {
+			continue
+		}
+		for _, item := range order.ItemList {
+			quantity := outboundInboundOrderItemQuantity(item)
+			if item.SKU == "" || quantity <= 0 {
+				continue
+			}
+			unitCost := item.SingleStockCost
+			if unitCost == 0 {
+				unitCost = item.Price + item.SingleFee
+			}
+			key := skuKey(item.SKU)
+			a, ok := c.purchaseCosts[key]
+			if !ok {
+				a = &landedCostAverage{}
+				c.purchaseCosts[key] = a
+			}
+			a.add(quantity, unitCost*float64(quantity))
+		}
+	}
+}
+
+// AddProducts 添加本地产品，产品的采购价格和采购运输成本作为未入库产品的单位采购成本
+func (c *LandedCostCalculator) AddProducts(products ...Product) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		cost := 0.0
+		for _, s := range []string{product.CgPrice, product.CgTransportCosts} {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				cost += v
+			}
+		}
+		c.productCosts[skuKey(product.SKU)] = cost
+	}
+}
+
+// AddProductLogistics 添加产品物流关联，用于获取报关税率和默认头程成本
+func (c *LandedCostCalculator) AddProductLogistics(sku string, logistics ...ProductLogistic) {
+	key := skuKey(sku)
+	m, ok := c.logistics[key]
+	if !ok {
+		m = make(ProductLogistic)
+		c.logistics[key] = m
+	}
+	for _, logistic := range logistics {
+		for code, v := range logistic {
+			m.Set(code, v)
+		}
+	}
+}
+
+// toCNY 将金额转换为人民币，month 为空时使用计算器的汇率月份
+func (c *LandedCostCalculator) toCNY(amount float64, currency, month string) (float64, error) {
+	if amount == 0 || currency == "" || strings.EqualFold(currency, constant.CNY) {
+		return amount, nil
+	}
+	if c.converter == nil {
+		return 0, fmt.Errorf("lingxing: 缺少 %s 币种的汇率", currency)
+	}
+	if month == "" {
+		month = c.month
+	}
+	if month == "" {
+		return 0, errors.New("lingxing: 汇率月份不能为空")
+	}
+	return c.converter.Convert(amount, currency, constant.CNY, month)
+}
+
+// AddShipments 添加发货单，按照发货单的头程费分配方式分摊头程费用并转换为人民币
+func (c *LandedCostCalculator) AddShipments(shipments ...LandedCostShipment) error {
+	for _, shipment := range shipments {
+		fees, err := AllocateHeadFee(shipment)
+		if err != nil {
+			return fmt.Errorf("%w（发货单 %s）", err, shipment.ShipmentSN)
+		}
+		for i := range fees {
+			if fees[i], err = c.toCNY(fees[i], shipment.HeadFeeCurrency, rateMonth(shipment.ShipmentTime)); err != nil {
+				return fmt.Errorf("%w（发货单 %s）", err, shipment.ShipmentSN)
+			}
+		}
+		for i, item := range shipment.Items {
+			if item.SKU == "" || item.Quantity <= 0 {
+				continue
+			}
+			key := skuKey(item.SKU)
+			if _, ok := c.headFees[key]; !ok {
+				c.headFees[key] = make(map[string]*landedCostAverage)
+				c.skus[key] = item.SKU
+			}
+			country := productLogisticCountryCode(item.Country)
+			a, ok := c.headFees[key][country]
+			if !ok {
+				a = &landedCostAverage{}
+				c.headFees[key][country] = a
+			}
+			a.add(item.Quantity, fees[i])
+		}
+	}
+	return nil
+}
+
+// Cost 计算 SKU 在国家（地区）的单位到岸成本
+func (c *LandedCostCalculator) Cost(sku, country string) (cost LandedCost, err error) {
+	key := skuKey(sku)
+	country = productLogisticCountryCode(country)
+	cost = LandedCost{SKU: sku, Country: country}
+	if a, ok := c.purchaseCosts[key]; ok && a.quantity > 0 {
+		cost.PurchaseCost = a.value()
+	} else if v, ok := c.productCosts[key]; ok {
+		cost.PurchaseCost = v
+	} else {
+		return cost, fmt.Errorf("lingxing: %s 的采购成本不存在", sku)
+	}
+
+	logistic, hasLogistic := c.logistics[key].Country(country)
+	if a, ok := c.headFees[key][country]; ok && a.quantity > 0 {
+		cost.Quantity = a.quantity
+		cost.HeadFee = a.value()
+	} else if hasLogistic && logistic.CgTransportCosts != 0 {
+		if cost.HeadFee, err = c.toCNY(logistic.CgTransportCosts, logistic.Currency, ""); err != nil {
+			return
+		}
+	}
+	if hasLogistic {
+		cost.Tax = cost.PurchaseCost * logistic.BgTaxRate / 100
+	}
+	cost.UnitCost = cost.PurchaseCost + cost.HeadFee + cost.Tax
+	cost.TotalCost = cost.UnitCost * float64(cost.Quantity)
+	return
+}
+
+// Calculate 计算所有已发货的 SKU 在各国家（地区）的单位到岸成本（按 SKU、国家代码排序）
+func (c *LandedCostCalculator) Calculate() (costs []LandedCost, err error) {
+	costs = make([]LandedCost, 0)
+	keys := make([]string, 0, len(c.headFees))
+	for key := range c.headFees {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		countries := make([]string, 0, len(c.headFees[key]))
+		for country := range c.headFees[key] {
+			countries = append(countries, country)
+		}
+		sort.Strings(countries)
+		for _, country := range countries {
+			cost, e := c.Cost(c.skus[key], country)
+			if e != nil {
+				return nil, e
+			}
+			costs = append(costs, cost)
+		}
+	}
+	return
+}
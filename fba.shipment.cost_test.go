@@ -0,0 +1,141 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAllocateHeadFee(t *testing.T) {
+	items := []LandedCostShipmentItem{
+		{SKU: "A", Quantity: 10, UnitWeight: 1, UnitVolumetricWeight: 2, CustomFee: 5},
+		{SKU: "B", Quantity: 20, UnitWeight: 1.5, UnitVolumetricWeight: 1, CustomFee: 7},
+	}
+	boxes := []LandedCostShipmentBox{
+		{BoxNo: "1", Volume: 3000, Items: []LandedCostShipmentBoxItem{{SKU: "A", Quantity: 10}}},
+		{BoxNo: "2", Volume: 1000, Items: []LandedCostShipmentBoxItem{{SKU: "b", Quantity: 20}}},
+	}
+	testCases := []struct {
+		headFeeType int
+		fees        []float64
+	}{
+		{HeadFeeTypeChargeableWeight, []float64{40, 60}}, // 20 : 30
+		{HeadFeeTypeActualWeight, []float64{25, 75}},     // 10 : 30
+		{HeadFeeTypeVolumetricWeight, []float64{50, 50}}, // 20 : 20
+		{HeadFeeTypeQuantity, []float64{100.0 / 3, 200.0 / 3}},
+		{HeadFeeTypeCustom, []float64{5, 7}},
+		{HeadFeeTypeBoxVolume, []float64{75, 25}},
+	}
+	for _, testCase := range testCases {
+		fees, err := AllocateHeadFee(LandedCostShipment{HeadFeeType: testCase.headFeeType, HeadFee: 100, Items: items, Boxes: boxes})
+		assert.Nil(t, err, "head fee type %d", testCase.headFeeType)
+		assert.InDeltaSlice(t, testCase.fees, fees, 0.0001, "head fee type %d", testCase.headFeeType)
+	}
+
+	_, err := AllocateHeadFee(LandedCostShipment{HeadFeeType: 9, HeadFee: 100, Items: items})
+	assert.NotNil(t, err)
+	_, err = AllocateHeadFee(LandedCostShipment{HeadFeeType: HeadFeeTypeBoxVolume, HeadFee: 100, Items: []LandedCostShipmentItem{{SKU: "A", Quantity: 1}}})
+	assert.NotNil(t, err, "without boxes")
+}
+
+func TestAllocateHeadFee_BoxVolume(t *testing.T) {
+	shipment := LandedCostShipment{
+		HeadFeeType: HeadFeeTypeBoxVolume,
+		HeadFee:     100,
+		Items: []LandedCostShipmentItem{
+			{SKU: "A", FnSKU: "X001", Country: "US", Quantity: 5},
+			{SKU: "A", FnSKU: "X002", Country: "GB", Quantity: 5},
+			{SKU: "B", Quantity: 15},
+		},
+		Boxes: []LandedCostShipmentBox{
+			// 混装箱按装箱量分摊箱子的头程费用
+			{BoxNo: "1", Volume: 2000, Items: []LandedCostShipmentBoxItem{{SKU: "A", FnSKU: "x001", Quantity: 5}, {SKU: "B", Quantity: 15}}},
+			{BoxNo: "2", Volume: 2000, Items: []LandedCostShipmentBoxItem{{SKU: "A", FnSKU: "X002", Quantity: 5}}},
+		},
+	}
+	fees, err := AllocateHeadFee(shipment)
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{12.5, 50, 37.5}, fees, 0.0001)
+
+	shipment.Boxes = append(shipment.Boxes, LandedCostShipmentBox{BoxNo: "3", Volume: 1000, Items: []LandedCostShipmentBoxItem{{SKU: "C", Quantity: 1}}})
+	_, err = AllocateHeadFee(shipment)
+	assert.NotNil(t, err, "unknown box item")
+}
+
+func TestNewLandedCostShipment(t *testing.T) {
+	detail := FBAShipmentDetail{
+		ShipmentSN:   "SP1",
+		ShipmentTime: "2022-09-10 10:00:00",
+		HeadFeeType:  HeadFeeTypeBoxVolume,
+		HeadFee:      100,
+		Items: []FBAShipmentDetailItem{
+			{SID: 1, SKU: "A", FnSKU: "X001", Num: 10, HeadFee: 60},
+			{SID: 2, SKU: "B", FnSKU: "X002", Num: 20, HeadFee: 40},
+		},
+		Boxes: []FBAShipmentBox{
+			{BoxNo: "1", Length: 10, Width: 10, Height: 30, Items: []FBAShipmentBoxItem{{SKU: "A", FnSKU: "X001", Quantity: 10}}},
+			{BoxNo: "2", Length: 10, Width: 10, Height: 10, Items: []FBAShipmentBoxItem{{SKU: "B", FnSKU: "X002", Quantity: 20}}},
+		},
+	}
+	shipment := NewLandedCostShipment(detail, map[int]string{1: "US", 2: "GB"}, ProductDetail{SKU: "a", CgProductGrossWeight: 500, CgPackageLength: "10", CgPackageWidth: "20", CgPackageHeight: "30"})
+	assert.Equal(t, "SP1", shipment.ShipmentSN)
+	assert.Equal(t, "CNY", shipment.HeadFeeCurrency)
+	if assert.Len(t, shipment.Items, 2) {
+		assert.Equal(t, LandedCostShipmentItem{SKU: "A", FnSKU: "X001", Country: "US", Quantity: 10, UnitWeight: 0.5, UnitVolumetricWeight: 1, CustomFee: 60}, shipment.Items[0])
+		assert.Equal(t, "GB", shipment.Items[1].Country)
+	}
+	fees, err := AllocateHeadFee(shipment)
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{75, 25}, fees, 0.0001)
+}
+
+func TestLandedCostCalculator(t *testing.T) {
+	c := NewLandedCostCalculator(NewCurrencyConverter(Rate{Date: "2000-01", Code: "USD", RateOrg: 7}), "2022-09")
+	c.AddInboundOrders(
+		InboundOrder{Status: InboundOrderStatusCompleted, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 10, SingleStockCost: 10}}},
+		InboundOrder{Status: InboundOrderStatusCompleted, ItemList: []OutboundInboundOrderItem{{SKU: "a", ProductTotal: 30, Price: 11, SingleFee: 1}}},
+		InboundOrder{Status: InboundOrderStatusPendingInbound, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 100, SingleStockCost: 100}}},
+	)
+	c.AddProducts(Product{SKU: "A", CgPrice: "1"}, Product{SKU: "B", CgPrice: "8.5", CgTransportCosts: "0.5"})
+	c.AddProductLogistics("A", ProductLogistic{"US": {BgTaxRate: 10}})
+	c.AddProductLogistics("B", ProductLogistic{"UK": {CgTransportCosts: 1, Currency: "USD"}})
+	err := c.AddShipments(
+		LandedCostShipment{ShipmentSN: "SP1", HeadFeeType: HeadFeeTypeQuantity, HeadFee: 100, Items: []LandedCostShipmentItem{{SKU: "A", Country: "US", Quantity: 10}, {SKU: "B", Country: "US", Quantity: 10}}},
+		LandedCostShipment{ShipmentSN: "SP2", HeadFeeType: HeadFeeTypeCustom, Items: []LandedCostShipmentItem{{SKU: "A", Country: "US", Quantity: 30, CustomFee: 90}}},
+	)
+	assert.Nil(t, err)
+
+	costs, err := c.Calculate()
+	assert.Nil(t, err)
+	if assert.Len(t, costs, 2) {
+		a := costs[0]
+		assert.Equal(t, "A", a.SKU)
+		assert.Equal(t, 40, a.Quantity)
+		assert.InDelta(t, 11.5, a.PurchaseCost, 0.0001) // (10 * 10 + 12 * 30) / 40
+		assert.InDelta(t, 3.5, a.HeadFee, 0.0001)       // (50 + 90) / 40
+		assert.InDelta(t, 1.15, a.Tax, 0.0001)
+		assert.InDelta(t, 16.15, a.UnitCost, 0.0001)
+		assert.InDelta(t, 646, a.TotalCost, 0.0001)
+		assert.InDelta(t, 14, costs[1].UnitCost, 0.0001)
+	}
+
+	b, err := c.Cost("B", "GB")
+	assert.Nil(t, err)
+	assert.InDelta(t, 16, b.UnitCost, 0.0001)
+	_, err = c.Cost("C", "US")
+	assert.NotNil(t, err)
+
+	// 外币头程费用按发货时间所在月份的汇率转换
+	converter := NewCurrencyConverter(Rate{Date: "2022-08", Code: "USD", RateOrg: 6.8}, Rate{Date: "2022-09", Code: "USD", RateOrg: 7})
+	c = NewLandedCostCalculator(converter, "2022-08")
+	c.AddProducts(Product{SKU: "A", CgPrice: "10"})
+	err = c.AddShipments(
+		LandedCostShipment{ShipmentSN: "SP1", ShipmentTime: "2022-09-10 10:00:00", HeadFeeType: HeadFeeTypeQuantity, HeadFee: 10, HeadFeeCurrency: "USD", Items: []LandedCostShipmentItem{{SKU: "A", Country: "US", Quantity: 10}}},
+		LandedCostShipment{ShipmentSN: "SP2", HeadFeeType: HeadFeeTypeQuantity, HeadFee: 10, HeadFeeCurrency: "USD", Items: []LandedCostShipmentItem{{SKU: "A", Country: "US", Quantity: 10}}},
+	)
+	assert.Nil(t, err)
+	a, err := c.Cost("A", "US")
+	assert.Nil(t, err)
+	assert.InDelta(t, 6.9, a.HeadFee, 0.0001) // (70 + 68) / 20
+
+	assert.NotNil(t, NewLandedCostCalculator(nil, "").AddShipments(LandedCostShipment{HeadFeeType: HeadFeeTypeQuantity, HeadFee: 10, HeadFeeCurrency: "USD", Items: []LandedCostShipmentItem{{SKU: "A", Quantity: 1}}}))
+}
@@ -1,10 +1,13 @@
 package lingxing
 
 import (
+	"errors"
 	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/hiscaler/lingxing/constant"
 	jsoniter "github.com/json-iterator/go"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,90 +15,104 @@ type fbaShipmentService service
 
 // 查询 FBA 发货单列表
 
-type FBAShipment struct {
-	ID                   int    `json:"id"`                     // 发货单id
-	ShipmentSN           string `json:"shipment_sn"`            // 发货单号
-	Status               int    `json:"status"`                 // 发货单状态，-1 : 待配货 0：待发货，1：已发货，2：已完成，3：已作废
-	ShipmentTime         string `json:"shipment_time"`          // 发货时间
-	WName                string `json:"wname"`                  // 仓库名称
-	CreateUser           string `json:"create_user"`            // 创建用户
-	LogisticsChannelName string `json:"logistics_channel_name"` // 物流方式
-	ExpectedArrivalDate  string `json:"expected_arrival_date"`  // 到货时间
-	EtaDate              string `json:"eta_date"`               // 预计到港时间
-	DeliveryDate         string `json:"delivery_date"`          // 实际妥投时间
-	CreateTime           string `json:"create_time"`            // 创建时间
-	IsPick               bool   `json:"is_pick"`                // 拣货状态 0 未拣货 1已拣货
-	IsPrint              bool   `json:"is_print"`               // 是否打印
-	PickTime             string `json:"pick_time"`              // 拣货时间
-	PrintNum             int    `json:"print_num"`              // 打印次数
-	HeadFeeType          int    `json:"head_fee_type"`          // 头程费分配方式，0：按计费重；1：按实重；2：按体积重；3：按SKU数量；4：自定义；5：按箱子体积
-	FileId               string `json:"file_id"`                // 附件文件
-	GmtModified          string `json:"gmt_modified"`           // 更新时间
-	Remark               string `json:"remark"`                 // 备注
-	Wid                  int    `json:"wid"`                    // 仓库ID
-	IsReturnStock        bool   `json:"is_return_stock"`        // 是否恢复库存
-	Logistics            []struct {
-		ReplaceTrackingNumber string `json:"replace_tracking_number"` // 跟踪单号
-		TrackingNumber        string `json:"tracking_number"`         // 物流商号
-	} `json:"logistics"` // 	物流列表
-	RelateList []struct {
-		Mid                            int      `json:"mid"`                               // 国家id
-		DestinationFulfillmentCenterId string   `json:"destination_fulfillment_center_id"` // 物流中心编码
-		QuantityShipped                int      `json:"quantity_shipped"`                  // 申报量
-		Id                             int      `json:"id"`                                // 明细id
-		WName                          string   `json:"wname"`                             // 仓库名称
-		ShipmentSN                     string   `json:"shipment_sn"`                       // 发货单号
-		ShipmentId                     string   `json:"shipment_id"`                       // 货件id
-		Wid                            int      `json:"wid"`                               // 仓库id
-		Pid                            int      `json:"pid"`                               // 货件明细ID
-		SName                          string   `json:"sname"`                             // 店铺名称
-		ProductName                    string   `json:"product_name"`                      // 产品名称
-		Num                            int      `json:"num"`                               // 发货数量
-		PicURL                         string   `json:"pic_url"`                           // 图片url
-		PackingType                    int      `json:"packing_type"`                      // 混装类型 2原装 1原装
-		FulfillmentNetworkSKU          string   `json:"fulfillment_network_sku"`           // listing的fnsku
-		SKU                            string   `json:"sku"`                               // sku
-		FnSKU                          string   `json:"fnsku"`                             // 仓库fnsku
-		MSKU                           string   `json:"msku"`                              // seller_sku
-		Nation                         string   `json:"nation"`                            // 国家名称
-		ApplyNum                       int      `json:"apply_num"`                         // 关联货件量
-		ProductId                      int      `json:"product_id"`                        // 商品id
-		Remark                         string   `json:"remark"`                            // 备注
-		Status                         int      `json:"stauts"`                            // 状态
-		SId                            int      `json:"sid"`                               // 店铺id
-		IsCombo                        bool     `json:"is_combo"`                          // 是否组合商品
-		CreateByMws                    int      `json:"create_by_mws"`                     // 创建发货单的途径
-		WhbCodeList                    []string `json:"whb_code_list"`                     // 仓位编码列表
-		PackingTypeName                string   `json:"packing_type_name"`                 // 包装名称
-		ProductValidNum                int      `json:"product_valid_num"`                 // 可用量
-		ProductQCNum                   int      `json:"product_qc_num"`                    // 待检量
-		DiffNum                        int      `json:"diff_num"`                          // 差额
-	} `json:"relate_list"` // 	关联货件列表
-	NotRelateList                  []string `json:"not_relate_list"`                   // 未关联货件列表
+// FBAShipmentLogistics 发货单物流
+type FBAShipmentLogistics struct {
+	ReplaceTrackingNumber string `json:"replace_tracking_number"` // 跟踪单号
+	TrackingNumber        string `json:"tracking_number"`         // 物流商号
+}
+
+// FBAShipmentRelateItem 发货单关联货件明细
+type FBAShipmentRelateItem struct {
+	Mid                            int      `json:"mid"`                               // 国家id
 	DestinationFulfillmentCenterId string   `json:"destination_fulfillment_center_id"` // 物流中心编码
-	StatusName                     string   `json:"status_name"`                       // 状态名称
-	HeadFeeTypeName                string   `json:"head_fee_type_name"`                // 头程分摊名称
-	FileList                       []string `json:"fileList"`                          // 文件列表
+	QuantityShipped                int      `json:"quantity_shipped"`                  // 申报量
+	Id                             int      `json:"id"`                                // 明细id
+	WName                          string   `json:"wname"`                             // 仓库名称
+	ShipmentSN                     string   `json:"shipment_sn"`                       // 发货单号
+	ShipmentId                     string   `json:"shipment_id"`                       // 货件id
+	Wid                            int      `json:"wid"`                               // 仓库id
+	Pid                            int      `json:"pid"`                               // 货件明细ID
+	SName                          string   `json:"sname"`                             // 店铺名称
+	ProductName                    string   `json:"product_name"`                      // 产品名称
+	Num                            int      `json:"num"`                               // 发货数量
+	PicURL                         string   `json:"pic_url"`                           // 图片url
+	PackingType                    int      `json:"packing_type"`                      // 混装类型 2原装 1原装
+	FulfillmentNetworkSKU          string   `json:"fulfillment_network_sku"`           // listing的fnsku
+	SKU                            string   `json:"sku"`                               // sku
+	FnSKU                          string   `json:"fnsku"`                             // 仓库fnsku
+	MSKU                           string   `json:"msku"`                              // seller_sku
+	Nation                         string   `json:"nation"`                            // 国家名称
+	ApplyNum                       int      `json:"apply_num"`                         // 关联货件量
+	ProductId                      int      `json:"product_id"`                        // 商品id
+	Remark                         string   `json:"remark"`                            // 备注
+	Status                         int      `json:"stauts"`                            // 状态
+	SId                            int      `json:"sid"`                               // 店铺id
+	IsCombo                        bool     `json:"is_combo"`                          // 是否组合商品
+	CreateByMws                    int      `json:"create_by_mws"`                     // 创建发货单的途径
+	WhbCodeList                    []string `json:"whb_code_list"`                     // 仓位编码列表
+	PackingTypeName                string   `json:"packing_type_name"`                 // 包装名称
+	ProductValidNum                int      `json:"product_valid_num"`                 // 可用量
+	ProductQCNum                   int      `json:"product_qc_num"`                    // 待检量
+	DiffNum                        int      `json:"diff_num"`                          // 差额
+}
+
+type FBAShipment struct {
+	ID                             int                     `json:"id"`                                // 发货单id
+	ShipmentSN                     string                  `json:"shipment_sn"`                       // 发货单号
+	Status                         ShipmentSheetStatus     `json:"status"`                            // 发货单状态，-1 : 待配货 0：待发货，1：已发货，2：已完成，3：已作废
+	ShipmentTime                   string                  `json:"shipment_time"`                     // 发货时间
+	WName                          string                  `json:"wname"`                             // 仓库名称
+	CreateUser                     string                  `json:"create_user"`                       // 创建用户
+	LogisticsChannelName           string                  `json:"logistics_channel_name"`            // 物流方式
+	ExpectedArrivalDate            string                  `json:"expected_arrival_date"`             // 到货时间
+	EtaDate                        string                  `json:"eta_date"`                          // 预计到港时间
+	DeliveryDate                   string                  `json:"delivery_date"`                     // 实际妥投时间
+	CreateTime                     string                  `json:"create_time"`                       // 创建时间
+	IsPick                         bool                    `json:"is_pick"`                           // 拣货状态 0 未拣货 1已拣货
+	IsPrint                        bool                    `json:"is_print"`                          // 是否打印
+	PickTime                       string                  `json:"pick_time"`                         // 拣货时间
+	PrintNum                       int                     `json:"print_num"`                         // 打印次数
+	HeadFeeType                    int                     `json:"head_fee_type"`                     // 头程费分配方式，0：按计费重；1：按实重；2：按体积重；3：按SKU数量；4：自定义；5：按箱子体积
+	FileId                         string                  `json:"file_id"`                           // 附件文件
+	GmtModified                    string                  `json:"gmt_modified"`                      // 更新时间
+	Remark                         string                  `json:"remark"`                            // 备注
+	Wid                            int                     `json:"wid"`                               // 仓库ID
+	IsReturnStock                  bool                    `json:"is_return_stock"`                   // 是否恢复库存
+	Logistics                      []FBAShipmentLogistics  `json:"logistics"`                         // 物流列表
+	RelateList                     []FBAShipmentRelateItem `json:"relate_list"`                       // 关联货件列表
+	NotRelateList                  []FBAShipmentRelateItem `json:"not_relate_list"`                   // 未关联货件列表
+	DestinationFulfillmentCenterId string                  `json:"destination_fulfillment_center_id"` // 物流中心编码
+	StatusName                     string                  `json:"status_name"`                       // 状态名称
+	HeadFeeTypeName                string                  `json:"head_fee_type_name"`                // 头程分摊名称
+	FileList                       []string                `json:"fileList"`                          // 文件列表
 }
 
 type FBAShipmentsQueryParams struct {
 	Paging
-	SearchValue   string   `json:"search_value,omitempty"`   // 搜索的值
-	SearchField   string   `json:"search_field,omitempty"`   // 搜索字段，shipment_sn:发货单号，sku:skushipment_id：货件单号
-	SIds          []string `json:"sids,omitempty"`           // 店铺id
-	MIds          []string `json:"mids,omitempty"`           // 国家id
-	WId           []string `json:"wid,omitempty"`            // 仓库id
-	LogisticsType []string `json:"logistics_type,omitempty"` // 物流方式id
-	Status        int      `json:"status,omitempty"`         // 发货单状态，-1 : 待配货 0：待发货，1：已发货，3：已作废
-	PrintStatus   int      `json:"print_status,omitempty"`   // 打印状态： 0未打印 ，1 已打印
-	PickStatus    int      `json:"pick_status,omitempty"`    // 拣货状态 ：0 未拣货， 1已拣货
-	TimeType      int      `json:"time_type,omitempty"`      // 按时间查询时必传。时间类型 2创建时间 1到货时间 0发货时间
-	StartDate     string   `json:"start_date,omitempty"`     // 开始日期
-	EndDate       string   `json:"end_date,omitempty"`       // 结束日期
+	SearchValue   string               `json:"search_value,omitempty"`   // 搜索的值
+	SearchField   string               `json:"search_field,omitempty"`   // 搜索字段，shipment_sn:发货单号，sku:skushipment_id：货件单号
+	SIds          []string             `json:"sids,omitempty"`           // 店铺id
+	MIds          []string             `json:"mids,omitempty"`           // 国家id
+	WId           []string             `json:"wid,omitempty"`            // 仓库id
+	LogisticsType []string             `json:"logistics_type,omitempty"` // 物流方式id
+	Status        *ShipmentSheetStatus `json:"status,omitempty"`         // 发货单状态，-1 : 待配货 0：待发货，1：已发货，3：已作废（待发货为 0，因此使用指针以区分未设置）
+	PrintStatus   int                  `json:"print_status,omitempty"`   // 打印状态： 0未打印 ，1 已打印
+	PickStatus    int                  `json:"pick_status,omitempty"`    // 拣货状态 ：0 未拣货， 1已拣货
+	TimeType      int                  `json:"time_type,omitempty"`      // 按时间查询时必传。时间类型 2创建时间 1到货时间 0发货时间
+	StartDate     string               `json:"start_date,omitempty"`     // 开始日期
+	EndDate       string               `json:"end_date,omitempty"`       // 结束日期
 }
 
 func (m FBAShipmentsQueryParams) Validate() error {
-	return nil
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Status, validation.In(
+			ShipmentSheetStatusPendingAllocation,
+			ShipmentSheetStatusPendingShipment,
+			ShipmentSheetStatusShipped,
+			ShipmentSheetStatusCompleted,
+			ShipmentSheetStatusVoided,
+		).Error("无效的发货单状态")),
+	)
 }
 
 // All 查询 FBA 发货单
@@ -127,129 +144,297 @@ func (s fbaShipmentService) All(params FBAShipmentsQueryParams) (items []FBAShip
 // 查询 FBA 发货单详情
 // https://openapidoc.lingxing.com/#/docs/FBA/getInboundShipmentListMwsDetail
 
+// FBAShipmentDetailItem 发货单产品
+type FBAShipmentDetailItem struct {
+	ID                             int      `json:"id"`                                // 明细 ID
+	SID                            int      `json:"sid"`                               // 店铺 ID
+	SellerName                     string   `json:"sname"`                             // 店铺名称
+	MID                            int      `json:"mid"`                               // 国家 ID
+	Nation                         string   `json:"nation"`                            // 国家名称
+	ShipmentId                     string   `json:"shipment_id"`                       // 货件单号
+	DestinationFulfillmentCenterId string   `json:"destination_fulfillment_center_id"` // 物流中心编码
+	ProductId                      int      `json:"product_id"`                        // 产品 ID
+	ProductName                    string   `json:"product_name"`                      // 品名
+	SKU                            string   `json:"sku"`                               // SKU
+	MSKU                           string   `json:"msku"`                              // MSKU
+	FnSKU                          string   `json:"fnsku"`                             // FNSKU
+	FulfillmentNetworkSKU          string   `json:"fulfillment_network_sku"`           // Listing 的 FNSKU
+	PicURL                         string   `json:"pic_url"`                           // 图片 URL
+	IsCombo                        bool     `json:"is_combo"`                          // 是否组合商品
+	PackingType                    int      `json:"packing_type"`                      // 包装类型（1：混装、2：原装）
+	PackingTypeName                string   `json:"packing_type_name"`                 // 包装类型名称
+	Num                            int      `json:"num"`                               // 发货量
+	QuantityShipped                int      `json:"quantity_shipped"`                  // 申报量
+	ApplyNum                       int      `json:"apply_num"`                         // 关联货件量
+	ReceivedNum                    int      `json:"received_num"`                      // 签收量
+	ProductValidNum                int      `json:"product_valid_num"`                 // 可用量
+	ProductQcNum                   int      `json:"product_qc_num"`                    // 待检量
+	DiffNum                        int      `json:"diff_num"`                          // 差额
+	WhbCodeList                    []string `json:"whb_code_list"`                     // 仓位编码列表
+	CgPrice                        float64  `json:"cg_price"`                          // 单位采购成本
+	StockCost                      float64  `json:"stock_cost"`                        // 单位库存成本
+	HeadFee                        float64  `json:"head_fee"`                          // 分摊头程费用
+	Remark                         string   `json:"remark"`                            // 备注
+}
+
+// FBAShipmentBoxItem 装箱产品
+type FBAShipmentBoxItem struct {
+	SKU      string `json:"sku"`      // SKU
+	MSKU     string `json:"msku"`     // MSKU
+	FnSKU    string `json:"fnsku"`    // FNSKU
+	Quantity int    `json:"quantity"` // 装箱量
+}
+
+// FBAShipmentBox 发货单箱子
+type FBAShipmentBox struct {
+	BoxNo      string               `json:"box_no"`      // 箱号
+	ShipmentId string               `json:"shipment_id"` // 货件单号
+	Length     float64              `json:"length"`      // 长（cm）
+	Width      float64              `json:"width"`       // 宽（cm）
+	Height     float64              `json:"height"`      // 高（cm）
+	Weight     float64              `json:"weight"`      // 重量（kg）
+	Items      []FBAShipmentBoxItem `json:"items"`       // 装箱产品
+}
+
+// FBAShipmentFee 发货单费用
+type FBAShipmentFee struct {
+	FeeTypeId   int     `json:"fee_type_id"`   // 费用类型 ID
+	FeeTypeName string  `json:"fee_type_name"` // 费用类型名称
+	Amount      float64 `json:"amount"`        // 金额
+	Currency    string  `json:"currency"`      // 币种
+	Remark      string  `json:"remark"`        // 备注
+}
+
 type FBAShipmentDetail struct {
-	ID           int    `json:"id"`            // 发货单 ID
-	ZId          int    `json:"zid"`           // ZID
-	TrackingId   int    `json:"tracking_id"`   // 物流追踪(运单) ID
-	ShipmentSN   string `json:"shipment_sn"`   // 发货单号
-	Status       int    `json:"status"`        // 发货单状态（1：待配货、0：待发货、1：已发货、2：已完成、3：已作废）
-	ShipmentTime string `json:"shipment_time"` // 发货时间
-	WId          int    `json:"wid"`           // 仓库 ID
-	GmtModified  string `json:"gmt_modified"`  // 修改时间
-	GmtCreate    string `json:"gmt_create"`    // 创建时间
-	Remark       string `json:"remark"`        // 备注
-	// todo 待完善
+	ID                   int                     `json:"id"`                     // 发货单 ID
+	ZId                  int                     `json:"zid"`                    // ZID
+	TrackingId           int                     `json:"tracking_id"`            // 物流追踪(运单) ID
+	ShipmentSN           string                  `json:"shipment_sn"`            // 发货单号
+	Status               ShipmentSheetStatus     `json:"status"`                 // 发货单状态（-1：待配货、0：待发货、1：已发货、2：已完成、3：已作废）
+	StatusName           string                  `json:"status_name"`            // 状态名称
+	ShipmentTime         string                  `json:"shipment_time"`          // 发货时间
+	WId                  int                     `json:"wid"`                    // 仓库 ID
+	WName                string                  `json:"wname"`                  // 仓库名称
+	CreateUser           string                  `json:"create_user"`            // 创建用户
+	LogisticsChannelId   int                     `json:"logistics_channel_id"`   // 物流方式 ID
+	LogisticsChannelName string                  `json:"logistics_channel_name"` // 物流方式
+	ExpectedArrivalDate  string                  `json:"expected_arrival_date"`  // 到货时间
+	EtdDate              string                  `json:"etd_date"`               // 开船时间
+	EtaDate              string                  `json:"eta_date"`               // 预计到港时间
+	DeliveryDate         string                  `json:"delivery_date"`          // 实际妥投时间
+	IsPick               bool                    `json:"is_pick"`                // 是否已拣货
+	PickTime             string                  `json:"pick_time"`              // 拣货时间
+	IsPrint              bool                    `json:"is_print"`               // 是否已打印
+	PrintNum             int                     `json:"print_num"`              // 打印次数
+	IsReturnStock        bool                    `json:"is_return_stock"`        // 是否恢复库存
+	HeadFeeType          int                     `json:"head_fee_type"`          // 头程费分配方式（0：按计费重、1：按实重、2：按体积重、3：按SKU数量、4：自定义、5：按箱子体积）
+	HeadFeeTypeName      string                  `json:"head_fee_type_name"`     // 头程费分配方式名称
+	HeadFee              float64                 `json:"head_fee"`               // 头程费用合计
+	FileId               string                  `json:"file_id"`                // 附件文件
+	FileList             []string                `json:"fileList"`               // 文件列表
+	GmtModified          string                  `json:"gmt_modified"`           // 修改时间
+	GmtCreate            string                  `json:"gmt_create"`             // 创建时间
+	Remark               string                  `json:"remark"`                 // 备注
+	Items                []FBAShipmentDetailItem `json:"items"`                  // 发货产品
+	Boxes                []FBAShipmentBox        `json:"box_list"`               // 箱子
+	Logistics            []FBAShipmentLogistics  `json:"logistics"`              // 物流跟踪
+	RelateList           []FBAShipmentRelateItem `json:"relate_list"`            // 关联货件列表
+	NotRelateList        []FBAShipmentRelateItem `json:"not_relate_list"`        // 未关联货件列表
+	Fees                 []FBAShipmentFee        `json:"fee_list"`               // 费用
 }
 
+// Quantity 发货量合计
+func (d FBAShipmentDetail) Quantity() int {
+	quantity := 0
+	for _, item := range d.Items {
+		quantity += item.Num
+	}
+	return quantity
+}
+
+// One 查询 FBA 发货单详情
 func (s fbaShipmentService) One(shipmentSN string) (item FBAShipmentDetail, err error) {
+	if shipmentSN == "" {
+		err = ErrNotFound
+		return
+	}
+
 	res := struct {
 		NormalResponse
 		Data FBAShipmentDetail `json:"data"`
 	}{}
-	_, err = s.httpClient.R().
+	resp, err := s.httpClient.R().
 		SetBody(map[string]string{"shipment_sn": shipmentSN}).
-		SetResult(&res).
 		Post("/routing/storage/shipment/getInboundShipmentListMwsDetail")
 	if err != nil {
 		return
 	}
-	item = res.Data
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		item = res.Data
+		if item.ID == 0 {
+			err = ErrNotFound
+		}
+	}
+	return
+}
+
+// Details 批量查询 FBA 发货单详情，返回以发货单号为键的发货单详情，不存在的发货单将被忽略
+// 接口不支持批量查询，每个发货单号依次调用一次 One，发货单较多时请注意接口的调用频率限制
+func (s fbaShipmentService) Details(shipmentSNs ...string) (items map[string]FBAShipmentDetail, err error) {
+	items = make(map[string]FBAShipmentDetail, len(shipmentSNs))
+	for _, shipmentSN := range shipmentSNs {
+		if _, ok := items[shipmentSN]; ok || shipmentSN == "" {
+			continue
+		}
+		item, e := s.One(shipmentSN)
+		if e != nil {
+			if errors.Is(e, ErrNotFound) {
+				continue
+			}
+			return nil, e
+		}
+		items[shipmentSN] = item
+	}
 	return
 }
 
 // FBA 发货计划
 
+// FBAShipmentPlanItem FBA 发货计划明细
+type FBAShipmentPlanItem struct {
+	IspgId               int                   `json:"ispg_id"`                // 发货计划组父 ID
+	IspId                int                   `json:"isp_id"`                 // 发货计划 ID
+	LogisticsChannelId   int                   `json:"logistics_channel_id"`   // 物流 ID
+	FnSKU                string                `json:"fnsku"`                  // FNSKU
+	MSKU                 string                `json:"msku"`                   // MSKU
+	WID                  int                   `json:"wid"`                    // 仓库 ID
+	WarehouseName        string                `json:"wname"`                  // 仓库名称
+	SID                  int                   `json:"sid"`                    // 店铺 ID
+	CreateTime           string                `json:"create_time"`            // 创建时间
+	Status               FBAShipmentPlanStatus `json:"status"`                 // 状态（-5：已驳回、0：待审核、5：待处理、10：已处理）
+	PackageType          int                   `json:"package_type"`           // 包装类型 2原装 1混装
+	ShipmentTime         string                `json:"shipment_time"`          // 计划发货时间
+	ShipmentPlanQuantity int                   `json:"shipment_plan_quantity"` // 计划发货量
+	Seq                  string                `json:"seq"`                    // 批次号
+	LogisticsName        string                `json:"logistics_name"`         // 物流名称
+	QuantityInCase       int                   `json:"quantity_in_case"`       // 单箱数量
+	BoxNum               int                   `json:"box_num"`                // 箱数
+	IsRelateMws          int                   `json:"is_relate_mws"`          // 是否关联货件
+	IsRelateList         int                   `json:"is_relate_list"`         // 是否关联发货单
+	Remark               string                `json:"remark"`                 // 备注
+	PrintNum             int                   `json:"print_num"`              // 打印次数
+	CreateUser           string                `json:"create_user"`            // 创建用户
+	SmallImageURL        string                `json:"small_image_url"`        // 商品图片
+	OrderSN              string                `json:"order_sn"`               // 计划发货单号
+	ProductName          string                `json:"product_name"`           // 产品名称
+	ProductId            int                   `json:"product_id"`             // 产品 ID
+	SKU                  string                `json:"sku"`                    // SKU
+	PicURL               string                `json:"pic_url"`                // 商品图片
+	IsCombo              bool                  `json:"is_combo"`               // 是否组合商品
+	StorageList          []struct {
+		ProductId       int `json:"product_id"`        // 商品 ID
+		ProductValidNum int `json:"product_valid_num"` // 库存可用量
+		ProductQcNum    int `json:"product_qc_num"`    // 待检量
+		QuantityReceive int `json:"quantity_receive"`  // 待收货量
+	} `json:"storage_list"` // 库存列表
+	MwsRelate []struct {
+		IsimId               int    `json:"isim_id"`                // 关联货件 ID
+		IsilId               int    `json:"isil_id"`                // 关联发货单 ID
+		IsilmId              int    `json:"isilm_id"`               // 关联发货单明细 ID
+		ShipmentPlanSN       string `json:"shipment_plan_sn"`       // 关联发货计划单号
+		ShipmentMwsSN        string `json:"shipment_mws_sn"`        // 关联货件单号
+		ShipmentListSN       string `json:"shipment_list_sn"`       // 关联发货单单号
+		ShipmentPlanQuantity int    `json:"shipment_plan_quantity"` // 关联发货计划数量
+		ShipmentMwsQuantity  int    `json:"shipment_mws_quantity"`  // 关联货件数量
+		ShipmentListQuantity int    `json:"shipment_list_quantity"` // 关联发货单数量
+	} `json:"mws_relate"` // 发货单关联
+	StatusName      string `json:"status_name"`       // 状态名称
+	PackingTypeName string `json:"packing_type_name"` // 包装类型名称
+	DiffNum         int    `json:"diff_num"`          // 差额
+	SName           string `json:"sname"`             // 店铺
+	Nation          string `json:"nation"`            // 国家
+}
+
 // FBAShipmentPlan FBA 发货计划
 type FBAShipmentPlan struct {
-	IspgId     int    `json:"ispg_id"`     // 发货计划组 ID
-	CreateTime string `json:"create_time"` // 创建时间
-	Seq        string `json:"seq"`         // 批次号
-	Remark     string `json:"remark"`      // 备注
-	CreateUser string `json:"create_user"` // 创建用户
-	List       []struct {
-		IspgId               int    `json:"ispg_id"`                // 发货计划组父 ID
-		IspId                int    `json:"isp_id"`                 // 发货计划 ID
-		LogisticsChannelId   int    `json:"logistics_channel_id"`   // 物流 ID
-		FnSKU                string `json:"fnsku"`                  // FNSKU
-		MSKU                 string `json:"msku"`                   // MSKU
-		WID                  int    `json:"wid"`                    // 仓库 ID
-		WarehouseName        string `json:"wname"`                  // 仓库名称
-		SID                  int    `json:"sid"`                    // 店铺 ID
-		CreateTime           string `json:"create_time"`            // 创建时间
-		Status               int    `json:"status"`                 // 状态（-5：已驳回、0：待审核、5：待处理、10：已处理）
-		PackageType          int    `json:"package_type"`           // 包装类型 2原装 1混装
-		ShipmentTime         string `json:"shipment_time"`          // 计划发货时间
-		ShipmentPlanQuantity int    `json:"shipment_plan_quantity"` // 计划发货量
-		Seq                  string `json:"seq"`                    // 批次号
-		LogisticsName        string `json:"logistics_name"`         // 物流名称
-		QuantityInCase       int    `json:"quantity_in_case"`       // 单箱数量
-		BoxNum               int    `json:"box_num"`                // 箱数
-		IsRelateMws          int    `json:"is_relate_mws"`          // 是否关联货件
-		IsRelateList         int    `json:"is_relate_list"`         // 是否关联发货单
-		Remark               string `json:"remark"`                 // 备注
-		PrintNum             int    `json:"print_num"`              // 打印次数
-		CreateUser           string `json:"create_user"`            // 创建用户
-		SmallImageURL        string `json:"small_image_url"`        // 商品图片
-		OrderSN              string `json:"order_sn"`               // 计划发货单号
-		ProductName          string `json:"product_name"`           // 产品名称
-		ProductId            int    `json:"product_id"`             // 产品 ID
-		SKU                  string `json:"sku"`                    // SKU
-		PicURL               string `json:"pic_url"`                // 商品图片
-		IsCombo              bool   `json:"is_combo"`               // 是否组合商品
-		StorageList          []struct {
-			ProductId       int `json:"product_id"`        // 商品 ID
-			ProductValidNum int `json:"product_valid_num"` // 库存可用量
-			ProductQcNum    int `json:"product_qc_num"`    // 待检量
-			QuantityReceive int `json:"quantity_receive"`  // 待收货量
-		} `json:"storage_list"` // 库存列表
-		MwsRelate []struct {
-			IsimId               int    `json:"isim_id"`                // 关联货件 ID
-			IsilId               int    `json:"isil_id"`                // 关联发货单 ID
-			IsilmId              int    `json:"isilm_id"`               // 关联发货单明细 ID
-			ShipmentPlanSN       string `json:"shipment_plan_sn"`       // 关联发货计划单号
-			ShipmentMwsSN        string `json:"shipment_mws_sn"`        // 关联货件单号
-			ShipmentListSN       string `json:"shipment_list_sn"`       // 关联发货单单号
-			ShipmentPlanQuantity int    `json:"shipment_plan_quantity"` // 关联发货计划数量
-			ShipmentMwsQuantity  int    `json:"shipment_mws_quantity"`  // 关联货件数量
-			ShipmentListQuantity int    `json:"shipment_list_quantity"` // 关联发货单数量
-		} `json:"mws_relate"` // 发货单关联
-		StatusName      string `json:"status_name"`       // 状态名称
-		PackingTypeName string `json:"packing_type_name"` // 包装类型名称
-		DiffNum         int    `json:"diff_num"`          // 差额
-		SName           string `json:"sname"`             // 店铺
-		Nation          string `json:"nation"`            // 国家
-	} `json:"list"` // 子项目列表
+	IspgId     int                   `json:"ispg_id"`     // 发货计划组 ID
+	CreateTime string                `json:"create_time"` // 创建时间
+	Seq        string                `json:"seq"`         // 批次号
+	Remark     string                `json:"remark"`      // 备注
+	CreateUser string                `json:"create_user"` // 创建用户
+	List       []FBAShipmentPlanItem `json:"list"`        // 子项目列表
 }
 
+// 发货计划查找字段
+type FBAShipmentPlanSearchField string
+
+const (
+	FBAShipmentPlanSearchFieldOrderSN FBAShipmentPlanSearchField = "order_sn" // 发货计划单号
+	FBAShipmentPlanSearchFieldSKU     FBAShipmentPlanSearchField = "sku"      // SKU
+	FBAShipmentPlanSearchFieldMSKU    FBAShipmentPlanSearchField = "msku"     // MSKU
+	FBAShipmentPlanSearchFieldFnSKU   FBAShipmentPlanSearchField = "fnsku"    // FNSKU
+)
+
+// 发货计划时间查找字段
+type FBAShipmentPlanSearchFieldTime string
+
+const (
+	FBAShipmentPlanSearchFieldTimeCreate   FBAShipmentPlanSearchFieldTime = "gmt_create"    // 创建时间
+	FBAShipmentPlanSearchFieldTimeShipment FBAShipmentPlanSearchFieldTime = "shipment_time" // 计划发货时间
+)
+
+// 发货计划包装类型
+const (
+	FBAShipmentPlanPackageTypeMixed    = 1 // 混装
+	FBAShipmentPlanPackageTypeOriginal = 2 // 原装
+)
+
 type FBAShipmentPlansQueryParams struct {
 	Paging
-	SIDs            string `json:"sids"`                 // 店铺 ids，多个之间以逗号分隔
-	WID             string `json:"wid"`                  // 仓库 ID
-	PackageType     int    `json:"package_type"`         // 包装类型（1：混装、2：原装）
-	SearchFieldTime string `json:"search_field_time"`    // 查找时间字段（gmt_create?）文档不全
-	SearchField     string `json:"search_field"`         // 查找字段（order_sn：发货计划单号）文档不全
-	SearchValue     string `json:"search_value"`         // 查找值
-	Status          string `json:"status"`               // 状态
-	MIDs            string `json:"mids"`                 // 国家 ID
-	StartDate       string `json:"start_date,omitempty"` // 开始日期
-	EndDate         string `json:"end_date,omitempty"`   // 结束日期
+	SIDs            string                         `json:"sids"`              // 店铺 ID（多个使用英文逗号分隔）
+	WID             string                         `json:"wid"`               // 仓库 ID（多个使用英文逗号分隔）
+	PackageType     int                            `json:"package_type"`      // 包装类型（1：混装、2：原装）
+	SearchFieldTime FBAShipmentPlanSearchFieldTime `json:"search_field_time"` // 时间查找字段（gmt_create：创建时间、shipment_time：计划发货时间）
+	SearchField     FBAShipmentPlanSearchField     `json:"search_field"`      // 查找字段（order_sn：发货计划单号、sku：SKU、msku：MSKU、fnsku：FNSKU）
+	SearchValue     string                         `json:"search_value"`      // 查找值
+	Status          string                         `json:"status"`            // 状态（-5：已驳回、0：待审核、5：待处理、10：已处理，多个使用英文逗号分隔）
+	MIDs            string                         `json:"mids"`              // 国家 ID（多个使用英文逗号分隔）
+	StartDate       string                         `json:"start_date"`        // 开始日期（Y-m-d）
+	EndDate         string                         `json:"end_date"`          // 结束日期（Y-m-d）
 }
 
 func (m FBAShipmentPlansQueryParams) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.SIDs, validation.Required.Error("店铺 ID 不能为空")),
 		validation.Field(&m.WID, validation.Required.Error("仓库 ID 不能为空")),
-		validation.Field(&m.PackageType, validation.In(1, 2).Error("无效的包装类型")),
+		validation.Field(&m.PackageType, validation.In(FBAShipmentPlanPackageTypeMixed, FBAShipmentPlanPackageTypeOriginal).Error("无效的包装类型")),
 		validation.Field(&m.SearchFieldTime,
-			validation.Required.Error("查找时间字段不能为空"),
-			validation.In("gmt_create").Error("查找时间字段有误"),
+			validation.Required.Error("时间查找字段不能为空"),
+			validation.In(FBAShipmentPlanSearchFieldTimeCreate, FBAShipmentPlanSearchFieldTimeShipment).Error("时间查找字段有误"),
 		),
 		validation.Field(&m.SearchField,
-			validation.Required.Error("查找字段不能为空"),
-			validation.In("order_sn").Error("查找字段有误"),
+			validation.When(m.SearchValue != "", validation.Required.Error("查找字段不能为空")),
+			validation.In(
+				FBAShipmentPlanSearchFieldOrderSN,
+				FBAShipmentPlanSearchFieldSKU,
+				FBAShipmentPlanSearchFieldMSKU,
+				FBAShipmentPlanSearchFieldFnSKU,
+			).Error("查找字段有误"),
+		),
+		validation.Field(&m.SearchValue, validation.When(m.SearchField != "", validation.Required.Error("查找值不能为空"))),
+		validation.Field(&m.Status,
+			validation.Required.Error("状态不能为空"),
+			validation.By(func(value interface{}) error {
+				for _, v := range strings.Split(value.(string), ",") {
+					status, err := strconv.Atoi(strings.TrimSpace(v))
+					if err != nil || !FBAShipmentPlanStatus(status).IsValid() {
+						return fmt.Errorf("无效的状态 %s", v)
+					}
+				}
+				return nil
+			}),
 		),
-		validation.Field(&m.SearchValue, validation.Required.Error("查找值不能为空")),
-		validation.Field(&m.Status, validation.Required.Error("状态不能为空")),
 		validation.Field(&m.MIDs, validation.Required.Error("国家 ID 不能为空")),
 		validation.Field(&m.StartDate,
 			validation.Required.Error("开始时间不能为空"),
@@ -305,3 +490,153 @@ func (s fbaShipmentService) Plans(params FBAShipmentPlansQueryParams) (items []F
 	}
 	return
 }
+
+// 创建 FBA 发货计划
+
+type CreateFBAShipmentPlanItem struct {
+	SID                int    `json:"sid"`                            // 店铺 ID
+	MSKU               string `json:"msku"`                           // MSKU
+	FnSKU              string `json:"fnsku"`                          // FNSKU
+	WID                int    `json:"wid"`                            // 发货仓库 ID
+	Quantity           int    `json:"shipment_plan_quantity"`         // 计划发货量
+	QuantityInCase     int    `json:"quantity_in_case,omitempty"`     // 单箱数量
+	BoxNum             int    `json:"box_num,omitempty"`              // 箱数
+	PackageType        int    `json:"package_type"`                   // 包装类型（1：混装、2：原装）
+	LogisticsChannelId int    `json:"logistics_channel_id,omitempty"` // 物流 ID
+	ShipmentTime       string `json:"shipment_time,omitempty"`        // 计划发货时间（Y-m-d）
+	Remark             string `json:"remark,omitempty"`               // 备注
+}
+
+func (m CreateFBAShipmentPlanItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SID, validation.Required.Error("店铺不能为空")),
+		validation.Field(&m.MSKU, validation.Required.Error("MSKU 不能为空")),
+		validation.Field(&m.FnSKU, validation.Required.Error("FNSKU 不能为空")),
+		validation.Field(&m.WID, validation.Required.Error("发货仓库不能为空")),
+		validation.Field(&m.Quantity,
+			validation.Required.Error("计划发货量不能为空"),
+			validation.Min(1).Error("计划发货量不能小于 {{.threshold}}"),
+		),
+		validation.Field(&m.QuantityInCase, validation.Min(0).Error("单箱数量不能小于 {{.threshold}}")),
+		validation.Field(&m.BoxNum,
+			validation.Min(0).Error("箱数不能小于 {{.threshold}}"),
+			validation.When(m.QuantityInCase > 0 && m.BoxNum > 0, validation.By(func(value interface{}) error {
+				if m.QuantityInCase*m.BoxNum != m.Quantity {
+					return fmt.Errorf("单箱数量 × 箱数（%d）与计划发货量（%d）不一致", m.QuantityInCase*m.BoxNum, m.Quantity)
+				}
+				return nil
+			})),
+		),
+		validation.Field(&m.PackageType,
+			validation.Required.Error("包装类型不能为空"),
+			validation.In(FBAShipmentPlanPackageTypeMixed, FBAShipmentPlanPackageTypeOriginal).Error("无效的包装类型"),
+		),
+		validation.Field(&m.ShipmentTime, validation.When(m.ShipmentTime != "", validation.Date(constant.DateFormat).Error("计划发货时间格式有误"))),
+	)
+}
+
+type CreateFBAShipmentPlanRequest struct {
+	Remark string                      `json:"remark,omitempty"` // 发货计划组备注
+	Items  []CreateFBAShipmentPlanItem `json:"list"`             // 发货计划
+}
+
+func (m CreateFBAShipmentPlanRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Items, validation.Required.Error("发货计划不能为空")),
+	)
+}
+
+// CreatePlan 创建 FBA 发货计划，返回发货计划组 ID 和发货计划单号，创建后的发货计划为待审核状态
+func (s fbaShipmentService) CreatePlan(req CreateFBAShipmentPlanRequest) (ispgId int, orderSNs []string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			IspgId   int      `json:"ispg_id"`
+			OrderSNs []string `json:"order_sns"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/fba/shipmentPlan/create")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		ispgId = res.Data.IspgId
+		orderSNs = res.Data.OrderSNs
+	}
+	return
+}
+
+// FBA 发货计划状态变更
+
+// fbaShipmentPlanStatusTransitions 发货计划状态允许变更的目标状态
+var fbaShipmentPlanStatusTransitions = statusTransitions[FBAShipmentPlanStatus]{
+	FBAShipmentPlanStatusPendingReview: {FBAShipmentPlanStatusPending, FBAShipmentPlanStatusRejected},
+	FBAShipmentPlanStatusRejected:      {FBAShipmentPlanStatusPendingReview},
+}
+
+// CanChangeFBAShipmentPlanStatus 判断发货计划是否可以从 from 状态变更为 to 状态
+// 待处理的发货计划生成发货单后变为已处理，已处理的发货计划不能再变更状态
+func CanChangeFBAShipmentPlanStatus(from, to FBAShipmentPlanStatus) bool {
+	return fbaShipmentPlanStatusTransitions.can(from, to)
+}
+
+type FBAShipmentPlanStatusRequest struct {
+	IspIds []int                  `json:"isp_ids"`          // 发货计划 ID
+	Status *FBAShipmentPlanStatus `json:"status"`           // 目标状态（0：待审核（重新提交）、5：待处理（审核通过）、-5：已驳回），待审核为 0，因此使用指针以区分未设置
+	Remark string                 `json:"remark,omitempty"` // 备注（驳回原因）
+}
+
+func (m FBAShipmentPlanStatusRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.IspIds, validation.Required.Error("发货计划 ID 不能为空")),
+		validation.Field(&m.Status,
+			validation.NotNil.Error("目标状态不能为空"),
+			validation.In(
+				FBAShipmentPlanStatusPendingReview,
+				FBAShipmentPlanStatusPending,
+				FBAShipmentPlanStatusRejected,
+			).Error("无效的目标状态"),
+		),
+		validation.Field(&m.Remark, validation.When(m.Status != nil && *m.Status == FBAShipmentPlanStatusRejected, validation.Required.Error("驳回原因不能为空"))),
+	)
+}
+
+// ChangePlanStatus 检查发货计划是否可以变更为 status 状态后变更 FBA 发货计划状态
+func (s fbaShipmentService) ChangePlanStatus(status FBAShipmentPlanStatus, reason string, items ...FBAShipmentPlanItem) (err error) {
+	req := FBAShipmentPlanStatusRequest{
+		IspIds: make([]int, len(items)),
+		Status: &status,
+		Remark: reason,
+	}
+	for i, item := range items {
+		if !CanChangeFBAShipmentPlanStatus(item.Status, status) {
+			return fmt.Errorf("lingxing: 发货计划 %d 不能从%s变更为%s", item.IspId, item.Status, status)
+		}
+		req.IspIds[i] = item.IspId
+	}
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/fba/shipmentPlan/changeStatus")
+	return
+}
+
+// ApprovePlans 审核通过 FBA 发货计划（待审核 -> 待处理）
+func (s fbaShipmentService) ApprovePlans(items ...FBAShipmentPlanItem) error {
+	return s.ChangePlanStatus(FBAShipmentPlanStatusPending, "", items...)
+}
+
+// RejectPlans 驳回 FBA 发货计划（待审核 -> 已驳回）
+func (s fbaShipmentService) RejectPlans(reason string, items ...FBAShipmentPlanItem) error {
+	return s.ChangePlanStatus(FBAShipmentPlanStatusRejected, reason, items...)
+}
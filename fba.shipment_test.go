@@ -2,6 +2,7 @@ package lingxing
 
 import (
 	"github.com/hiscaler/gox/jsonx"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -24,7 +25,7 @@ func Test_warehouseService_Plans(t *testing.T) {
 			SearchFieldTime: "gmt_create",
 			SearchField:     "order_sn",
 			SearchValue:     "123",
-			Status:          "1",
+			Status:          "0,5",
 			MIDs:            "1",
 			StartDate:       "2022-09-01",
 			EndDate:         "2022-09-01",
@@ -53,3 +54,106 @@ func Test_warehouseService_Plans(t *testing.T) {
 		})
 	}
 }
+
+func TestFBAShipmentDetail_Unmarshal(t *testing.T) {
+	var d FBAShipmentDetail
+	err := jsoniter.UnmarshalFromString(`{"id":1,"shipment_sn":"SP1","status":1,"head_fee_type":3,"items":[{"sku":"A","msku":"A-US","fnsku":"X001","num":"10","quantity_shipped":10},{"sku":"B","num":5}],"box_list":[{"box_no":"1","length":"50","weight":12.5,"items":[{"sku":"A","quantity":10}]}],"logistics":[{"tracking_number":"1Z"}],"relate_list":[{"shipment_id":"FBA1","msku":"A-US","num":10}],"not_relate_list":[],"fee_list":[{"fee_type_name":"头程","amount":"100.5","currency":"CNY"}]}`, &d)
+	assert.Nil(t, err)
+	assert.Equal(t, ShipmentSheetStatusShipped, d.Status)
+	assert.Equal(t, HeadFeeTypeQuantity, d.HeadFeeType)
+	assert.Equal(t, 15, d.Quantity())
+	assert.Equal(t, "X001", d.Items[0].FnSKU)
+	assert.Equal(t, 50.0, d.Boxes[0].Length)
+	assert.Equal(t, 10, d.Boxes[0].Items[0].Quantity)
+	assert.Equal(t, "1Z", d.Logistics[0].TrackingNumber)
+	assert.Equal(t, "FBA1", d.RelateList[0].ShipmentId)
+	assert.Equal(t, 100.5, d.Fees[0].Amount)
+}
+
+func TestFBAShipment_UnmarshalNotRelateList(t *testing.T) {
+	var shipment FBAShipment
+	err := jsoniter.UnmarshalFromString(`{"id":1,"shipment_sn":"SP1","not_relate_list":[{"sku":"A","msku":"A-US","num":10}]}`, &shipment)
+	assert.Nil(t, err)
+	if assert.Len(t, shipment.NotRelateList, 1) {
+		assert.Equal(t, "A-US", shipment.NotRelateList[0].MSKU)
+		assert.Equal(t, 10, shipment.NotRelateList[0].Num)
+	}
+}
+
+func TestFBAShipmentService_OneEmptyShipmentSN(t *testing.T) {
+	_, err := lingXingClient.Services.FBA.Shipment.One("")
+	assert.ErrorIs(t, err, ErrNotFound)
+	items, err := lingXingClient.Services.FBA.Shipment.Details("", "")
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
+
+func TestFBAShipmentPlansQueryParams_Validate(t *testing.T) {
+	params := FBAShipmentPlansQueryParams{
+		SearchFieldTime: FBAShipmentPlanSearchFieldTimeCreate,
+		StartDate:       "2022-09-01",
+		EndDate:         "2022-09-30",
+	}
+	assert.NotNil(t, params.Validate(), "without sids, wid, status and mids")
+	params.SIDs = "1,2"
+	params.WID = "1"
+	params.Status = "0"
+	params.MIDs = "1"
+	assert.Nil(t, params.Validate())
+
+	params.SearchFieldTime = "bad"
+	assert.NotNil(t, params.Validate())
+	params.SearchFieldTime = FBAShipmentPlanSearchFieldTimeShipment
+
+	params.SearchValue = "SP1"
+	assert.NotNil(t, params.Validate(), "search value without search field")
+	params.SearchField = FBAShipmentPlanSearchFieldOrderSN
+	assert.Nil(t, params.Validate())
+
+	params.Status = "-5, 10"
+	assert.Nil(t, params.Validate())
+	params.Status = "1"
+	assert.NotNil(t, params.Validate())
+}
+
+func TestCreateFBAShipmentPlanRequest_Validate(t *testing.T) {
+	req := CreateFBAShipmentPlanRequest{Items: []CreateFBAShipmentPlanItem{
+		{SID: 1, MSKU: "A-US", FnSKU: "X001", WID: 1, Quantity: 100, QuantityInCase: 20, BoxNum: 5, PackageType: FBAShipmentPlanPackageTypeOriginal},
+	}}
+	assert.Nil(t, req.Validate())
+	req.Items[0].BoxNum = 4
+	assert.NotNil(t, req.Validate(), "box quantity mismatch")
+	req.Items[0].BoxNum = 0
+	assert.Nil(t, req.Validate())
+	req.Items[0].PackageType = 3
+	assert.NotNil(t, req.Validate())
+}
+
+func TestCanChangeFBAShipmentPlanStatus(t *testing.T) {
+	assert.True(t, CanChangeFBAShipmentPlanStatus(FBAShipmentPlanStatusPendingReview, FBAShipmentPlanStatusPending))
+	assert.True(t, CanChangeFBAShipmentPlanStatus(FBAShipmentPlanStatusPendingReview, FBAShipmentPlanStatusRejected))
+	assert.True(t, CanChangeFBAShipmentPlanStatus(FBAShipmentPlanStatusRejected, FBAShipmentPlanStatusPendingReview))
+	assert.False(t, CanChangeFBAShipmentPlanStatus(FBAShipmentPlanStatusProcessed, FBAShipmentPlanStatusPending))
+	assert.NotNil(t, FBAShipmentPlanStatusRequest{IspIds: []int{1}}.Validate(), "without status")
+	pendingReview, rejected, processed := FBAShipmentPlanStatusPendingReview, FBAShipmentPlanStatusRejected, FBAShipmentPlanStatusProcessed
+	assert.Nil(t, FBAShipmentPlanStatusRequest{IspIds: []int{1}, Status: &pendingReview}.Validate())
+	assert.NotNil(t, FBAShipmentPlanStatusRequest{IspIds: []int{1}, Status: &rejected}.Validate(), "without reason")
+	assert.NotNil(t, FBAShipmentPlanStatusRequest{IspIds: []int{1}, Status: &processed}.Validate())
+}
+
+func TestFbaShipmentService_ChangePlanStatusTransition(t *testing.T) {
+	s := fbaShipmentService{}
+	err := s.ApprovePlans(FBAShipmentPlanItem{IspId: 1, Status: FBAShipmentPlanStatusPendingReview}, FBAShipmentPlanItem{IspId: 2, Status: FBAShipmentPlanStatusProcessed})
+	assert.EqualError(t, err, "lingxing: 发货计划 2 不能从已处理变更为待处理")
+	assert.NotNil(t, s.RejectPlans("reason"), "empty plans")
+}
+
+func TestFBAShipmentsQueryParams_Status(t *testing.T) {
+	b, _ := jsoniter.Marshal(FBAShipmentsQueryParams{})
+	assert.NotContains(t, string(b), "status")
+	status := ShipmentSheetStatusPendingShipment
+	b, _ = jsoniter.Marshal(FBAShipmentsQueryParams{Status: &status})
+	assert.Contains(t, string(b), `"status":0`)
+	invalid := ShipmentSheetStatus(9)
+	assert.NotNil(t, FBAShipmentsQueryParams{Status: &invalid}.Validate())
+}
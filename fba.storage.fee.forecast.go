@@ -0,0 +1,411 @@
+package lingxing
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/lingxing/constant"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 长期仓储费预测
+// 根据 FBA 库存的库龄分布推算下次评估日的库龄，扣除评估日前的预计销量（先进先出，优先消耗库龄最长的库存）后，
+// 库龄 181-365 天的商品按 6-12 个月收费标准收费，库龄 365 天以上的商品按 12 个月以上收费标准收费
+// 收费标准（每单位体积）为 0 时，使用历史长期仓储费按国家推算（收费合计 / 收费商品体积合计）
+// 商品体积优先使用月仓储费中的体积，其次使用历史长期仓储费中的单个商品体积，两者的体积单位需保持一致
+
+// 库龄
+const (
+	longTermStorageFeeSixMonthsAge    = 181 // 6-12 个月收费的起始库龄（天）
+	longTermStorageFeeTwelveMonthsAge = 366 // 12 个月以上收费的起始库龄（天）
+)
+
+// LongTermStorageFeeForecastOptions 长期仓储费预测选项
+type LongTermStorageFeeForecastOptions struct {
+	Date                   time.Time `json:"date"`                      // 库存日期（为零值时使用当前日期）
+	AssessmentDate         time.Time `json:"assessment_date"`           // 下次评估日期（必填）
+	SixMonthsRate          float64   `json:"six_months_rate"`           // 6-12 个月收费标准（每单位体积）
+	TwelveMonthsRate       float64   `json:"twelve_months_rate"`        // 12 个月以上收费标准（每单位体积）
+	TwelveMonthsMinimumFee float64   `json:"twelve_months_minimum_fee"` // 12 个月以上每件商品的最低收费
+	RemovalFee             float64   `json:"removal_fee"`               // 每件商品的移除费用
+}
+
+func (m LongTermStorageFeeForecastOptions) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.AssessmentDate, validation.Required.Error("评估日期不能为空")),
+	)
+}
+
+// LongTermStorageFeeForecast 长期仓储费预测结果
+type LongTermStorageFeeForecast struct {
+	SID                  int     `json:"sid"`                    // 店铺 ID
+	FnSKU                string  `json:"fnsku"`                  // FNSKU
+	ASIN                 string  `json:"asin"`                   // ASIN
+	SKU                  string  `json:"sku"`                    // 本地 SKU
+	Country              string  `json:"country"`                // 国家
+	Currency             string  `json:"currency"`               // 币种
+	Quantity             int     `json:"quantity"`               // 当前库存量（各库龄库存量合计）
+	AgedQuantity         int     `json:"aged_quantity"`          // 当前库龄超过 181 天的库存量
+	DailySales           float64 `json:"daily_sales"`            // 日均销量
+	SalesQuantity        int     `json:"sales_quantity"`         // 评估日前预计销量
+	PerUnitVolume        float64 `json:"per_unit_volume"`        // 单个商品体积
+	SixMonthsQuantity    int     `json:"six_months_quantity"`    // 预计 6-12 个月收费商品量
+	SixMonthsFee         float64 `json:"six_months_fee"`         // 预计 6-12 个月收费
+	TwelveMonthsQuantity int     `json:"twelve_months_quantity"` // 预计 12 个月以上收费商品量
+	TwelveMonthsFee      float64 `json:"twelve_months_fee"`      // 预计 12 个月以上收费
+	TotalFee             float64 `json:"total_fee"`              // 预计长期仓储费合计
+	MonthlyStorageFee    float64 `json:"monthly_storage_fee"`    // 每件商品的月仓储费
+	RemovalQuantity      int     `json:"removal_quantity"`       // 建议移除量
+}
+
+type longTermStorageFeeInventory struct {
+	sid        int
+	fnSKU      string
+	asin       string
+	sku        string
+	country    string
+	ages       [5]int // 库龄 0-90、91-180、181-270、271-365、365 天以上的库存量
+	dailySales float64
+	hasSales   bool // 是否已设置日均销量
+}
+
+type longTermStorageFeeVolume struct {
+	month       string
+	volume      float64
+	storageRate float64
+	currency    string
+}
+
+type longTermStorageFeeRate struct {
+	currency           string
+	sixMonthsFee       float64
+	sixMonthsVolume    float64
+	twelveMonthsFee    float64
+	twelveMonthsVolume float64
+}
+
+// LongTermStorageFeeForecaster 长期仓储费预测器，FNSKU 不区分大小写
+type LongTermStorageFeeForecaster struct {
+	options     LongTermStorageFeeForecastOptions
+	inventories map[string]*longTermStorageFeeInventory // 店铺 ID + FNSKU
+	volumes     map[string]*longTermStorageFeeVolume    // 店铺 ID + FNSKU => 月仓储费中的体积和收费标准
+	ltsfVolumes map[string]*longTermStorageFeeVolume    // 店铺 ID + FNSKU => 历史长期仓储费中的单个商品体积
+	rates       map[string]*longTermStorageFeeRate      // 国家 => 历史长期仓储费
+	asinSales   asinSales                               // 店铺 ID + ASIN => 日均销量
+}
+
+func NewLongTermStorageFeeForecaster(options LongTermStorageFeeForecastOptions) *LongTermStorageFeeForecaster {
+	return &LongTermStorageFeeForecaster{
+		options:     options,
+		inventories: make(map[string]*longTermStorageFeeInventory),
+		volumes:     make(map[string]*longTermStorageFeeVolume),
+		ltsfVolumes: make(map[string]*longTermStorageFeeVolume),
+		rates:       make(map[string]*longTermStorageFeeRate),
+		asinSales:   make(asinSales),
+	}
+}
+
+func (f *LongTermStorageFeeForecaster) inventory(sid int, fnSKU string) *longTermStorageFeeInventory {
+	key := sidKey(sid, fnSKU)
+	v, ok := f.inventories[key]
+	if !ok {
+		v = &longTermStorageFeeInventory{sid: sid, fnSKU: fnSKU}
+		f.inventories[key] = v
+	}
+	return v
+}
+
+// AddInventories 添加 FBA 库存，同一店铺下 FNSKU 相同的库存合并计算
+func (f *LongTermStorageFeeForecaster) AddInventories(inventories ...FBAInventory) {
+	for _, item := range inventories {
+		if item.FnSKU == "" {
+			continue
+		}
+		v := f.inventory(item.SID, item.FnSKU)
+		if item.ASIN != "" {
+			v.asin = item.ASIN
+		}
+		if item.SKU != "" {
+			v.sku = item.SKU
+		}
+		if item.Country != "" {
+			v.country = item.Country
+		}
+		for i, n := range []int{item.InvAge0To90Days, item.InvAge91To180Days, item.InvAge181To270Days, item.InvAge271To365Days, item.InvAge365PlusDays} {
+			v.ages[i] += n
+		}
+	}
+}
+
+// AddMonthStorageFees 添加月仓储费，同一 FNSKU 使用收费月份最新的商品体积和收费标准
+func (f *LongTermStorageFeeForecaster) AddMonthStorageFees(fees ...FBAMonthStorageFee) {
+	for _, fee := range fees {
+		if fee.FnSKU == "" || fee.ItemVolume <= 0 {
+			continue
+		}
+		key := sidKey(fee.SID, fee.FnSKU)
+		if v, ok := f.volumes[key]; ok && v.month > fee.MonthOfCharge {
+			continue
+		}
+		f.volumes[key] = &longTermStorageFeeVolume{
+			month:       fee.MonthOfCharge,
+			volume:      fee.ItemVolume,
+			storageRate: fee.StorageRate,
+			currency:    fee.Currency,
+		}
+	}
+}
+
+// AddLongTermStorageFees 添加历史长期仓储费，用于推算各国家的收费标准和商品体积
+func (f *LongTermStorageFeeForecaster) AddLongTermStorageFees(fees ...FBALongTermStorageFee) {
+	for _, fee := range fees {
+		if fee.PerUnitVolume <= 0 {
+			continue
+		}
+		if fee.FnSKU != "" {
+			key := sidKey(fee.SID, fee.FnSKU)
+			if v, ok := f.ltsfVolumes[key]; !ok || v.month <= fee.SnapshotDate {
+				f.ltsfVolumes[key] = &longTermStorageFeeVolume{
+					month:    fee.SnapshotDate,
+					volume:   fee.PerUnitVolume,
+					currency: fee.Currency,
+				}
+			}
+		}
+
+		country := strings.ToUpper(strings.TrimSpace(fee.Country))
+		r, ok := f.rates[country]
+		if !ok {
+			r = &longTermStorageFeeRate{}
+			f.rates[country] = r
+		}
+		if fee.Currency != "" {
+			r.currency = fee.Currency
+		}
+		if fee.QtyCharged6MonthsLongTermStorageFee > 0 {
+			r.sixMonthsFee += fee.SixMonthsLongTermsStorageFee
+			r.sixMonthsVolume += fee.PerUnitVolume * float64(fee.QtyCharged6MonthsLongTermStorageFee)
+		}
+		if fee.QtyCharged12monthsLongTermStorageFee > 0 {
+			r.twelveMonthsFee += fee.TwelveMonthsLongTermsStorageFee
+			r.twelveMonthsVolume += fee.PerUnitVolume * float64(fee.QtyCharged12monthsLongTermStorageFee)
+		}
+	}
+}
+
+// AddProductReports 添加产品表现，days 为产品表现的统计天数，日均销量 = 销量 / days
+// 产品表现按 ASIN 统计，同一店铺下多个 FNSKU 对应同一 ASIN 时销量平均分配到各 FNSKU
+func (f *LongTermStorageFeeForecaster) AddProductReports(days int, reports ...ProductReport) {
+	f.asinSales.add(days, reports...)
+}
+
+// SetDailySales 设置店铺 FNSKU 的日均销量，设置后将不再使用产品表现计算的日均销量
+func (f *LongTermStorageFeeForecaster) SetDailySales(sid int, fnSKU string, dailySales float64) {
+	v := f.inventory(sid, fnSKU)
+	v.dailySales = dailySales
+	v.hasSales = true
+}
+
+// agedQuantity 经过 days 天后库龄不小于 age 天的库存量（各库龄段内的库存按库龄均匀分布）
+func (v longTermStorageFeeInventory) agedQuantity(days, age int) float64 {
+	ranges := [4][2]int{{0, 90}, {91, 180}, {181, 270}, {271, 365}}
+	quantity := 0.0
+	for i, r := range ranges {
+		n := float64(v.ages[i])
+		switch {
+		case n == 0 || r[1]+days < age:
+		case r[0]+days >= age:
+			quantity += n
+		default:
+			quantity += n * float64(r[1]+days-age+1) / float64(r[1]-r[0]+1)
+		}
+	}
+	// 库龄 365 天以上的库存没有上限，库龄不小于 366 + days 天
+	if 366+days >= age {
+		quantity += float64(v.ages[4])
+	}
+	return quantity
+}
+
+func (f *LongTermStorageFeeForecaster) rate(country string) (sixMonthsRate, twelveMonthsRate float64, currency string) {
+	sixMonthsRate, twelveMonthsRate = f.options.SixMonthsRate, f.options.TwelveMonthsRate
+	r, ok := f.rates[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return
+	}
+	currency = r.currency
+	if sixMonthsRate == 0 && r.sixMonthsVolume > 0 {
+		sixMonthsRate = r.sixMonthsFee / r.sixMonthsVolume
+	}
+	if twelveMonthsRate == 0 && r.twelveMonthsVolume > 0 {
+		twelveMonthsRate = r.twelveMonthsFee / r.twelveMonthsVolume
+	}
+	return
+}
+
+// Forecast 预测下次评估日的长期仓储费（仅返回预计收费或建议移除的 FNSKU，按店铺 ID、FNSKU 排序）
+// 每件收费商品的长期仓储费与月仓储费之和大于移除费用时，建议移除该部分收费商品
+func (f *LongTermStorageFeeForecaster) Forecast() (forecasts []LongTermStorageFeeForecast, err error) {
+	if err = f.options.Validate(); err != nil {
+		return
+	}
+
+	date := f.options.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	days := int(math.Floor(f.options.AssessmentDate.Sub(date).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+
+	asins := make([]string, 0, len(f.inventories))
+	for _, v := range f.inventories {
+		if v.asin != "" {
+			asins = append(asins, sidKey(v.sid, v.asin))
+		}
+	}
+	asinSales := f.asinSales.split(asins...)
+
+	forecasts = make([]LongTermStorageFeeForecast, 0)
+	for key, v := range f.inventories {
+		quantity := 0
+		for _, n := range v.ages {
+			quantity += n
+		}
+		if quantity == 0 {
+			continue
+		}
+
+		dailySales := v.dailySales
+		if !v.hasSales && v.asin != "" {
+			dailySales = asinSales[sidKey(v.sid, v.asin)]
+		}
+		forecast := LongTermStorageFeeForecast{
+			SID:          v.sid,
+			FnSKU:        v.fnSKU,
+			ASIN:         v.asin,
+			SKU:          v.sku,
+			Country:      v.country,
+			Quantity:     quantity,
+			AgedQuantity: v.ages[2] + v.ages[3] + v.ages[4],
+			DailySales:   dailySales,
+		}
+		sixMonthsRate, twelveMonthsRate, currency := f.rate(v.country)
+		forecast.Currency = currency
+		if volume, ok := f.volumes[key]; ok {
+			forecast.PerUnitVolume = volume.volume
+			forecast.MonthlyStorageFee = volume.volume * volume.storageRate
+			if volume.currency != "" {
+				forecast.Currency = volume.currency
+			}
+		} else if volume, ok = f.ltsfVolumes[key]; ok {
+			forecast.PerUnitVolume = volume.volume
+			if forecast.Currency == "" {
+				forecast.Currency = volume.currency
+			}
+		}
+
+		// 评估日前的销量优先消耗库龄最长的库存
+		twelveMonthsQuantity := int(math.Round(v.agedQuantity(days, longTermStorageFeeTwelveMonthsAge)))
+		sixMonthsQuantity := int(math.Round(v.agedQuantity(days, longTermStorageFeeSixMonthsAge))) - twelveMonthsQuantity
+		salesQuantity := int(math.Min(math.Round(dailySales*float64(days)), float64(quantity)))
+		forecast.SalesQuantity = salesQuantity
+		n := salesQuantity
+		if n > twelveMonthsQuantity {
+			n = twelveMonthsQuantity
+		}
+		twelveMonthsQuantity -= n
+		n = salesQuantity - n
+		if n > sixMonthsQuantity {
+			n = sixMonthsQuantity
+		}
+		sixMonthsQuantity -= n
+
+		forecast.SixMonthsQuantity = sixMonthsQuantity
+		forecast.SixMonthsFee = float64(sixMonthsQuantity) * forecast.PerUnitVolume * sixMonthsRate
+		forecast.TwelveMonthsQuantity = twelveMonthsQuantity
+		forecast.TwelveMonthsFee = math.Max(
+			float64(twelveMonthsQuantity)*forecast.PerUnitVolume*twelveMonthsRate,
+			float64(twelveMonthsQuantity)*f.options.TwelveMonthsMinimumFee,
+		)
+		forecast.TotalFee = forecast.SixMonthsFee + forecast.TwelveMonthsFee
+		if sixMonthsQuantity > 0 && forecast.SixMonthsFee/float64(sixMonthsQuantity)+forecast.MonthlyStorageFee > f.options.RemovalFee {
+			forecast.RemovalQuantity += sixMonthsQuantity
+		}
+		if twelveMonthsQuantity > 0 && forecast.TwelveMonthsFee/float64(twelveMonthsQuantity)+forecast.MonthlyStorageFee > f.options.RemovalFee {
+			forecast.RemovalQuantity += twelveMonthsQuantity
+		}
+		if forecast.TotalFee > 0 || forecast.RemovalQuantity > 0 {
+			forecasts = append(forecasts, forecast)
+		}
+	}
+	sort.Slice(forecasts, func(i, j int) bool {
+		a, b := forecasts[i], forecasts[j]
+		if a.SID != b.SID {
+			return a.SID < b.SID
+		}
+		return strings.ToUpper(a.FnSKU) < strings.ToUpper(b.FnSKU)
+	})
+	return
+}
+
+// Load 从接口加载店铺的 FBA 库存、指定日期范围（Y-m-d，开始日期为闭区间，结束日期为开区间）内的历史长期仓储费和产品表现，
+// 以及结束日期上一个月的月仓储费
+func (f *LongTermStorageFeeForecaster) Load(client *LingXing, sid int, startDate, endDate string) error {
+	start, err := time.Parse(constant.DateFormat, startDate)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(constant.DateFormat, endDate)
+	if err != nil {
+		return err
+	}
+	days := int(end.Sub(start).Hours() / 24)
+
+	inventoryParams := FBAInventoriesQueryParams{SIDs: strconv.Itoa(sid)}
+	inventories, err := queryAll(func(offset int) ([]FBAInventory, int, bool, error) {
+		inventoryParams.Offset = offset
+		return client.Services.FBA.Inventory.All(inventoryParams)
+	})
+	if err != nil {
+		return err
+	}
+	f.AddInventories(inventories...)
+
+	longTermParams := FBALongTermStorageFeesQueryParams{SID: sid, StartDate: startDate, EndDate: endDate}
+	longTermFees, err := queryAll(func(offset int) ([]FBALongTermStorageFee, int, bool, error) {
+		longTermParams.Offset = offset
+		return client.Services.FBA.StorageFee.LongTerm(longTermParams)
+	})
+	if err != nil {
+		return err
+	}
+	f.AddLongTermStorageFees(longTermFees...)
+
+	monthParams := FBAMonthStorageFeesQueryParams{
+		SID:   sid,
+		Month: time.Date(end.Year(), end.Month()-1, 1, 0, 0, 0, 0, end.Location()).Format("2006-01"),
+	}
+	monthFees, err := queryAll(func(offset int) ([]FBAMonthStorageFee, int, bool, error) {
+		monthParams.Offset = offset
+		return client.Services.FBA.StorageFee.Month(monthParams)
+	})
+	if err != nil {
+		return err
+	}
+	f.AddMonthStorageFees(monthFees...)
+
+	reportParams := ProductStatisticQueryParams{SID: sid, StartDate: startDate, EndDate: endDate}
+	reports, err := queryAll(func(offset int) ([]ProductReport, int, bool, error) {
+		reportParams.Offset = offset
+		return client.Services.Statistic.Products(reportParams)
+	})
+	if err != nil {
+		return err
+	}
+	f.AddProductReports(days, reports...)
+	return nil
+}
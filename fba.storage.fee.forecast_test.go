@@ -0,0 +1,85 @@
+package lingxing
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFBALongTermStorageFee_Unmarshal(t *testing.T) {
+	fee := FBALongTermStorageFee{}
+	err := jsoniter.Unmarshal([]byte(`{"qty_charged_12_mo_long_term_storage_fee":"3","per_unit_volume":"0.25","12_mo_long_terms_storage_fee":"5.18","qty_charged_6_mo_long_term_storage_fee":"","6_mo_long_terms_storage_fee":""}`), &fee)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, fee.QtyCharged12monthsLongTermStorageFee)
+		assert.Equal(t, 0.25, fee.PerUnitVolume)
+		assert.Equal(t, 5.18, fee.TwelveMonthsLongTermsStorageFee)
+		assert.Equal(t, 0, fee.QtyCharged6MonthsLongTermStorageFee)
+		assert.Equal(t, 0.0, fee.SixMonthsLongTermsStorageFee)
+	}
+}
+
+func TestLongTermStorageFeeForecaster_Forecast(t *testing.T) {
+	f := NewLongTermStorageFeeForecaster(LongTermStorageFeeForecastOptions{
+		Date:                   time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		AssessmentDate:         time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC),
+		TwelveMonthsMinimumFee: 0.15,
+		RemovalFee:             0.5,
+	})
+	f.AddInventories(
+		FBAInventory{SID: 1, FnSKU: "X1", ASIN: "B01", SKU: "A", Country: "US", InvAge0To90Days: 100, InvAge91To180Days: 90, InvAge181To270Days: 50, InvAge271To365Days: 95, InvAge365PlusDays: 10},
+		FBAInventory{SID: 1, FnSKU: "X2", ASIN: "B02", SKU: "B", Country: "US", InvAge365PlusDays: 20},
+		FBAInventory{SID: 1, FnSKU: "X3", ASIN: "B03", SKU: "C", Country: "US", InvAge0To90Days: 10},
+	)
+	// 6-12 个月收费标准 15 / (10 × 0.5) = 3，12 个月以上收费标准 12 / (2 × 1) = 6
+	f.AddLongTermStorageFees(
+		FBALongTermStorageFee{SID: 1, FnSKU: "X1", Country: "US", Currency: "USD", PerUnitVolume: 0.5, QtyCharged6MonthsLongTermStorageFee: 10, SixMonthsLongTermsStorageFee: 15},
+		FBALongTermStorageFee{SID: 1, FnSKU: "x2", Country: "US", Currency: "USD", PerUnitVolume: 1, QtyCharged12monthsLongTermStorageFee: 2, TwelveMonthsLongTermsStorageFee: 12},
+	)
+	f.AddMonthStorageFees(
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", MonthOfCharge: "2022-08", ItemVolume: 0.1, StorageRate: 0.5},
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", MonthOfCharge: "2022-09", ItemVolume: 0.2, StorageRate: 0.75, Currency: "USD"},
+	)
+	f.AddProductReports(30, ProductReport{SID: 1, ASIN: "B01", Volume: 60}, ProductReport{SID: 1, ASIN: "B02", Volume: 300})
+	f.SetDailySales(1, "x2", 0)
+
+	forecasts, err := f.Forecast()
+	assert.NoError(t, err)
+	if assert.Len(t, forecasts, 2) {
+		// 30 天后 12 个月以上：30 + 10 = 40，6-12 个月：30 + 50 + 95 + 10 - 40 = 145，预计销量 60 优先消耗 12 个月以上的库存
+		x1 := forecasts[0]
+		assert.Equal(t, "X1", x1.FnSKU)
+		assert.Equal(t, "USD", x1.Currency)
+		assert.Equal(t, 345, x1.Quantity)
+		assert.Equal(t, 155, x1.AgedQuantity)
+		assert.Equal(t, 60, x1.SalesQuantity)
+		assert.Equal(t, 0, x1.TwelveMonthsQuantity)
+		assert.Equal(t, 125, x1.SixMonthsQuantity)
+		assert.InDelta(t, 75, x1.SixMonthsFee, 0.0001)
+		assert.InDelta(t, 75, x1.TotalFee, 0.0001)
+		assert.InDelta(t, 0.15, x1.MonthlyStorageFee, 0.0001)
+		assert.Equal(t, 125, x1.RemovalQuantity)
+
+		x2 := forecasts[1]
+		assert.Equal(t, "X2", x2.FnSKU)
+		assert.Equal(t, 0.0, x2.DailySales)
+		assert.Equal(t, 1.0, x2.PerUnitVolume)
+		assert.Equal(t, 20, x2.TwelveMonthsQuantity)
+		assert.InDelta(t, 120, x2.TwelveMonthsFee, 0.0001)
+		assert.Equal(t, 20, x2.RemovalQuantity)
+	}
+}
+
+func TestLongTermStorageFeeForecaster_ForecastAssessmentDate(t *testing.T) {
+	f := NewLongTermStorageFeeForecaster(LongTermStorageFeeForecastOptions{SixMonthsRate: 1})
+	f.AddInventories(FBAInventory{SID: 1, FnSKU: "X1", InvAge181To270Days: 10})
+	_, err := f.Forecast()
+	assert.Error(t, err)
+}
+
+func TestLongTermStorageFeeInventory_AgedQuantity(t *testing.T) {
+	v := longTermStorageFeeInventory{ages: [5]int{0, 0, 0, 0, 10}}
+	assert.Equal(t, 10.0, v.agedQuantity(0, longTermStorageFeeTwelveMonthsAge))
+	assert.Equal(t, 10.0, v.agedQuantity(3650, longTermStorageFeeTwelveMonthsAge))
+	assert.Equal(t, 10.0, v.agedQuantity(30, longTermStorageFeeSixMonthsAge))
+}
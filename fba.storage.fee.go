@@ -10,23 +10,23 @@ import (
 type fbaStorageFeeService service
 
 type FBALongTermStorageFee struct {
-	SID                                  int    `json:"sid"`                                     // 店铺ID
-	SnapshotDate                         string `json:"snapshot_date"`                           // 时间
-	SKU                                  string `json:"sku"`                                     // SKU
-	FnSKU                                string `json:"fnsku"`                                   // FNSKU
-	ASIN                                 string `json:"asin"`                                    // ASIN
-	ProductName                          string `json:"product_name"`                            // 标题
-	Condition                            string `json:"condition"`                               // 状况
-	QtyCharged12monthsLongTermStorageFee string `json:"qty_charged_12_mo_long_term_storage_fee"` // 12个月以上收费商品量
-	PerUnitVolume                        string `json:"per_unit_volume"`                         // 单个商品体积
-	Currency                             string `json:"currency"`                                // 币种
-	TwelveMonthsLongTermsStorageFee      string `json:"12_mo_long_terms_storage_fee"`            // 12个月以上收费
-	QtyCharged6MonthsLongTermStorageFee  string `json:"qty_charged_6_mo_long_term_storage_fee"`  // 6-12个月收费商品量
-	SixMonthsLongTermsStorageFee         string `json:"6_mo_long_terms_storage_fee"`             // 6-12个月收费
-	VolumeUnit                           string `json:"volume_unit"`                             // 体积单位
-	Country                              string `json:"country"`                                 // 国家
-	IsSmallAndLight                      string `json:"is_small_and_light"`
-	EnrolledInSmallAndLight              string `json:"enrolled_in_small_and_light"`
+	SID                                  int     `json:"sid"`                                     // 店铺ID
+	SnapshotDate                         string  `json:"snapshot_date"`                           // 时间
+	SKU                                  string  `json:"sku"`                                     // SKU
+	FnSKU                                string  `json:"fnsku"`                                   // FNSKU
+	ASIN                                 string  `json:"asin"`                                    // ASIN
+	ProductName                          string  `json:"product_name"`                            // 标题
+	Condition                            string  `json:"condition"`                               // 状况
+	QtyCharged12monthsLongTermStorageFee int     `json:"qty_charged_12_mo_long_term_storage_fee"` // 12个月以上收费商品量
+	PerUnitVolume                        float64 `json:"per_unit_volume"`                         // 单个商品体积
+	Currency                             string  `json:"currency"`                                // 币种
+	TwelveMonthsLongTermsStorageFee      float64 `json:"12_mo_long_terms_storage_fee"`            // 12个月以上收费
+	QtyCharged6MonthsLongTermStorageFee  int     `json:"qty_charged_6_mo_long_term_storage_fee"`  // 6-12个月收费商品量
+	SixMonthsLongTermsStorageFee         float64 `json:"6_mo_long_terms_storage_fee"`             // 6-12个月收费
+	VolumeUnit                           string  `json:"volume_unit"`                             // 体积单位
+	Country                              string  `json:"country"`                                 // 国家
+	IsSmallAndLight                      string  `json:"is_small_and_light"`
+	EnrolledInSmallAndLight              string  `json:"enrolled_in_small_and_light"`
 }
 
 type FBALongTermStorageFeesQueryParams struct {
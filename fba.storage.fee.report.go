@@ -0,0 +1,188 @@
+package lingxing
+
+import (
+	"errors"
+	"github.com/hiscaler/lingxing/constant"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 月仓储费汇总
+// 月仓储费按 ASIN/FNSKU + 仓库编号统计，汇总时按指定维度合计预估仓储费和总体积，预估仓储费统一转换为报表币种
+// 不同国家的体积单位可能不同（立方英尺、立方米），跨国家汇总时总体积仅供参考
+
+// StorageFeeGroupBy 月仓储费汇总维度
+type StorageFeeGroupBy string
+
+const (
+	StorageFeeGroupBySID               StorageFeeGroupBy = "sid"                // 店铺
+	StorageFeeGroupByCountry           StorageFeeGroupBy = "country"            // 国家
+	StorageFeeGroupBySizeTier          StorageFeeGroupBy = "size_tier"          // 产品标准
+	StorageFeeGroupByFulfillmentCenter StorageFeeGroupBy = "fulfillment_center" // 仓库编号
+	StorageFeeGroupBySKU               StorageFeeGroupBy = "sku"                // 本地 SKU
+)
+
+// StorageFeeSummary 月仓储费汇总结果
+type StorageFeeSummary struct {
+	Key            string  `json:"key"`             // 汇总维度值（未配对本地 SKU 时为空）
+	Month          string  `json:"month"`           // 收费月份
+	Currency       string  `json:"currency"`        // 报表币种
+	Quantity       float64 `json:"quantity"`        // 库存量
+	Volume         float64 `json:"volume"`          // 总体积
+	Fee            float64 `json:"fee"`             // 预估仓储费
+	PreviousVolume float64 `json:"previous_volume"` // 上月总体积
+	PreviousFee    float64 `json:"previous_fee"`    // 上月预估仓储费
+	FeeChange      float64 `json:"fee_change"`      // 预估仓储费环比变化量
+	FeeChangeRate  float64 `json:"fee_change_rate"` // 预估仓储费环比变化率（上月为 0 时为 0）
+}
+
+// StorageFeeReport 月仓储费汇总报表，FNSKU 不区分大小写
+type StorageFeeReport struct {
+	currency  string
+	converter *CurrencyConverter
+	fees      []FBAMonthStorageFee
+	skus      map[string]string // 店铺 ID + FNSKU => 本地 SKU
+}
+
+// NewStorageFeeReport 创建月仓储费汇总报表，currency 为报表币种（为空时使用人民币），converter 用于币种转换，可以为 nil（仅在需要转换币种时使用）
+func NewStorageFeeReport(currency string, converter *CurrencyConverter) *StorageFeeReport {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = constant.CNY
+	}
+	return &StorageFeeReport{
+		currency:  currency,
+		converter: converter,
+		fees:      make([]FBAMonthStorageFee, 0),
+		skus:      make(map[string]string),
+	}
+}
+
+// AddListings 添加店铺的 Listing，用于通过 FNSKU 配对本地 SKU
+func (r *StorageFeeReport) AddListings(sid int, listings ...Listing) {
+	for _, listing := range listings {
+		if listing.FnSKU == "" || listing.LocalSKU == "" {
+			continue
+		}
+		r.skus[sidKey(sid, listing.FnSKU)] = listing.LocalSKU
+	}
+}
+
+// AddMonthStorageFees 添加月仓储费
+func (r *StorageFeeReport) AddMonthStorageFees(fees ...FBAMonthStorageFee) {
+	r.fees = append(r.fees, fees...)
+}
+
+func (r *StorageFeeReport) groupKey(groupBy StorageFeeGroupBy, fee FBAMonthStorageFee) string {
+	switch groupBy {
+	case StorageFeeGroupBySID:
+		return strconv.Itoa(fee.SID)
+	case StorageFeeGroupByCountry:
+		return strings.ToUpper(strings.TrimSpace(fee.CountryCode))
+	case StorageFeeGroupBySizeTier:
+		return strings.TrimSpace(fee.ProductSizeTier)
+	case StorageFeeGroupByFulfillmentCenter:
+		return strings.ToUpper(strings.TrimSpace(fee.FulfillmentCenter))
+	default:
+		return r.skus[sidKey(fee.SID, fee.FnSKU)]
+	}
+}
+
+// Summarize 按汇总维度汇总收费月份（Y-m）的月仓储费，并与上月对比（按预估仓储费降序排序）
+// 仅上月存在的汇总维度值同样返回，本月数据为 0
+func (r *StorageFeeReport) Summarize(groupBy StorageFeeGroupBy, month string) (summaries []StorageFeeSummary, err error) {
+	switch groupBy {
+	case StorageFeeGroupBySID, StorageFeeGroupByCountry, StorageFeeGroupBySizeTier, StorageFeeGroupByFulfillmentCenter, StorageFeeGroupBySKU:
+	default:
+		return nil, errors.New("lingxing: 无效的汇总维度 " + string(groupBy))
+	}
+	current, err := time.Parse("2006-01", rateMonth(month))
+	if err != nil {
+		return nil, errors.New("lingxing: 收费月份格式有误，正确的格式为：2006-01")
+	}
+	month = current.Format("2006-01")
+	previousMonth := current.AddDate(0, -1, 0).Format("2006-01")
+
+	groups := make(map[string]*StorageFeeSummary)
+	for _, fee := range r.fees {
+		m := rateMonth(fee.MonthOfCharge)
+		if m != month && m != previousMonth {
+			continue
+		}
+		amount := fee.EstimatedMonthlyStorageFee
+		if fee.Currency != "" && !strings.EqualFold(fee.Currency, r.currency) {
+			if r.converter == nil {
+				return nil, errors.New("lingxing: 缺少 " + fee.Currency + " 币种的汇率")
+			}
+			if amount, err = r.converter.Convert(amount, fee.Currency, r.currency, m); err != nil {
+				return nil, err
+			}
+		}
+
+		key := r.groupKey(groupBy, fee)
+		s, ok := groups[key]
+		if !ok {
+			s = &StorageFeeSummary{Key: key, Month: month, Currency: r.currency}
+			groups[key] = s
+		}
+		if m == month {
+			s.Quantity += fee.AverageQuantityOnHand
+			s.Volume += fee.EstimatedTotalItemVolume
+			s.Fee += amount
+		} else {
+			s.PreviousVolume += fee.EstimatedTotalItemVolume
+			s.PreviousFee += amount
+		}
+	}
+
+	summaries = make([]StorageFeeSummary, 0, len(groups))
+	for _, s := range groups {
+		s.FeeChange = s.Fee - s.PreviousFee
+		if s.PreviousFee != 0 {
+			s.FeeChangeRate = s.FeeChange / s.PreviousFee
+		}
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Fee != b.Fee {
+			return a.Fee > b.Fee
+		}
+		return a.Key < b.Key
+	})
+	return
+}
+
+// Load 从接口加载店铺的 Listing 以及收费月份（Y-m）和上月的月仓储费
+func (r *StorageFeeReport) Load(client *LingXing, sids []int, month string) error {
+	current, err := time.Parse("2006-01", month)
+	if err != nil {
+		return err
+	}
+	for _, sid := range sids {
+		listingParams := ListingsQueryParams{SID: sid}
+		listings, e := queryAll(func(offset int) ([]Listing, int, bool, error) {
+			listingParams.Offset = offset
+			return client.Services.Sale.Listing.All(listingParams)
+		})
+		if e != nil {
+			return e
+		}
+		r.AddListings(sid, listings...)
+
+		for _, m := range []string{current.AddDate(0, -1, 0).Format("2006-01"), current.Format("2006-01")} {
+			feeParams := FBAMonthStorageFeesQueryParams{SID: sid, Month: m}
+			fees, e := queryAll(func(offset int) ([]FBAMonthStorageFee, int, bool, error) {
+				feeParams.Offset = offset
+				return client.Services.FBA.StorageFee.Month(feeParams)
+			})
+			if e != nil {
+				return e
+			}
+			r.AddMonthStorageFees(fees...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStorageFeeReport_Summarize(t *testing.T) {
+	converter := NewCurrencyConverter(
+		Rate{Date: "2022-08", Code: "USD", RateOrg: 7},
+		Rate{Date: "2022-08", Code: "GBP", RateOrg: 8},
+	)
+	r := NewStorageFeeReport("CNY", converter)
+	r.AddListings(1,
+		Listing{FnSKU: "X1", LocalSKU: "A"},
+		Listing{FnSKU: "x2", LocalSKU: "B"},
+	)
+	r.AddMonthStorageFees(
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", CountryCode: "US", FulfillmentCenter: "PHX3", ProductSizeTier: "Standard", MonthOfCharge: "2022-09", Currency: "USD", AverageQuantityOnHand: 5, EstimatedTotalItemVolume: 2, EstimatedMonthlyStorageFee: 10},
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", CountryCode: "US", FulfillmentCenter: "ABE2", ProductSizeTier: "Standard", MonthOfCharge: "2022-09", Currency: "USD", AverageQuantityOnHand: 2, EstimatedTotalItemVolume: 1, EstimatedMonthlyStorageFee: 5},
+		FBAMonthStorageFee{SID: 1, FnSKU: "X2", CountryCode: "US", FulfillmentCenter: "PHX3", ProductSizeTier: "Oversize", MonthOfCharge: "2022-09", Currency: "USD", AverageQuantityOnHand: 1, EstimatedTotalItemVolume: 4, EstimatedMonthlyStorageFee: 20},
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", CountryCode: "US", FulfillmentCenter: "PHX3", ProductSizeTier: "Standard", MonthOfCharge: "2022-08", Currency: "USD", AverageQuantityOnHand: 5, EstimatedTotalItemVolume: 2, EstimatedMonthlyStorageFee: 10},
+		FBAMonthStorageFee{SID: 2, FnSKU: "X9", CountryCode: "UK", FulfillmentCenter: "LTN1", ProductSizeTier: "Standard", MonthOfCharge: "2022-08", Currency: "GBP", AverageQuantityOnHand: 3, EstimatedTotalItemVolume: 1, EstimatedMonthlyStorageFee: 8},
+		FBAMonthStorageFee{SID: 1, FnSKU: "X1", CountryCode: "US", FulfillmentCenter: "PHX3", ProductSizeTier: "Standard", MonthOfCharge: "2022-07", Currency: "USD", EstimatedMonthlyStorageFee: 100},
+	)
+
+	summaries, err := r.Summarize(StorageFeeGroupBySKU, "2022-09")
+	if assert.NoError(t, err) && assert.Len(t, summaries, 3) {
+		assert.Equal(t, "B", summaries[0].Key)
+		assert.InDelta(t, 140, summaries[0].Fee, 0.0001)
+		assert.Equal(t, 0.0, summaries[0].FeeChangeRate)
+
+		assert.Equal(t, "A", summaries[1].Key)
+		assert.Equal(t, "CNY", summaries[1].Currency)
+		assert.Equal(t, 7.0, summaries[1].Quantity)
+		assert.Equal(t, 3.0, summaries[1].Volume)
+		assert.InDelta(t, 105, summaries[1].Fee, 0.0001)
+		assert.Equal(t, 2.0, summaries[1].PreviousVolume)
+		assert.InDelta(t, 70, summaries[1].PreviousFee, 0.0001)
+		assert.InDelta(t, 35, summaries[1].FeeChange, 0.0001)
+		assert.InDelta(t, 0.5, summaries[1].FeeChangeRate, 0.0001)
+
+		// 未配对本地 SKU
+		assert.Equal(t, "", summaries[2].Key)
+		assert.Equal(t, 0.0, summaries[2].Fee)
+		assert.InDelta(t, 64, summaries[2].PreviousFee, 0.0001)
+	}
+
+	summaries, err = r.Summarize(StorageFeeGroupByFulfillmentCenter, "2022-09")
+	if assert.NoError(t, err) && assert.Len(t, summaries, 3) {
+		assert.Equal(t, "PHX3", summaries[0].Key)
+		assert.InDelta(t, 210, summaries[0].Fee, 0.0001)
+		assert.Equal(t, "ABE2", summaries[1].Key)
+		assert.Equal(t, "LTN1", summaries[2].Key)
+	}
+
+	_, err = r.Summarize("asin", "2022-09")
+	assert.Error(t, err)
+
+	_, err = NewStorageFeeReport("CNY", nil).Summarize(StorageFeeGroupBySID, "2022-09")
+	assert.NoError(t, err)
+
+	r = NewStorageFeeReport("CNY", nil)
+	r.AddMonthStorageFees(FBAMonthStorageFee{SID: 1, MonthOfCharge: "2022-09", Currency: "USD", EstimatedMonthlyStorageFee: 1})
+	_, err = r.Summarize(StorageFeeGroupBySID, "2022-09")
+	assert.Error(t, err)
+}
+
+func TestStorageFeeReport_DefaultCurrency(t *testing.T) {
+	r := NewStorageFeeReport(" ", NewCurrencyConverter(Rate{Date: "2022-09", Code: "USD", RateOrg: 7}))
+	r.AddMonthStorageFees(FBAMonthStorageFee{SID: 1, FnSKU: "X1", MonthOfCharge: "2022-09", Currency: "USD", EstimatedMonthlyStorageFee: 10})
+	summaries, err := r.Summarize(StorageFeeGroupBySID, "2022-09")
+	if assert.NoError(t, err) && assert.Len(t, summaries, 1) {
+		assert.Equal(t, "CNY", summaries[0].Currency)
+		assert.InDelta(t, 70, summaries[0].Fee, 0.0001)
+	}
+}
@@ -0,0 +1,32 @@
+package fba
+
+// ShipmentSheetStatus 发货单状态
+type ShipmentSheetStatus int
+
+const (
+	ShipmentSheetStatusPendingAllocation ShipmentSheetStatus = -1 // 待配货
+	ShipmentSheetStatusPendingShipment   ShipmentSheetStatus = 0  // 待发货
+	ShipmentSheetStatusShipped           ShipmentSheetStatus = 1  // 已发货
+	ShipmentSheetStatusCompleted         ShipmentSheetStatus = 2  // 已完成
+	ShipmentSheetStatusVoided            ShipmentSheetStatus = 3  // 已作废
+)
+
+var shipmentSheetStatusTexts = map[ShipmentSheetStatus]string{
+	ShipmentSheetStatusPendingAllocation: "待配货",
+	ShipmentSheetStatusPendingShipment:   "待发货",
+	ShipmentSheetStatusShipped:           "已发货",
+	ShipmentSheetStatusCompleted:         "已完成",
+	ShipmentSheetStatusVoided:            "已作废",
+}
+
+func (s ShipmentSheetStatus) String() string {
+	if text, ok := shipmentSheetStatusTexts[s]; ok {
+		return text
+	}
+	return "未知"
+}
+
+func (s ShipmentSheetStatus) IsValid() bool {
+	_, ok := shipmentSheetStatusTexts[s]
+	return ok
+}
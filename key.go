@@ -0,0 +1,16 @@
+package lingxing
+
+import (
+	"strconv"
+	"strings"
+)
+
+// skuKey SKU、MSKU、FNSKU 等编码的匹配键（去除首尾空格并转为大写），用于不区分大小写的匹配
+func skuKey(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// sidKey 店铺 ID + 编码（MSKU、FNSKU、ASIN 等）的匹配键，编码不区分大小写
+func sidKey(sid int, value string) string {
+	return strconv.Itoa(sid) + "\x00" + skuKey(value)
+}
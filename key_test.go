@@ -0,0 +1,21 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSkuKey(t *testing.T) {
+	assert.Equal(t, "ABC-1", skuKey(" abc-1 "))
+	assert.Equal(t, sidKey(1, "abc"), sidKey(1, " ABC"))
+	assert.NotEqual(t, sidKey(1, "abc"), sidKey(2, "abc"))
+}
+
+func TestAsinSales(t *testing.T) {
+	s := make(asinSales)
+	s.add(0, ProductReport{SID: 1, ASIN: "B01", Volume: 30})
+	s.add(30, ProductReport{SID: 1, ASIN: "B01", Volume: 300}, ProductReport{SID: 1, Volume: 30})
+	sales := s.split(sidKey(1, "b01"), sidKey(1, "B01"), sidKey(2, "B01"))
+	assert.Equal(t, 5.0, sales[sidKey(1, "B01")])
+	assert.Equal(t, 0.0, sales[sidKey(2, "B01")])
+}
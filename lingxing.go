@@ -328,14 +328,16 @@ func NewLingXing(cfg config.Config) *LingXing {
 		FBA: fbaService{
 			Shipment:   (fbaShipmentService)(xService),
 			StorageFee: (fbaStorageFeeService)(xService),
+			Inventory:  (fbaInventoryService)(xService),
 		},
 		Statistic: (statisticService)(xService),
 		Ad:        (adService)(xService),
 		Purchase:  (purchaseService)(xService),
 		Warehouse: (warehouseService)(xService),
 		MultiPlatform: multiPlatformService{
-			Seller: (multiPlatformSellerService)(xService),
-			Order:  (multiPlatformOrderService)(xService),
+			Seller:  (multiPlatformSellerService)(xService),
+			Order:   (multiPlatformOrderService)(xService),
+			Listing: (multiPlatformListingService)(xService),
 		},
 	}
 	return lingXingClient
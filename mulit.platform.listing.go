@@ -0,0 +1,191 @@
+package lingxing
+
+import (
+	"context"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/lingxing/constant"
+	jsoniter "github.com/json-iterator/go"
+	"strconv"
+)
+
+// 多平台 Listing
+
+type multiPlatformListingService service
+
+// 配对状态
+const (
+	MultiPlatformListingPaired   = 1 // 已配对
+	MultiPlatformListingUnpaired = 2 // 未配对
+)
+
+// MultiPlatformListing 多平台 Listing
+type MultiPlatformListing struct {
+	ID               int     `json:"id"`                 // ID
+	PlatformCode     string  `json:"platform_code"`      // 平台 code
+	PlatformName     string  `json:"platform_name"`      // 平台名称
+	StoreId          int     `json:"store_id"`           // 店铺 ID
+	StoreName        string  `json:"store_name"`         // 店铺名称
+	ListingId        string  `json:"listing_id"`         // 平台 Listing ID
+	ParentListingId  string  `json:"parent_listing_id"`  // 平台父 Listing ID
+	MSKU             string  `json:"msku"`               // 平台 SKU
+	Title            string  `json:"title"`              // 标题
+	PicURL           string  `json:"pic_url"`            // 图片链接
+	VariantAttr      string  `json:"variant_attr"`       // 变体属性
+	Price            float64 `json:"price"`              // 售价
+	Currency         string  `json:"currency"`           // 币种
+	Quantity         int     `json:"quantity"`           // 平台库存
+	Status           string  `json:"status"`             // 平台 Listing 状态
+	IsPair           bool    `json:"is_pair"`            // 是否配对（0：否、1：是）
+	LocalSKU         string  `json:"local_sku"`          // 配对的本地 SKU
+	LocalProductName string  `json:"local_product_name"` // 配对的本地品名
+	UpdateTime       int     `json:"update_time"`        // 更新时间
+}
+
+type MultiPlatformListingsQueryParams struct {
+	Paging
+	PlatformCode []string `json:"platform_code,omitempty"` // 平台 Code
+	StoreId      []string `json:"store_id,omitempty"`      // 店铺 ID
+	MSKU         []string `json:"msku,omitempty"`          // 平台 SKU
+	IsPair       int      `json:"is_pair,omitempty"`       // 是否配对（1：已配对、2：未配对）
+}
+
+func (m MultiPlatformListingsQueryParams) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.PlatformCode, validation.When(len(m.PlatformCode) > 0, validation.Each(validation.WithContext(func(ctx context.Context, value interface{}) error {
+			code, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("无效的平台代码: %v", value)
+			}
+			return validation.In(
+				constant.Shopify,
+				constant.Ebay,
+				constant.Wish,
+				constant.AliExpress,
+				constant.Shopee,
+				constant.Lazada,
+				constant.Walmart,
+				constant.CustomPlatform,
+				constant.Wayfair,
+				constant.TikTok,
+			).Error("无效的平台代码：" + code).Validate(code)
+		})))),
+		validation.Field(&m.IsPair, validation.In(MultiPlatformListingPaired, MultiPlatformListingUnpaired).Error("无效的配对状态")),
+	)
+}
+
+// All 查询多平台 Listing
+func (s multiPlatformListingService) All(params MultiPlatformListingsQueryParams) (items []MultiPlatformListing, nextOffset int, isLastPage bool, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	params.SetPagingVars()
+	res := struct {
+		NormalResponse
+		Data struct {
+			Current int                    `json:"current"` // 当前页数
+			List    []MultiPlatformListing `json:"list"`    // 详细列表
+			Total   int                    `json:"total"`   // 总条数
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(params).
+		Post("/pb/mp/listing/getPairList")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		items = res.Data.List
+		nextOffset = params.nextOffset
+		isLastPage = len(items) < params.Limit
+	}
+	return
+}
+
+// Seller 查询多平台店铺下的所有 Listing
+func (s multiPlatformListingService) Seller(seller MultiPlatformSeller) (items []MultiPlatformListing, err error) {
+	params := MultiPlatformListingsQueryParams{
+		PlatformCode: []string{seller.PlatformCode},
+		StoreId:      []string{strconv.Itoa(seller.StoreId)},
+	}
+	return queryAll(func(offset int) ([]MultiPlatformListing, int, bool, error) {
+		params.Offset = offset
+		return s.All(params)
+	})
+}
+
+// Unpaired 查询未配对的 Listing，并按照平台代码分组返回
+func (s multiPlatformListingService) Unpaired(platformCodes ...string) (items map[string][]MultiPlatformListing, err error) {
+	params := MultiPlatformListingsQueryParams{
+		PlatformCode: platformCodes,
+		IsPair:       MultiPlatformListingUnpaired,
+	}
+	listings, err := queryAll(func(offset int) ([]MultiPlatformListing, int, bool, error) {
+		params.Offset = offset
+		return s.All(params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	items = make(map[string][]MultiPlatformListing)
+	for _, listing := range listings {
+		items[listing.PlatformCode] = append(items[listing.PlatformCode], listing)
+	}
+	return
+}
+
+// 多平台 Listing 配对
+
+type MultiPlatformListingPair struct {
+	StoreId   string `json:"store_id"`    // 店铺 ID
+	MSKU      string `json:"msku"`        // 平台 SKU
+	SKU       string `json:"sku"`         // 本地 SKU
+	IsSyncPic bool   `json:"is_sync_pic"` // 是否同步 Listing 图片（0：否、1：是）
+}
+
+func (m MultiPlatformListingPair) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.StoreId, validation.Required.Error("店铺 ID 不能为空")),
+		validation.Field(&m.MSKU, validation.Required.Error("MSKU 不能为空")),
+		validation.Field(&m.SKU, validation.Required.Error("本地 SKU 不能为空")),
+	)
+}
+
+type MultiPlatformListingPairRequest struct {
+	Data []MultiPlatformListingPair `json:"data"`
+}
+
+func (m MultiPlatformListingPairRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Data, validation.Required.Error("配对数据不能为空")),
+	)
+}
+
+// Pair 多平台 Listing 与本地产品配对
+func (s multiPlatformListingService) Pair(req MultiPlatformListingPairRequest) (successfulCount, failedCount int, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			Success int `json:"success"`
+			Error   int `json:"error"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/pb/mp/listing/pair")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		successfulCount = res.Data.Success
+		failedCount = res.Data.Error
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/gox/jsonx"
+	"github.com/hiscaler/lingxing/constant"
+	"testing"
+)
+
+func Test_multiPlatformListingService_All(t *testing.T) {
+	params := MultiPlatformListingsQueryParams{
+		PlatformCode: []string{constant.Shopify},
+		IsPair:       MultiPlatformListingPaired,
+	}
+	params.Limit = 10
+	items, _, _, err := lingXingClient.Services.MultiPlatform.Listing.All(params)
+	if err != nil {
+		t.Errorf("Services.MultiPlatform.Listing.All() error: %s", err.Error())
+	} else {
+		t.Log(jsonx.ToPrettyJson(items))
+	}
+}
+
+func Test_multiPlatformListingService_Unpaired(t *testing.T) {
+	items, err := lingXingClient.Services.MultiPlatform.Listing.Unpaired(constant.Shopify, constant.Walmart)
+	if err != nil {
+		t.Errorf("Services.MultiPlatform.Listing.Unpaired() error: %s", err.Error())
+	} else {
+		for code, listings := range items {
+			t.Logf("%s: %d", code, len(listings))
+		}
+	}
+}
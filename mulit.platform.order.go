@@ -89,7 +89,7 @@ type MultiPlatformOrderLogistics struct {
 	Status                string  `json:"status"`                  // 状态（0：待物流下单、1：物流下单中、2：成功、3：失败、4：已取消）
 }
 
-// 	MultiPlatformOrderTag 标签+处理类型
+// MultiPlatformOrderTag 标签+处理类型
 type MultiPlatformOrderTag struct {
 	TagName string `json:"tag_name"` // 标签名称
 	TagNo   string `json:"tag_no"`   // 标签 NO
@@ -97,7 +97,7 @@ type MultiPlatformOrderTag struct {
 
 }
 
-// 	MultiPlatformOrderPlatformInfo 平台单信息
+// MultiPlatformOrderPlatformInfo 平台单信息
 type MultiPlatformOrderPlatformInfo struct {
 	CancelTime        int    `json:"cancel_time"`         // 取消单时间
 	DeliveryTime      int    `json:"delivery_time"`       // 平台发货时间
@@ -114,7 +114,7 @@ type MultiPlatformOrderPlatformInfo struct {
 	StoreCountryCode  string `json:"store_Country_code"`  // 订单国家(二位iso_3166_1)
 }
 
-// 	MultiPlatformOrderTransactionInfo 交易信息
+// MultiPlatformOrderTransactionInfo 交易信息
 type MultiPlatformOrderTransactionInfo struct {
 	CustomerShippingAmount float64 `json:"customer_shipping_amount"` // 客付运费（币种取 amount_currency）
 	CustomerTaxAmountShow  float64 `json:"customer_tax_amount_show"` // 客付税费（币种取 amount_currency）
@@ -146,7 +146,7 @@ type MultiPlatformOrder struct {
 	ItemInfo               []MultiPlatformOrderItem          `json:"item_info"`                // 商品信息
 	LogisticsInfo          []MultiPlatformOrderLogistics     `json:"logistics_info"`           // 物流信息
 	OrderFromName          string                            `json:"order_from_name"`          // 订单来源
-	OrderStatus            string                            `json:"order_status"`             // 系统订单状态（1：同步中、2：已同步、3：未付款、4：待审核、5：待发货、6：已发货、7：已取消、8：不显示、9：平台发货）
+	OrderStatus            MultiPlatformOrderStatus          `json:"order_status"`             // 系统订单状态（1：同步中、2：已同步、3：未付款、4：待审核、5：待发货、6：已发货、7：已取消、8：不显示、9：平台发货）
 	OrderTag               MultiPlatformOrderTag             `json:"order_tag"`                // 标签+处理类型
 	OriginalGlobalOrderNo  string                            `json:"original_global_order_no"` // 补发订单原系统单号
 	PlatformInfo           MultiPlatformOrderPlatformInfo    `json:"platform_info"`            // 平台单信息
@@ -160,13 +160,25 @@ type MultiPlatformOrder struct {
 	Wid                    string                            `json:"wid"`                      // 仓库ID
 }
 
+// 拆分单类型
+const (
+	MultiPlatformOrderSplitTypeOriginal = "1" // 原始单
+	MultiPlatformOrderSplitTypeMerged   = "2" // 合并单
+	MultiPlatformOrderSplitTypeSplit    = "3" // 拆分单
+)
+
 type MultiPlatformOrdersQueryParams struct {
 	Paging
-	DateType     string   `json:"date_type,omitempty"`     // 时间类型（更新时间：update_time、订购时间：global_purchase_time、发货时间：global_delivery_time）
-	PlatformCode []string `json:"platform_code,omitempty"` // 平台 Code
-	StartTime    string   `json:"start_time"`              // 开始时间（Y-m-d H:i:s 格式）
-	EndTime      string   `json:"end_time"`                // 结束时间（Y-m-d H:i:s 格式）
-	StoreId      []string `json:"store_id,omitempty"`      // 店铺 ID
+	DateType         string                   `json:"date_type,omitempty"`         // 时间类型（更新时间：update_time、订购时间：global_purchase_time、发货时间：global_delivery_time）
+	PlatformCode     []string                 `json:"platform_code,omitempty"`     // 平台 Code
+	StartTime        string                   `json:"start_time"`                  // 开始时间（Y-m-d H:i:s 格式）
+	EndTime          string                   `json:"end_time"`                    // 结束时间（Y-m-d H:i:s 格式）
+	StoreId          []string                 `json:"store_id,omitempty"`          // 店铺 ID
+	OrderStatus      MultiPlatformOrderStatus `json:"order_status,omitempty"`      // 系统订单状态
+	GlobalOrderNos   []string                 `json:"global_order_no,omitempty"`   // 系统单号
+	PlatformOrderNos []string                 `json:"platform_order_no,omitempty"` // 平台单号
+	WIDs             []string                 `json:"wid,omitempty"`               // 仓库 ID
+	SplitType        string                   `json:"split_type,omitempty"`        // 拆分单类型（1：原始单、2：合并单、3：拆分单）
 }
 
 func (m MultiPlatformOrdersQueryParams) Validate() error {
@@ -190,6 +202,22 @@ func (m MultiPlatformOrdersQueryParams) Validate() error {
 				constant.TikTok,
 			).Error("无效的平台代码：" + code).Validate(code)
 		})))),
+		validation.Field(&m.OrderStatus, validation.In(
+			MultiPlatformOrderStatusSyncing,
+			MultiPlatformOrderStatusSynced,
+			MultiPlatformOrderStatusUnpaid,
+			MultiPlatformOrderStatusPendingReview,
+			MultiPlatformOrderStatusPendingShipment,
+			MultiPlatformOrderStatusShipped,
+			MultiPlatformOrderStatusCanceled,
+			MultiPlatformOrderStatusHidden,
+			MultiPlatformOrderStatusPlatformShipment,
+		).Error("无效的订单状态")),
+		validation.Field(&m.SplitType, validation.When(m.SplitType != "", validation.In(
+			MultiPlatformOrderSplitTypeOriginal,
+			MultiPlatformOrderSplitTypeMerged,
+			MultiPlatformOrderSplitTypeSplit,
+		).Error("无效的拆分单类型"))),
 		validation.Field(&m.StartTime, validation.Required.Error("请输入开始时间"),
 			validation.Date(constant.DatetimeFormat).Error("无效的开始时间"),
 		),
@@ -245,3 +273,120 @@ func (s multiPlatformOrderService) All(params MultiPlatformOrdersQueryParams) (i
 	}
 	return
 }
+
+// One 查询多平台订单详情
+func (s multiPlatformOrderService) One(globalOrderNo string) (item MultiPlatformOrder, err error) {
+	res := struct {
+		NormalResponse
+		Data MultiPlatformOrder `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(map[string]string{"global_order_no": globalOrderNo}).
+		Post("/pb/mp/order/detail")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		if res.Data.GlobalOrderNo == "" {
+			err = ErrNotFound
+		} else {
+			item = res.Data
+		}
+	}
+	return
+}
+
+// 更新订单备注
+
+type MultiPlatformOrderRemarkRequest struct {
+	GlobalOrderNo string `json:"global_order_no"` // 系统单号
+	Remark        string `json:"remark"`          // 备注
+}
+
+func (m MultiPlatformOrderRemarkRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.GlobalOrderNo, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.Remark, validation.Length(0, 500).Error("备注不能超过 {{.max}} 个字符")),
+	)
+}
+
+// UpdateRemark 更新多平台订单备注
+func (s multiPlatformOrderService) UpdateRemark(req MultiPlatformOrderRemarkRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/pb/mp/order/updateRemark")
+	return
+}
+
+// 订单标签
+
+// 标签操作类型
+const (
+	MultiPlatformOrderTagOperationAdd    = 1 // 添加
+	MultiPlatformOrderTagOperationRemove = 2 // 移除
+)
+
+type MultiPlatformOrderTagsRequest struct {
+	GlobalOrderNos []string `json:"global_order_no"` // 系统单号
+	TagNos         []string `json:"tag_no"`          // 标签 NO（对应 MultiPlatformOrderTag.TagNo）
+	Operation      int      `json:"operation"`       // 操作类型（1：添加、2：移除）
+}
+
+func (m MultiPlatformOrderTagsRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.GlobalOrderNos, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.TagNos, validation.Required.Error("标签不能为空")),
+		validation.Field(&m.Operation,
+			validation.Required.Error("操作类型不能为空"),
+			validation.In(MultiPlatformOrderTagOperationAdd, MultiPlatformOrderTagOperationRemove).Error("无效的操作类型"),
+		),
+	)
+}
+
+// UpdateTags 添加、移除多平台订单标签
+func (s multiPlatformOrderService) UpdateTags(req MultiPlatformOrderTagsRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/pb/mp/order/updateTags")
+	return
+}
+
+// 订单物流信息
+
+type MultiPlatformOrderLogisticsRequest struct {
+	GlobalOrderNo       string `json:"global_order_no"`                 // 系统单号
+	WID                 string `json:"wid,omitempty"`                   // 发货仓库 ID
+	LogisticsProviderId int    `json:"logistics_provider_id,omitempty"` // 物流商 ID
+	LogisticsTypeId     int    `json:"logistics_type_id,omitempty"`     // 物流方式 ID
+	TrackingNumber      string `json:"tracking_number,omitempty"`       // 跟踪号
+	ActualCarrier       string `json:"actual_carrier,omitempty"`        // 实际承运人
+}
+
+func (m MultiPlatformOrderLogisticsRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.GlobalOrderNo, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.LogisticsTypeId, validation.When(m.LogisticsProviderId != 0, validation.Required.Error("物流方式 ID 不能为空"))),
+		validation.Field(&m.TrackingNumber, validation.When(m.LogisticsProviderId == 0 && m.WID == "", validation.Required.Error("跟踪号不能为空"))),
+	)
+}
+
+// UpdateLogistics 更新多平台订单物流信息（发货仓库、物流商、物流方式、跟踪号）
+func (s multiPlatformOrderService) UpdateLogistics(req MultiPlatformOrderLogisticsRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/pb/mp/order/updateLogistics")
+	return
+}
@@ -0,0 +1,43 @@
+package lingxing
+
+import (
+	"errors"
+	"github.com/hiscaler/gox/jsonx"
+	"github.com/hiscaler/lingxing/constant"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMultiPlatformOrdersQueryParams_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   MultiPlatformOrdersQueryParams
+		hasError bool
+	}{
+		{"t0", MultiPlatformOrdersQueryParams{}, true},
+		{"t1", MultiPlatformOrdersQueryParams{StartTime: "2022-01-01 00:00:00", EndTime: "2022-01-02 00:00:00"}, false},
+		{"t2", MultiPlatformOrdersQueryParams{StartTime: "2022-01-01 00:00:00", EndTime: "2022-01-02 00:00:00", OrderStatus: 100}, true},
+		{"t3", MultiPlatformOrdersQueryParams{StartTime: "2022-01-01 00:00:00", EndTime: "2022-01-02 00:00:00", SplitType: "4"}, true},
+		{"t4", MultiPlatformOrdersQueryParams{
+			StartTime:        "2022-01-01 00:00:00",
+			EndTime:          "2022-01-02 00:00:00",
+			PlatformCode:     []string{constant.Shopify},
+			OrderStatus:      MultiPlatformOrderStatusPendingShipment,
+			PlatformOrderNos: []string{"1001"},
+			SplitType:        MultiPlatformOrderSplitTypeOriginal,
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			assert.Equalf(t, tt.hasError, err != nil, "Validate(%s) error", jsonx.ToJson(tt.params, "{}"))
+		})
+	}
+}
+
+func Test_multiPlatformOrderService_OneNotFound(t *testing.T) {
+	_, err := lingXingClient.Services.MultiPlatform.Order.One("")
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Services.MultiPlatform.Order.One() error is not ErrNotFound type")
+	}
+}
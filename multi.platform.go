@@ -2,6 +2,7 @@ package lingxing
 
 // 多平台
 type multiPlatformService struct {
-	Seller multiPlatformSellerService
-	Order  multiPlatformOrderService
+	Seller  multiPlatformSellerService
+	Order   multiPlatformOrderService
+	Listing multiPlatformListingService
 }
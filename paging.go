@@ -25,3 +25,20 @@ func toValues(i interface{}) (values url.Values) {
 	values, _ = query.Values(i)
 	return
 }
+
+// queryAll 依次查询所有分页数据，fn 的参数为本次查询的分页偏移索引
+func queryAll[T any](fn func(offset int) (items []T, nextOffset int, isLastPage bool, err error)) ([]T, error) {
+	all := make([]T, 0)
+	offset := 0
+	for {
+		items, nextOffset, isLastPage, err := fn(offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if isLastPage {
+			return all, nil
+		}
+		offset = nextOffset
+	}
+}
@@ -0,0 +1,23 @@
+package lingxing
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQueryAll(t *testing.T) {
+	offsets := make([]int, 0)
+	items, err := queryAll(func(offset int) ([]int, int, bool, error) {
+		offsets = append(offsets, offset)
+		return []int{offset}, offset + 2, offset >= 4, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2, 4}, items)
+	assert.Equal(t, []int{0, 2, 4}, offsets)
+
+	_, err = queryAll(func(offset int) ([]int, int, bool, error) {
+		return nil, 0, false, errors.New("error")
+	})
+	assert.NotNil(t, err)
+}
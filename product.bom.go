@@ -0,0 +1,363 @@
+package lingxing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 物料清单（BOM）
+// 基于捆绑产品关系和辅料关联关系构建产品组成结构，用于需求展开、成本汇总和循环引用检测
+// 捆绑产品展开至最底层的子产品，辅料按照关联产品的数量计算用量，SKU 不区分大小写
+
+// BOMComponent 物料清单组件
+type BOMComponent struct {
+	SKU           string  `json:"sku"`             // SKU
+	ProductName   string  `json:"product_name"`    // 品名
+	IsAuxMaterial bool    `json:"is_aux_material"` // 是否为辅料
+	Quantity      int     `json:"quantity"`        // 数量
+	CgPrice       float64 `json:"cg_price"`        // 采购单价（RMB）
+	Amount        float64 `json:"amount"`          // 采购金额（RMB）
+}
+
+type bomEdge struct {
+	key           string // 子项键
+	quantity      int    // 数量
+	isAuxMaterial bool   // 是否为辅料
+}
+
+type bomNode struct {
+	sku           string
+	productName   string
+	cgPrice       float64
+	isAuxMaterial bool
+	children      []bomEdge
+}
+
+// BOM 物料清单
+type BOM struct {
+	nodes map[string]*bomNode
+}
+
+func NewBOM() *BOM {
+	return &BOM{nodes: make(map[string]*bomNode)}
+}
+
+func (b *BOM) node(sku string) *bomNode {
+	key := skuKey(sku)
+	n, ok := b.nodes[key]
+	if !ok {
+		n = &bomNode{sku: sku, children: make([]bomEdge, 0)}
+		b.nodes[key] = n
+	}
+	return n
+}
+
+// addChild 添加子项，相同子项重复添加时覆盖数量
+func (n *bomNode) addChild(edge bomEdge) {
+	for i, child := range n.children {
+		if child.key == edge.key && child.isAuxMaterial == edge.isAuxMaterial {
+			n.children[i].quantity = edge.quantity
+			return
+		}
+	}
+	n.children = append(n.children, edge)
+}
+
+// AddProducts 添加本地产品，用于获取产品的品名和采购单价
+func (b *BOM) AddProducts(products ...Product) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		n := b.node(product.SKU)
+		n.productName = product.ProductName
+		if price, err := strconv.ParseFloat(strings.TrimSpace(product.CgPrice), 64); err == nil {
+			n.cgPrice = price
+		}
+	}
+}
+
+// AddBundledProducts 添加捆绑产品
+func (b *BOM) AddBundledProducts(products ...BundledProduct) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		n := b.node(product.SKU)
+		if n.productName == "" {
+			n.productName = product.ProductName
+		}
+		for _, item := range product.BundledProducts {
+			if item.SKU == "" {
+				continue
+			}
+			b.node(item.SKU)
+			n.addChild(bomEdge{key: skuKey(item.SKU), quantity: item.Quantity})
+		}
+	}
+}
+
+// AddAuxMaterials 添加辅料
+func (b *BOM) AddAuxMaterials(materials ...ProductAuxMaterial) {
+	for _, material := range materials {
+		if material.SKU == "" {
+			continue
+		}
+		n := b.node(material.SKU)
+		n.productName = material.ProductName
+		n.cgPrice = material.CgPrice
+		n.isAuxMaterial = true
+		for _, product := range material.AuxRelationProduct {
+			if product.SKU == "" {
+				continue
+			}
+			parent := b.node(product.SKU)
+			if parent.productName == "" {
+				parent.productName = product.ProductName
+			}
+			parent.addChild(bomEdge{key: skuKey(material.SKU), quantity: product.Quantity, isAuxMaterial: true})
+		}
+	}
+}
+
+// Components 直接组成（不展开）
+func (b *BOM) Components(sku string) []BOMComponent {
+	components := make([]BOMComponent, 0)
+	n, ok := b.nodes[skuKey(sku)]
+	if !ok {
+		return components
+	}
+	for _, child := range n.children {
+		c := b.nodes[child.key]
+		components = append(components, BOMComponent{
+			SKU:           c.sku,
+			ProductName:   c.productName,
+			IsAuxMaterial: child.isAuxMaterial,
+			Quantity:      child.quantity,
+			CgPrice:       c.cgPrice,
+			Amount:        c.cgPrice * float64(child.quantity),
+		})
+	}
+	return components
+}
+
+// WhereUsed 使用了 sku 的上级产品 SKU（不展开）
+func (b *BOM) WhereUsed(sku string) []string {
+	key := skuKey(sku)
+	skus := make([]string, 0)
+	for _, n := range b.nodes {
+		for _, child := range n.children {
+			if child.key == key {
+				skus = append(skus, n.sku)
+				break
+			}
+		}
+	}
+	sort.Strings(skus)
+	return skus
+}
+
+// explode 递归展开需求，path 用于检测循环引用
+func (b *BOM) explode(key string, quantity int, isAuxMaterial bool, path []string, demands map[string]*BOMComponent) error {
+	for _, p := range path {
+		if p == key {
+			return fmt.Errorf("lingxing: BOM 存在循环引用 %s", strings.Join(append(path, key), " -> "))
+		}
+	}
+
+	n := b.nodes[key]
+	hasComponents := false
+	for _, child := range n.children {
+		if !child.isAuxMaterial {
+			hasComponents = true
+			break
+		}
+	}
+	if !hasComponents || isAuxMaterial {
+		d, ok := demands[key]
+		if !ok {
+			d = &BOMComponent{
+				SKU:           n.sku,
+				ProductName:   n.productName,
+				IsAuxMaterial: isAuxMaterial || n.isAuxMaterial,
+				CgPrice:       n.cgPrice,
+			}
+			demands[key] = d
+		}
+		d.Quantity += quantity
+		d.Amount = d.CgPrice * float64(d.Quantity)
+	}
+	if isAuxMaterial {
+		// 辅料不再展开
+		return nil
+	}
+
+	path = append(path, key)
+	for _, child := range n.children {
+		if child.quantity <= 0 {
+			return fmt.Errorf("lingxing: %s 的组成 %s 数量 %d 无效", n.sku, b.nodes[child.key].sku, child.quantity)
+		}
+		if err := b.explode(child.key, quantity*child.quantity, child.isAuxMaterial, path, demands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Explode 展开 sku 的需求数量，返回最底层的产品和所有辅料的需求（按 SKU 升序）
+// 不存在组成关系的 SKU 返回其自身
+func (b *BOM) Explode(sku string, quantity int) (components []BOMComponent, err error) {
+	return b.ExplodeAll(map[string]int{sku: quantity})
+}
+
+// ExplodeAll 展开多个 SKU 的需求数量（SKU => 需求数量）并合并，返回最底层的产品和所有辅料的需求（按 SKU 升序）
+// 不存在组成关系的 SKU 返回其自身，需求数量必须大于 0
+func (b *BOM) ExplodeAll(demands map[string]int) (components []BOMComponent, err error) {
+	skus := make([]string, 0, len(demands))
+	for sku := range demands {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+	values := make(map[string]*BOMComponent)
+	for _, sku := range skus {
+		quantity := demands[sku]
+		if quantity <= 0 {
+			return nil, fmt.Errorf("lingxing: %s 的需求数量 %d 无效", sku, quantity)
+		}
+		key := skuKey(sku)
+		if _, ok := b.nodes[key]; !ok {
+			// 不在物料清单中的 SKU 不写入物料清单
+			d, ok := values[key]
+			if !ok {
+				d = &BOMComponent{SKU: sku}
+				values[key] = d
+			}
+			d.Quantity += quantity
+			continue
+		}
+		if err = b.explode(key, quantity, false, make([]string, 0), values); err != nil {
+			return nil, err
+		}
+	}
+	components = make([]BOMComponent, 0, len(values))
+	for _, d := range values {
+		components = append(components, *d)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].SKU < components[j].SKU
+	})
+	return
+}
+
+// Cost 汇总 sku 的采购成本（RMB），即展开后所有子产品和辅料的采购金额之和
+func (b *BOM) Cost(sku string) (cost float64, err error) {
+	components, err := b.Explode(sku, 1)
+	if err != nil {
+		return
+	}
+	for _, c := range components {
+		cost += c.Amount
+	}
+	return
+}
+
+// Validate 检测所有产品是否存在循环引用和无效的组成数量，每个节点仅检测一次
+func (b *BOM) Validate() error {
+	const (
+		visiting = 1 // 检测中
+		visited  = 2 // 已检测
+	)
+	states := make(map[string]int, len(b.nodes))
+	var visit func(key string, path []string) error
+	visit = func(key string, path []string) error {
+		switch states[key] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("lingxing: BOM 存在循环引用 %s", strings.Join(append(path, key), " -> "))
+		}
+		states[key] = visiting
+		path = append(path, key)
+		n := b.nodes[key]
+		for _, child := range n.children {
+			if child.quantity <= 0 {
+				return fmt.Errorf("lingxing: %s 的组成 %s 数量 %d 无效", n.sku, b.nodes[child.key].sku, child.quantity)
+			}
+			if child.isAuxMaterial {
+				// 辅料不再展开，仅检测是否引用了上级产品
+				if states[child.key] == visiting {
+					return fmt.Errorf("lingxing: BOM 存在循环引用 %s", strings.Join(append(path, child.key), " -> "))
+				}
+				continue
+			}
+			if err := visit(child.key, path); err != nil {
+				return err
+			}
+		}
+		states[key] = visited
+		return nil
+	}
+
+	keys := make([]string, 0, len(b.nodes))
+	for key := range b.nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := visit(key, make([]string, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurchasePlanItems 将产品需求（SKU => 需求数量）展开为采购计划产品，捆绑产品展开为子产品和辅料的采购量
+func (b *BOM) PurchasePlanItems(wid int, demands map[string]int) (items []CreatePurchasePlanItem, err error) {
+	components, err := b.ExplodeAll(demands)
+	if err != nil {
+		return
+	}
+	items = make([]CreatePurchasePlanItem, len(components))
+	for i, c := range components {
+		items[i] = CreatePurchasePlanItem{SKU: c.SKU, WID: wid, QuantityPlan: c.Quantity}
+	}
+	return
+}
+
+// BOM 从接口加载本地产品、捆绑产品和辅料数据并构建物料清单
+func (s productService) BOM() (*BOM, error) {
+	productParams := ProductsQueryParams{}
+	products, err := queryAll(func(offset int) ([]Product, int, bool, error) {
+		productParams.Offset = offset
+		return s.productProductService.All(productParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bundledParams := BundledProductsQueryParams{}
+	bundledProducts, err := queryAll(func(offset int) ([]BundledProduct, int, bool, error) {
+		bundledParams.Offset = offset
+		return s.Bundle.All(bundledParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	auxParams := ProductAuxMaterialsQueryParams{}
+	materials, err := queryAll(func(offset int) ([]ProductAuxMaterial, int, bool, error) {
+		auxParams.Offset = offset
+		return s.AuxMaterial.All(auxParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bom := NewBOM()
+	bom.AddProducts(products...)
+	bom.AddBundledProducts(bundledProducts...)
+	bom.AddAuxMaterials(materials...)
+	return bom, bom.Validate()
+}
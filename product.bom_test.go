@@ -0,0 +1,84 @@
+package lingxing
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBOM(t *testing.T) {
+	bom := NewBOM()
+	bom.AddProducts(
+		Product{SKU: "A", ProductName: "A", CgPrice: "10"},
+		Product{SKU: "B", ProductName: "B", CgPrice: "5.5"},
+	)
+	bom.AddBundledProducts(
+		BundledProduct{SKU: "SET", BundledProducts: []BundledProductItem{{SKU: "A", Quantity: 2}, {SKU: "KIT", Quantity: 1}}},
+		BundledProduct{SKU: "KIT", BundledProducts: []BundledProductItem{{SKU: "b", Quantity: 3}}},
+	)
+	bom.AddAuxMaterials(
+		ProductAuxMaterial{SKU: "BOX", CgPrice: 1, AuxRelationProduct: []ProductAuxMaterialRelationProduct{{SKU: "SET", Quantity: 1}, {SKU: "A", Quantity: 1}}},
+	)
+
+	components, err := bom.Explode("set", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []BOMComponent{
+		{SKU: "A", ProductName: "A", Quantity: 4, CgPrice: 10, Amount: 40},
+		{SKU: "B", ProductName: "B", Quantity: 6, CgPrice: 5.5, Amount: 33},
+		{SKU: "BOX", IsAuxMaterial: true, Quantity: 6, CgPrice: 1, Amount: 6},
+	}, components)
+
+	cost, err := bom.Cost("SET")
+	assert.Nil(t, err)
+	assert.Equal(t, 39.5, cost)
+
+	components, err = bom.Explode("UNKNOWN", 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []BOMComponent{{SKU: "UNKNOWN", Quantity: 3}}, components)
+	_, exists := bom.nodes[skuKey("UNKNOWN")]
+	assert.False(t, exists, "Explode should not change BOM")
+	_, err = bom.Explode("SET", 0)
+	assert.NotNil(t, err)
+
+	items, err := bom.PurchasePlanItems(1, map[string]int{"SET": 1, "A": 1, "UNKNOWN": 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []CreatePurchasePlanItem{
+		{SKU: "A", WID: 1, QuantityPlan: 3},
+		{SKU: "B", WID: 1, QuantityPlan: 3},
+		{SKU: "BOX", WID: 1, QuantityPlan: 4},
+		{SKU: "UNKNOWN", WID: 1, QuantityPlan: 2},
+	}, items)
+
+	assert.Len(t, bom.Components("SET"), 3)
+	assert.Equal(t, []string{"A", "SET"}, bom.WhereUsed("BOX"))
+	assert.Nil(t, bom.Validate())
+
+	bom.AddBundledProducts(BundledProduct{SKU: "B", BundledProducts: []BundledProductItem{{SKU: "SET", Quantity: 1}}})
+	assert.NotNil(t, bom.Validate())
+	_, err = bom.Explode("SET", 1)
+	assert.NotNil(t, err)
+}
+
+func TestBOM_ValidateQuantity(t *testing.T) {
+	bom := NewBOM()
+	bom.AddBundledProducts(BundledProduct{SKU: "SET", BundledProducts: []BundledProductItem{{SKU: "A", Quantity: 0}}})
+	assert.NotNil(t, bom.Validate())
+	_, err := bom.Explode("SET", 1)
+	assert.NotNil(t, err)
+}
+
+func TestBOM_ValidateSharedComponents(t *testing.T) {
+	// 每层的两个产品都引用下一层的两个产品，不使用缓存时需要检测 2^50 条路径
+	bom := NewBOM()
+	for i := 0; i < 50; i++ {
+		items := []BundledProductItem{
+			{SKU: fmt.Sprintf("L%d-A", i+1), Quantity: 1},
+			{SKU: fmt.Sprintf("L%d-B", i+1), Quantity: 1},
+		}
+		bom.AddBundledProducts(
+			BundledProduct{SKU: fmt.Sprintf("L%d-A", i), BundledProducts: items},
+			BundledProduct{SKU: fmt.Sprintf("L%d-B", i), BundledProducts: items},
+		)
+	}
+	assert.Nil(t, bom.Validate())
+}
@@ -1,7 +1,12 @@
 package lingxing
 
 import (
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	jsoniter "github.com/json-iterator/go"
+	"sort"
+	"strings"
 )
 
 // 捆绑产品
@@ -59,3 +64,274 @@ func (s productBundledService) All(params BundledProductsQueryParams) (items []B
 	}
 	return
 }
+
+// 添加、编辑捆绑产品
+// https://openapidoc.lingxing.com/#/docs/Product/SetBundled
+
+// UpsertBundledProductItem 捆绑产品子产品
+type UpsertBundledProductItem struct {
+	SKU      string `json:"sku"`        // 子产品 SKU
+	Quantity int    `json:"bundledQty"` // 捆绑数量
+}
+
+func (m UpsertBundledProductItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("子产品 SKU 不能为空")),
+		validation.Field(&m.Quantity,
+			validation.Required.Error("捆绑数量不能为空"),
+			validation.Min(1).Error("捆绑数量不能小于 {{.threshold}}"),
+		),
+	)
+}
+
+type UpsertBundledProductRequest struct {
+	SKU             string                     `json:"sku"`          // 捆绑产品 SKU
+	ProductName     string                     `json:"product_name"` // 捆绑产品名
+	BundledProducts []UpsertBundledProductItem `json:"group_list"`   // 子产品
+}
+
+func (m UpsertBundledProductRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("捆绑产品 SKU 不能为空")),
+		validation.Field(&m.ProductName, validation.Required.Error("捆绑产品名不能为空")),
+		validation.Field(&m.BundledProducts,
+			validation.Required.Error("子产品不能为空"),
+			validation.By(func(value interface{}) error {
+				items, _ := value.([]UpsertBundledProductItem)
+				skus := make(map[string]struct{}, len(items))
+				for _, item := range items {
+					sku := strings.ToUpper(item.SKU)
+					if sku == strings.ToUpper(m.SKU) {
+						return fmt.Errorf("子产品 %s 不能为捆绑产品自身", item.SKU)
+					}
+					if _, ok := skus[sku]; ok {
+						return fmt.Errorf("子产品 %s 重复", item.SKU)
+					}
+					skus[sku] = struct{}{}
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// Upsert 添加、编辑捆绑产品（SKU 存在时编辑，否则添加）
+// 子产品必须为已存在的本地产品，且不能为捆绑产品
+func (s productBundledService) Upsert(req UpsertBundledProductRequest) (id int, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	bundles, err := s.bundledProducts()
+	if err != nil {
+		return
+	}
+	if err = s.checkComponents([]UpsertBundledProductRequest{req}, bundles, false); err != nil {
+		return
+	}
+	return s.upsert(req)
+}
+
+// upsert 添加、编辑捆绑产品，不检查子产品
+func (s productBundledService) upsert(req UpsertBundledProductRequest) (id int, err error) {
+	res := struct {
+		NormalResponse
+		Data struct {
+			ProductId int `json:"product_id"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/storage/product/setBundled")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		id = res.Data.ProductId
+	}
+	return
+}
+
+// 删除捆绑产品
+
+// Delete 删除捆绑产品
+func (s productBundledService) Delete(skus ...string) (err error) {
+	if len(skus) == 0 {
+		return errors.New("捆绑产品 SKU 不能为空")
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(map[string][]string{"sku_list": skus}).
+		Post("/routing/storage/product/delBundled")
+	return
+}
+
+// 同步捆绑产品
+
+type BundledProductSyncOptions struct {
+	DeleteMissing      bool // 是否删除不在同步列表中的捆绑产品
+	AllowNestedBundles bool // 是否允许子产品为捆绑产品
+}
+
+// BundledProductSyncResult 同步结果（捆绑产品 SKU）
+type BundledProductSyncResult struct {
+	Created   []string `json:"created"`   // 添加
+	Updated   []string `json:"updated"`   // 编辑
+	Deleted   []string `json:"deleted"`   // 删除
+	Unchanged []string `json:"unchanged"` // 未变化
+}
+
+// bundledProductEqual 比较捆绑产品与期望的定义是否一致（SKU 不区分大小写，忽略子产品顺序）
+func bundledProductEqual(product BundledProduct, req UpsertBundledProductRequest) bool {
+	if req.ProductName != product.ProductName || len(req.BundledProducts) != len(product.BundledProducts) {
+		return false
+	}
+	quantities := make(map[string]int, len(product.BundledProducts))
+	for _, item := range product.BundledProducts {
+		quantities[strings.ToUpper(item.SKU)] = item.Quantity
+	}
+	for _, item := range req.BundledProducts {
+		if qty, ok := quantities[strings.ToUpper(item.SKU)]; !ok || qty != item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// bundledProducts 查询所有捆绑产品，以大写的 SKU 为键
+func (s productBundledService) bundledProducts() (map[string]BundledProduct, error) {
+	params := BundledProductsQueryParams{}
+	items, err := queryAll(func(offset int) ([]BundledProduct, int, bool, error) {
+		params.Offset = offset
+		return s.All(params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[string]BundledProduct, len(items))
+	for _, item := range items {
+		products[strings.ToUpper(item.SKU)] = item
+	}
+	return products, nil
+}
+
+// checkComponents 检查子产品是否存在，以及是否为捆绑产品
+func (s productBundledService) checkComponents(reqs []UpsertBundledProductRequest, bundles map[string]BundledProduct, allowNestedBundles bool) error {
+	skus := make(map[string]string)
+	for _, req := range reqs {
+		for _, item := range req.BundledProducts {
+			key := strings.ToUpper(item.SKU)
+			if _, ok := bundles[key]; ok {
+				if !allowNestedBundles {
+					return fmt.Errorf("捆绑产品 %s 的子产品 %s 不能为捆绑产品", req.SKU, item.SKU)
+				}
+				continue
+			}
+			skus[key] = item.SKU
+		}
+	}
+	if len(skus) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		values = append(values, sku)
+	}
+	sort.Strings(values)
+	products, err := productProductService(s).BySKUs(values...)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		delete(skus, strings.ToUpper(product.SKU))
+	}
+	if len(skus) > 0 {
+		missing := make([]string, 0, len(skus))
+		for _, sku := range skus {
+			missing = append(missing, sku)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("子产品 %s 不存在", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Sync 将捆绑产品同步为 reqs 中定义的状态
+// 仅添加不存在的捆绑产品和编辑有变化的捆绑产品，多次执行结果一致
+// 为避免误删所有捆绑产品，删除不在同步列表中的捆绑产品时同步列表不能为空
+func (s productBundledService) Sync(reqs []UpsertBundledProductRequest, options BundledProductSyncOptions) (result BundledProductSyncResult, err error) {
+	if options.DeleteMissing && len(reqs) == 0 {
+		err = errors.New("删除不在同步列表中的捆绑产品时，同步列表不能为空")
+		return
+	}
+
+	result = BundledProductSyncResult{
+		Created:   make([]string, 0),
+		Updated:   make([]string, 0),
+		Deleted:   make([]string, 0),
+		Unchanged: make([]string, 0),
+	}
+	desired := make(map[string]struct{}, len(reqs))
+	for _, req := range reqs {
+		if err = req.Validate(); err != nil {
+			return
+		}
+		key := strings.ToUpper(req.SKU)
+		if _, ok := desired[key]; ok {
+			err = fmt.Errorf("捆绑产品 %s 重复", req.SKU)
+			return
+		}
+		desired[key] = struct{}{}
+	}
+
+	bundles, err := s.bundledProducts()
+	if err != nil {
+		return
+	}
+	// 同步列表中的捆绑产品同样视为捆绑产品
+	nestedBundles := make(map[string]BundledProduct, len(bundles)+len(reqs))
+	for key, bundle := range bundles {
+		nestedBundles[key] = bundle
+	}
+	for _, req := range reqs {
+		nestedBundles[strings.ToUpper(req.SKU)] = BundledProduct{SKU: req.SKU}
+	}
+	if err = s.checkComponents(reqs, nestedBundles, options.AllowNestedBundles); err != nil {
+		return
+	}
+
+	for _, req := range reqs {
+		bundle, exists := bundles[strings.ToUpper(req.SKU)]
+		if exists && bundledProductEqual(bundle, req) {
+			result.Unchanged = append(result.Unchanged, req.SKU)
+			continue
+		}
+		if _, err = s.upsert(req); err != nil {
+			return
+		}
+		if exists {
+			result.Updated = append(result.Updated, req.SKU)
+		} else {
+			result.Created = append(result.Created, req.SKU)
+		}
+	}
+
+	if options.DeleteMissing {
+		skus := make([]string, 0)
+		for key, bundle := range bundles {
+			if _, ok := desired[key]; !ok {
+				skus = append(skus, bundle.SKU)
+			}
+		}
+		if len(skus) > 0 {
+			sort.Strings(skus)
+			if err = s.Delete(skus...); err != nil {
+				return
+			}
+			result.Deleted = skus
+		}
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/gox/jsonx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProductBundledService_All(t *testing.T) {
+	params := BundledProductsQueryParams{}
+	params.Limit = 1
+	items, _, _, err := lingXingClient.Services.Product.Bundle.All(params)
+	if err != nil {
+		t.Errorf("Services.Product.Bundle.All() error: %s", err.Error())
+	} else {
+		t.Log(jsonx.ToPrettyJson(items))
+	}
+}
+
+func TestUpsertBundledProductRequest_Validate(t *testing.T) {
+	req := UpsertBundledProductRequest{
+		SKU:             "SET",
+		ProductName:     "Set",
+		BundledProducts: []UpsertBundledProductItem{{SKU: "A", Quantity: 2}, {SKU: "B", Quantity: 1}},
+	}
+	assert.Nil(t, req.Validate())
+
+	req.BundledProducts = []UpsertBundledProductItem{{SKU: "A", Quantity: 0}}
+	assert.NotNil(t, req.Validate())
+	req.BundledProducts = []UpsertBundledProductItem{{SKU: "A", Quantity: 1}, {SKU: "a", Quantity: 1}}
+	assert.NotNil(t, req.Validate())
+	req.BundledProducts = []UpsertBundledProductItem{{SKU: "set", Quantity: 1}}
+	assert.NotNil(t, req.Validate())
+	req.BundledProducts = nil
+	assert.NotNil(t, req.Validate())
+}
+
+func TestBundledProductEqual(t *testing.T) {
+	product := BundledProduct{
+		SKU:             "SET",
+		ProductName:     "Set",
+		BundledProducts: []BundledProductItem{{SKU: "A", Quantity: 2}, {SKU: "B", Quantity: 1}},
+	}
+	req := UpsertBundledProductRequest{
+		SKU:             "SET",
+		ProductName:     "Set",
+		BundledProducts: []UpsertBundledProductItem{{SKU: "b", Quantity: 1}, {SKU: "A", Quantity: 2}},
+	}
+	assert.True(t, bundledProductEqual(product, req))
+	req.BundledProducts[1].Quantity = 3
+	assert.False(t, bundledProductEqual(product, req))
+	req.BundledProducts = req.BundledProducts[:1]
+	assert.False(t, bundledProductEqual(product, req))
+}
+
+func TestProductBundledService_SyncEmpty(t *testing.T) {
+	_, err := productBundledService{}.Sync(nil, BundledProductSyncOptions{DeleteMissing: true})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,321 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/gox/inx"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 产品索引
+// 汇总本地产品、Listing、捆绑产品数据，提供 SKU、产品 ID、FNSKU 之间的相互查询
+// SKU、FNSKU 查询时不区分大小写
+
+// ProductIndexEntry 产品索引项
+type ProductIndexEntry struct {
+	ProductId    int                  `json:"product_id"`    // 产品 ID
+	SKU          string               `json:"sku"`           // 本地 SKU
+	ProductName  string               `json:"product_name"`  // 品名
+	IsCombo      bool                 `json:"is_combo"`      // 是否为组合商品
+	IsBundled    bool                 `json:"is_bundled"`    // 是否为捆绑产品
+	FNSKUs       []string             `json:"fnskus"`        // FNSKU
+	MSKUs        []string             `json:"mskus"`         // MSKU
+	BundledItems []BundledProductItem `json:"bundled_items"` // 捆绑子产品
+}
+
+// ProductIndex 产品索引
+type ProductIndex struct {
+	mu              sync.RWMutex
+	entries         map[string]*ProductIndexEntry // 以 SKU 为键
+	ids             map[int]string                // 产品 ID => SKU
+	fnSKUs          map[string]string             // FNSKU => SKU
+	lastRefreshTime time.Time                     // 最后一次刷新时间
+}
+
+func NewProductIndex() *ProductIndex {
+	return &ProductIndex{
+		entries: make(map[string]*ProductIndexEntry),
+		ids:     make(map[int]string),
+		fnSKUs:  make(map[string]string),
+	}
+}
+
+// entry 获取 SKU 对应的索引项，不存在时创建
+func (idx *ProductIndex) entry(sku string) *ProductIndexEntry {
+	key := skuKey(sku)
+	e, ok := idx.entries[key]
+	if !ok {
+		e = &ProductIndexEntry{
+			SKU:          sku,
+			FNSKUs:       make([]string, 0),
+			MSKUs:        make([]string, 0),
+			BundledItems: make([]BundledProductItem, 0),
+		}
+		idx.entries[key] = e
+	}
+	return e
+}
+
+// AddProducts 添加本地产品
+func (idx *ProductIndex) AddProducts(products ...Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.addProducts(products...)
+}
+
+func (idx *ProductIndex) addProducts(products ...Product) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		// SKU 变更时将旧 SKU 的 Listing 数据迁移到新 SKU
+		if oldSKU, ok := idx.ids[product.ID]; ok && skuKey(oldSKU) != skuKey(product.SKU) {
+			idx.rename(oldSKU, product.SKU)
+		}
+		e := idx.entry(product.SKU)
+		e.ProductId = product.ID
+		e.SKU = product.SKU
+		e.ProductName = product.ProductName
+		e.IsCombo = product.IsCombo
+		idx.ids[product.ID] = product.SKU
+	}
+}
+
+// rename 将旧 SKU 的索引项合并到新 SKU，并更新 FNSKU 的指向
+func (idx *ProductIndex) rename(oldSKU, newSKU string) {
+	oldKey := skuKey(oldSKU)
+	old, ok := idx.entries[oldKey]
+	if !ok {
+		return
+	}
+	delete(idx.entries, oldKey)
+	e := idx.entry(newSKU)
+	for _, fnSKU := range old.FNSKUs {
+		if !inx.StringIn(fnSKU, e.FNSKUs...) {
+			e.FNSKUs = append(e.FNSKUs, fnSKU)
+		}
+	}
+	for _, msku := range old.MSKUs {
+		if !inx.StringIn(msku, e.MSKUs...) {
+			e.MSKUs = append(e.MSKUs, msku)
+		}
+	}
+	if old.IsBundled && !e.IsBundled {
+		e.IsBundled = true
+		e.BundledItems = old.BundledItems
+	}
+	for fnSKU, sku := range idx.fnSKUs {
+		if skuKey(sku) == oldKey {
+			idx.fnSKUs[fnSKU] = newSKU
+		}
+	}
+}
+
+// AddListings 添加 Listing，未配对本地 SKU 的 Listing 将被忽略
+func (idx *ProductIndex) AddListings(listings ...Listing) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.addListings(listings...)
+}
+
+func (idx *ProductIndex) addListings(listings ...Listing) {
+	for _, listing := range listings {
+		if listing.LocalSKU == "" {
+			continue
+		}
+		e := idx.entry(listing.LocalSKU)
+		if listing.FnSKU != "" {
+			if !inx.StringIn(listing.FnSKU, e.FNSKUs...) {
+				e.FNSKUs = append(e.FNSKUs, listing.FnSKU)
+			}
+			idx.fnSKUs[skuKey(listing.FnSKU)] = e.SKU
+		}
+		if listing.SellerSKU != "" && !inx.StringIn(listing.SellerSKU, e.MSKUs...) {
+			e.MSKUs = append(e.MSKUs, listing.SellerSKU)
+		}
+	}
+}
+
+// AddBundledProducts 添加捆绑产品
+func (idx *ProductIndex) AddBundledProducts(products ...BundledProduct) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.addBundledProducts(products...)
+}
+
+func (idx *ProductIndex) addBundledProducts(products ...BundledProduct) {
+	for _, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+		e := idx.entry(product.SKU)
+		if e.ProductId == 0 {
+			e.ProductId = product.ID
+			idx.ids[product.ID] = product.SKU
+		}
+		if e.ProductName == "" {
+			e.ProductName = product.ProductName
+		}
+		e.IsBundled = true
+		e.BundledItems = append(make([]BundledProductItem, 0, len(product.BundledProducts)), product.BundledProducts...)
+		for _, item := range product.BundledProducts {
+			if item.SKU == "" {
+				continue
+			}
+			child := idx.entry(item.SKU)
+			if child.ProductId == 0 {
+				if id, err := strconv.Atoi(item.ProductId); err == nil && id > 0 {
+					child.ProductId = id
+					idx.ids[id] = item.SKU
+				}
+			}
+		}
+	}
+}
+
+// prune 移除没有产品 ID、Listing 和捆绑数据的索引项
+func (idx *ProductIndex) prune() {
+	for key, e := range idx.entries {
+		if e.ProductId == 0 && len(e.FNSKUs) == 0 && len(e.MSKUs) == 0 && !e.IsBundled {
+			delete(idx.entries, key)
+		}
+	}
+}
+
+func (idx *ProductIndex) copy(e *ProductIndexEntry) ProductIndexEntry {
+	v := *e
+	v.FNSKUs = append(make([]string, 0, len(e.FNSKUs)), e.FNSKUs...)
+	v.MSKUs = append(make([]string, 0, len(e.MSKUs)), e.MSKUs...)
+	v.BundledItems = append(make([]BundledProductItem, 0, len(e.BundledItems)), e.BundledItems...)
+	return v
+}
+
+// BySKU 根据本地 SKU 查询
+func (idx *ProductIndex) BySKU(sku string) (entry ProductIndexEntry, exists bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if e, ok := idx.entries[skuKey(sku)]; ok {
+		return idx.copy(e), true
+	}
+	return
+}
+
+// ByID 根据产品 ID 查询
+func (idx *ProductIndex) ByID(id int) (entry ProductIndexEntry, exists bool) {
+	idx.mu.RLock()
+	sku, ok := idx.ids[id]
+	idx.mu.RUnlock()
+	if !ok {
+		return
+	}
+	return idx.BySKU(sku)
+}
+
+// ByFNSKU 根据 FNSKU 查询
+func (idx *ProductIndex) ByFNSKU(fnSKU string) (entry ProductIndexEntry, exists bool) {
+	idx.mu.RLock()
+	sku, ok := idx.fnSKUs[skuKey(fnSKU)]
+	idx.mu.RUnlock()
+	if !ok {
+		return
+	}
+	return idx.BySKU(sku)
+}
+
+// ProductId 根据 SKU 查询产品 ID，不存在时返回 0
+func (idx *ProductIndex) ProductId(sku string) int {
+	e, _ := idx.BySKU(sku)
+	return e.ProductId
+}
+
+// SKU 根据产品 ID 查询 SKU，不存在时返回空字符串
+func (idx *ProductIndex) SKU(id int) string {
+	e, _ := idx.ByID(id)
+	return e.SKU
+}
+
+// Len 索引中的 SKU 数量
+func (idx *ProductIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// LastRefreshTime 最后一次刷新时间
+func (idx *ProductIndex) LastRefreshTime() time.Time {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.lastRefreshTime
+}
+
+// Reset 清空索引，之后的刷新将重新全量拉取本地产品
+func (idx *ProductIndex) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = make(map[string]*ProductIndexEntry)
+	idx.ids = make(map[int]string)
+	idx.fnSKUs = make(map[string]string)
+	idx.lastRefreshTime = time.Time{}
+}
+
+// Refresh 从接口刷新索引
+// 首次刷新时拉取全部本地产品，之后仅拉取上次刷新后更新的本地产品。
+// Listing 和捆绑产品接口不支持按更新时间查询，每次均全量拉取并替换原有的 Listing（sids 不为空时）和捆绑数据，
+// sids 为空时不拉取 Listing，保留原有的 Listing 数据。
+// 增量拉取无法获取已删除的本地产品，需要移除已删除的本地产品时请先调用 Reset
+func (idx *ProductIndex) Refresh(client *LingXing, sids ...int) error {
+	startTime := time.Now()
+	productParams := ProductsQueryParams{}
+	if t := idx.LastRefreshTime(); !t.IsZero() {
+		productParams.UpdateTimeStart = int(t.Unix())
+	}
+	products, err := queryAll(func(offset int) ([]Product, int, bool, error) {
+		productParams.Offset = offset
+		return client.Services.Product.All(productParams)
+	})
+	if err != nil {
+		return err
+	}
+
+	listings := make([]Listing, 0)
+	for _, sid := range sids {
+		listingParams := ListingsQueryParams{SID: sid}
+		items, err := queryAll(func(offset int) ([]Listing, int, bool, error) {
+			listingParams.Offset = offset
+			return client.Services.Sale.Listing.All(listingParams)
+		})
+		if err != nil {
+			return err
+		}
+		listings = append(listings, items...)
+	}
+
+	bundledParams := BundledProductsQueryParams{}
+	bundledProducts, err := queryAll(func(offset int) ([]BundledProduct, int, bool, error) {
+		bundledParams.Offset = offset
+		return client.Services.Product.Bundle.All(bundledParams)
+	})
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.addProducts(products...)
+	for _, e := range idx.entries {
+		if len(sids) > 0 {
+			e.FNSKUs = make([]string, 0)
+			e.MSKUs = make([]string, 0)
+		}
+		e.IsBundled = false
+		e.BundledItems = make([]BundledProductItem, 0)
+	}
+	if len(sids) > 0 {
+		idx.fnSKUs = make(map[string]string)
+	}
+	idx.addListings(listings...)
+	idx.addBundledProducts(bundledProducts...)
+	idx.prune()
+	idx.lastRefreshTime = startTime
+	return nil
+}
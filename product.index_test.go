@@ -0,0 +1,81 @@
+package lingxing
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProductIndex(t *testing.T) {
+	idx := NewProductIndex()
+	idx.AddListings(
+		Listing{SellerSKU: "MSKU-A", FnSKU: "X001", LocalSKU: "SKU-A"},
+		Listing{SellerSKU: "MSKU-A2", FnSKU: "X001", LocalSKU: "SKU-A"},
+		Listing{SellerSKU: "MSKU-N", FnSKU: "X009"},
+	)
+	idx.AddProducts(
+		Product{ID: 1, SKU: "SKU-A", ProductName: "A"},
+		Product{ID: 2, SKU: "SKU-B", ProductName: "B"},
+	)
+	idx.AddBundledProducts(BundledProduct{
+		ID:          3,
+		SKU:         "SKU-C",
+		ProductName: "C",
+		BundledProducts: []BundledProductItem{
+			{ProductId: "1", SKU: "SKU-A", Quantity: 2},
+			{ProductId: "4", SKU: "SKU-D", Quantity: 1},
+		},
+	})
+
+	assert.Equal(t, 4, idx.Len())
+	e, ok := idx.ByFNSKU("x001")
+	assert.True(t, ok)
+	assert.Equal(t, 1, e.ProductId)
+	assert.Equal(t, []string{"X001"}, e.FNSKUs)
+	assert.Equal(t, []string{"MSKU-A", "MSKU-A2"}, e.MSKUs)
+	_, ok = idx.ByFNSKU("X009")
+	assert.False(t, ok)
+
+	assert.Equal(t, "SKU-B", idx.SKU(2))
+	assert.Equal(t, 3, idx.ProductId("sku-c"))
+	assert.Equal(t, 4, idx.ProductId("SKU-D"))
+	e, ok = idx.BySKU("SKU-C")
+	assert.True(t, ok)
+	assert.True(t, e.IsBundled)
+	assert.Len(t, e.BundledItems, 2)
+
+	// SKU 变更
+	idx.AddProducts(Product{ID: 2, SKU: "SKU-B2", ProductName: "B"})
+	_, ok = idx.BySKU("SKU-B")
+	assert.False(t, ok)
+	assert.Equal(t, "SKU-B2", idx.SKU(2))
+
+	// SKU 变更后 FNSKU、MSKU 指向新的 SKU
+	idx.AddProducts(Product{ID: 1, SKU: "SKU-A1", ProductName: "A"})
+	_, ok = idx.BySKU("SKU-A")
+	assert.False(t, ok)
+	e, ok = idx.ByFNSKU("X001")
+	if assert.True(t, ok) {
+		assert.Equal(t, "SKU-A1", e.SKU)
+		assert.Equal(t, 1, e.ProductId)
+		assert.Equal(t, []string{"MSKU-A", "MSKU-A2"}, e.MSKUs)
+	}
+
+	idx.Reset()
+	assert.Equal(t, 0, idx.Len())
+	assert.True(t, idx.LastRefreshTime().IsZero())
+}
+
+func TestProductsQueryParams_Validate(t *testing.T) {
+	assert.Nil(t, ProductsQueryParams{UpdateTimeStart: 100, UpdateTimeEnd: 200, Status: ProductStatusOnSale}.Validate())
+	assert.NotNil(t, ProductsQueryParams{UpdateTimeStart: 200, UpdateTimeEnd: 100}.Validate())
+	assert.NotNil(t, ProductsQueryParams{Status: 9}.Validate())
+}
+
+func TestProductsQueryParams_IsCombo(t *testing.T) {
+	b, _ := jsoniter.Marshal(ProductsQueryParams{})
+	assert.NotContains(t, string(b), "is_combo")
+	isCombo := false
+	b, _ = jsoniter.Marshal(ProductsQueryParams{IsCombo: &isCombo})
+	assert.Contains(t, string(b), `"is_combo":false`)
+}
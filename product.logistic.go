@@ -0,0 +1,148 @@
+package lingxing
+
+import (
+	"fmt"
+	"github.com/hiscaler/gox/stringx"
+	"github.com/hiscaler/lingxing/constant"
+	jsoniter "github.com/json-iterator/go"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 产品物流关联
+// 领星接口中以 `国家代码_字段名` 的格式返回各国家（地区）的物流数据，如 US_cg_transport_costs、UK_bg_tax_rate，
+// 解析后统一以国家代码为键保存，其中英国的国家代码 UK 会被转换为 GB，序列化时再转换为 UK
+
+// ProductLogisticCountry 国家（地区）物流关联
+type ProductLogisticCountry struct {
+	CgTransportCosts float64 `json:"cg_transport_costs"` // 默认头程成本（含税）
+	Currency         string  `json:"currency"`           // 官方汇率 code
+	BgImportHsCode   string  `json:"bg_import_hs_code"`  // 报关：HS Code（进口国）
+	BgTaxRate        float64 `json:"bg_tax_rate"`        // 报关：税率（百分比，如 5 表示 5%）
+}
+
+// IsEmpty 是否未设置物流数据（仅设置了币种也视为未设置）
+func (c ProductLogisticCountry) IsEmpty() bool {
+	return c.CgTransportCosts == 0 && stringx.IsBlank(c.BgImportHsCode) && c.BgTaxRate == 0
+}
+
+// ProductLogistic 物流关联，以国家代码为键
+type ProductLogistic map[string]ProductLogisticCountry
+
+// productLogisticCountryCode 将领星的国家代码转换为标准的国家代码
+func productLogisticCountryCode(code string) string {
+	code = strings.ToUpper(code)
+	if code == "UK" {
+		code = constant.CountryCodeUnitedKingdom
+	}
+	return code
+}
+
+// Country 获取国家（地区）物流数据
+func (m ProductLogistic) Country(countryCode string) (c ProductLogisticCountry, exists bool) {
+	c, exists = m[productLogisticCountryCode(countryCode)]
+	return
+}
+
+// Set 设置国家（地区）物流数据
+func (m ProductLogistic) Set(countryCode string, c ProductLogisticCountry) {
+	m[productLogisticCountryCode(countryCode)] = c
+}
+
+// CountryCodes 已设置物流数据的国家代码（升序）
+func (m ProductLogistic) CountryCodes() []string {
+	codes := make([]string, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func productLogisticFloat(v interface{}) (float64, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return t, nil
+	case string:
+		t = strings.TrimSpace(t)
+		if t == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(t, 64)
+	default:
+		return 0, fmt.Errorf("lingxing: 无效的数值 %v", v)
+	}
+}
+
+func productLogisticString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// UnmarshalJSON 解析物流关联，未设置物流数据的国家（地区）将被忽略
+func (m *ProductLogistic) UnmarshalJSON(b []byte) (err error) {
+	result := make(ProductLogistic)
+	object := make(map[string]interface{})
+	if err = jsoniter.Unmarshal(b, &object); err != nil {
+		return
+	}
+
+	for key, value := range object {
+		index := strings.Index(key, "_")
+		if index != 2 {
+			// 非 `国家代码_字段名` 格式，如 country_code
+			continue
+		}
+		code := productLogisticCountryCode(key[:index])
+		c := result[code]
+		switch key[index+1:] {
+		case "cg_transport_costs":
+			c.CgTransportCosts, err = productLogisticFloat(value)
+		case "currency":
+			c.Currency = productLogisticString(value)
+		case "bg_import_hs_code":
+			c.BgImportHsCode = productLogisticString(value)
+		case "bg_tax_rate":
+			c.BgTaxRate, err = productLogisticFloat(value)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("lingxing: 解析物流关联 %s 失败：%w", key, err)
+		}
+		result[code] = c
+	}
+	for code, c := range result {
+		if c.IsEmpty() {
+			delete(result, code)
+		}
+	}
+	*m = result
+	return
+}
+
+// MarshalJSON 序列化为领星接口格式
+func (m ProductLogistic) MarshalJSON() ([]byte, error) {
+	object := make(map[string]interface{}, len(m)*4)
+	for code, c := range m {
+		if code == constant.CountryCodeUnitedKingdom {
+			code = "UK"
+		}
+		object[code+"_cg_transport_costs"] = c.CgTransportCosts
+		object[code+"_currency"] = c.Currency
+		object[code+"_bg_import_hs_code"] = c.BgImportHsCode
+		object[code+"_bg_tax_rate"] = c.BgTaxRate
+	}
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(object)
+}
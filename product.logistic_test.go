@@ -0,0 +1,41 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/lingxing/constant"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProductLogistic_UnmarshalJSON(t *testing.T) {
+	var d ProductDetail
+	err := jsoniter.UnmarshalFromString(`{"id":1,"product_logistics_relation":[{"country_code":"","US_cg_transport_costs":"12.50","US_currency":"USD","US_bg_import_hs_code":"8471","US_bg_tax_rate":"5","UK_cg_transport_costs":3,"UK_currency":"GBP","FR_bg_import_hs_code":"9503","PL_currency":"PLN","PL_cg_transport_costs":""}]}`, &d)
+	assert.Nil(t, err)
+	assert.Len(t, d.ProductLogisticsRelation, 1)
+	logistic := d.ProductLogisticsRelation[0]
+	assert.Equal(t, []string{constant.CountryCodeFrance, constant.CountryCodeUnitedKingdom, constant.CountryCodeAmerica}, logistic.CountryCodes())
+	us, ok := logistic.Country(constant.CountryCodeAmerica)
+	assert.True(t, ok)
+	assert.Equal(t, ProductLogisticCountry{CgTransportCosts: 12.5, Currency: "USD", BgImportHsCode: "8471", BgTaxRate: 5}, us)
+	uk, ok := logistic.Country("UK")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, uk.CgTransportCosts)
+	_, ok = logistic.Country(constant.CountryCodePoland)
+	assert.False(t, ok)
+
+	assert.Nil(t, jsoniter.UnmarshalFromString(`{"US_currency":"USD"}`, &logistic))
+	assert.Len(t, logistic, 0)
+	assert.NotNil(t, jsoniter.UnmarshalFromString(`{"US_bg_tax_rate":"abc"}`, &logistic))
+}
+
+func TestProductLogistic_MarshalJSON(t *testing.T) {
+	logistic := ProductLogistic{}
+	logistic.Set(constant.CountryCodeUnitedKingdom, ProductLogisticCountry{CgTransportCosts: 3, Currency: "GBP"})
+	b, err := jsoniter.Marshal(UpsertProductRequest{ProductLogisticsRelation: []ProductLogistic{logistic}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"product_logistics_relation":[{"UK_bg_import_hs_code":"","UK_bg_tax_rate":0,"UK_cg_transport_costs":3,"UK_currency":"GBP"}]`)
+
+	var decoded UpsertProductRequest
+	assert.Nil(t, jsoniter.Unmarshal(b, &decoded))
+	assert.Equal(t, []ProductLogistic{logistic}, decoded.ProductLogisticsRelation)
+}
@@ -1,8 +1,7 @@
 package lingxing
 
 import (
-	"github.com/hiscaler/gox/stringx"
-	"github.com/hiscaler/lingxing/constant"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -53,6 +52,7 @@ type Product struct {
 	StatusText       string          `json:"status_text"`        // 状态文本
 	IsCombo          bool            `json:"is_combo"`           // 是否为组合商品（0：否、1：是）
 	CreateTime       int             `json:"create_time"`        // 创建时间
+	UpdateTime       int             `json:"update_time"`        // 更新时间
 	ProductDeveloper string          `json:"product_developer"`  // 开发人员
 	CgOptUsername    string          `json:"cg_opt_username"`    // 采购：采购员
 	SupplierQuote    []SupplierQuote `json:"supplier_quote"`     // 供应商报价
@@ -60,10 +60,22 @@ type Product struct {
 
 type ProductsQueryParams struct {
 	Paging
+	SKUs            []string `json:"sku_list,omitempty"`          // SKU
+	UpdateTimeStart int      `json:"update_time_start,omitempty"` // 更新时间开始（时间戳，单位：秒，闭区间）
+	UpdateTimeEnd   int      `json:"update_time_end,omitempty"`   // 更新时间结束（时间戳，单位：秒，开区间）
+	Status          int      `json:"status,omitempty"`            // 状态（1：停售、2：在售、3：开发中、4：清仓）
+	CID             int      `json:"cid,omitempty"`               // 分类 ID
+	BID             int      `json:"bid,omitempty"`               // 品牌 ID
+	IsCombo         *bool    `json:"is_combo,omitempty"`          // 是否为组合商品（nil：不限、false：否、true：是）
 }
 
 func (m ProductsQueryParams) Validate() error {
-	return nil
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKUs, validation.Length(0, 1000).Error("SKU 最多 {{.max}} 个")),
+		validation.Field(&m.UpdateTimeStart, validation.Min(0).Error("更新时间开始不能小于 {{.threshold}}")),
+		validation.Field(&m.UpdateTimeEnd, validation.When(m.UpdateTimeEnd != 0, validation.Min(m.UpdateTimeStart).Error("更新时间结束不能小于更新时间开始"))),
+		validation.Field(&m.Status, validation.In(ProductStatusStopSelling, ProductStatusOnSale, ProductStatusDeveloping, ProductStatusClearance).Error("无效的产品状态")),
+	)
 }
 
 func (s productProductService) All(params ProductsQueryParams) (items []Product, nextOffset int, isLastPage bool, err error) {
@@ -91,6 +103,28 @@ func (s productProductService) All(params ProductsQueryParams) (items []Product,
 	return
 }
 
+// BySKUs 根据 SKU 查询本地产品，SKU 超过 1000 个时分批查询
+func (s productProductService) BySKUs(skus ...string) ([]Product, error) {
+	const size = 1000
+	products := make([]Product, 0, len(skus))
+	for i := 0; i < len(skus); i += size {
+		j := i + size
+		if j > len(skus) {
+			j = len(skus)
+		}
+		params := ProductsQueryParams{SKUs: skus[i:j]}
+		items, err := queryAll(func(offset int) ([]Product, int, bool, error) {
+			params.Offset = offset
+			return s.All(params)
+		})
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, items...)
+	}
+	return products, nil
+}
+
 // 产品详情
 // https://openapidoc.lingxing.com/#/docs/Product/ProductDetails
 
@@ -99,113 +133,6 @@ type ProductPicture struct {
 	IsPrimary bool   `json:"is_primary"` // 是否产品主图（0：否、1：是）
 }
 
-// ProductLogistic 物流关联
-type ProductLogistic struct {
-	CountryCode string `json:"country_code"`
-
-	// US
-	USCgTransportCosts float64 `json:"US_cg_transport_costs,omitempty"` // 美国默认头程成本（含税）
-	USCurrency         string  `json:"US_currency,omitempty"`           // 美国官方汇率 code
-	USBgImportHsCode   string  `json:"US_bg_import_hs_code,omitempty"`  // 报关：美国 HS Code（进口国）
-	USBgTaxRate        float64 `json:"US_bg_tax_rate,omitempty"`        // 报关：美国税率
-
-	// CA
-	CACgTransportCosts float64 `json:"CA_cg_transport_costs,omitempty"`
-	CACurrency         string  `json:"CA_currency,omitempty"`
-	CABgImportHsCode   string  `json:"CA_bg_import_hs_code,omitempty"`
-	CABgTaxRate        float64 `json:"CA_bg_tax_rate,omitempty"`
-
-	// MX
-	MXCgTransportCosts float64 `json:"MX_cg_transport_costs,omitempty"`
-	MXCurrency         string  `json:"MX_currency,omitempty"`
-	MXBgImportHsCode   string  `json:"MX_bg_import_hs_code,omitempty"`
-	MXBgTaxRate        float64 `json:"MX_bg_tax_rate,omitempty"`
-
-	// JP
-	JPCgTransportCosts float64 `json:"JP_cg_transport_costs,omitempty"`
-	JPCurrency         string  `json:"JP_currency,omitempty"`
-	JPBgImportHsCode   string  `json:"JP_bg_import_hs_code,omitempty"`
-	JPBgTaxRate        float64 `json:"JP_bg_tax_rate,omitempty"`
-
-	// UK
-	UKCgTransportCosts float64 `json:"UK_cg_transport_costs,omitempty"`
-	UKCurrency         string  `json:"UK_currency,omitempty"`
-	UKBgImportHsCode   string  `json:"UK_bg_import_hs_code,omitempty"`
-	UKBgTaxRate        float64 `json:"UK_bg_tax_rate,omitempty"`
-
-	// DE
-	DECgTransportCosts float64 `json:"DE_cg_transport_costs,omitempty"`
-	DECurrency         string  `json:"DE_currency,omitempty"`
-	DEBgImportHsCode   string  `json:"DE_bg_import_hs_code,omitempty"`
-	DEBgTaxRate        float64 `json:"DE_bg_tax_rate,omitempty"`
-
-	// FR
-	FRCgTransportCosts float64 `json:"FR_cg_transport_costs,omitempty"`
-	FRCurrency         string  `json:"FR_currency,omitempty"`
-	FRBgImportHsCode   string  `json:"FR_bg_import_hs_code,omitempty"`
-	FRBgTaxRate        float64 `json:"FR_bg_tax_rate,omitempty"`
-
-	// IT
-	ITCgTransportCosts float64 `json:"IT_cg_transport_costs,omitempty"`
-	ITCurrency         string  `json:"IT_currency,omitempty"`
-	ITBgImportHsCode   string  `json:"IT_bg_import_hs_code,omitempty"`
-	ITBgTaxRate        float64 `json:"IT_bg_tax_rate,omitempty"`
-
-	// NL
-	NLCgTransportCosts float64 `json:"NL_cg_transport_costs,omitempty"`
-	NLCurrency         string  `json:"NL_currency,omitempty"`
-	NLBgImportHsCode   string  `json:"NL_bg_import_hs_code,omitempty"`
-	NLBgTaxRate        float64 `json:"NL_bg_tax_rate,omitempty"`
-
-	// ES
-	ESCgTransportCosts float64 `json:"ES_cg_transport_costs,omitempty"`
-	ESCurrency         string  `json:"ES_currency,omitempty"`
-	ESBgImportHsCode   string  `json:"ES_bg_import_hs_code,omitempty"`
-	ESBgTaxRate        float64 `json:"ES_bg_tax_rate,omitempty"`
-
-	// AU
-	AUCgTransportCosts float64 `json:"AU_cg_transport_costs,omitempty"`
-	AUCurrency         string  `json:"AU_currency,omitempty"`
-	AUBgImportHsCode   string  `json:"AU_bg_import_hs_code,omitempty"`
-	AUBgTaxRate        float64 `json:"AU_bg_tax_rate,omitempty"`
-
-	// SG
-	SGCgTransportCosts float64 `json:"SG_cg_transport_costs,omitempty"`
-	SGCurrency         string  `json:"SG_currency,omitempty"`
-	SGBgImportHsCode   string  `json:"SG_bg_import_hs_code,omitempty"`
-	SGBgTaxRate        float64 `json:"SG_bg_tax_rate,omitempty"`
-
-	// IN
-	INCgTransportCosts float64 `json:"IN_cg_transport_costs,omitempty"`
-	INCurrency         string  `json:"IN_currency,omitempty"`
-	INBgImportHsCode   string  `json:"IN_bg_import_hs_code,omitempty"`
-	INBgTaxRate        float64 `json:"IN_bg_tax_rate,omitempty"`
-
-	// AE
-	AECgTransportCosts float64 `json:"AE_cg_transport_costs,omitempty"`
-	AECurrency         string  `json:"AE_currency,omitempty"`
-	AEBgImportHsCode   string  `json:"AE_bg_import_hs_code,omitempty"`
-	AEBgTaxRate        float64 `json:"AE_bg_tax_rate,omitempty"`
-
-	// SA
-	SACgTransportCosts float64 `json:"SA_cg_transport_costs,omitempty"`
-	SACurrency         string  `json:"SA_currency,omitempty"`
-	SABgImportHsCode   string  `json:"SA_bg_import_hs_code,omitempty"`
-	SABgTaxRate        float64 `json:"SA_bg_tax_rate,omitempty"`
-
-	// BR
-	BRCgTransportCosts float64 `json:"BR_cg_transport_costs,omitempty"`
-	BRCurrency         string  `json:"BR_currency,omitempty"`
-	BRBgImportHsCode   string  `json:"BR_bg_import_hs_code,omitempty"`
-	BRBgTaxRate        float64 `json:"BR_bg_tax_rate,omitempty"`
-
-	// SE
-	SECgTransportCosts float64 `json:"SE_cg_transport_costs,omitempty"`
-	SECurrency         string  `json:"SE_currency,omitempty"`
-	SEBgImportHsCode   string  `json:"SE_bg_import_hs_code,omitempty"`
-	SEBgTaxRate        float64 `json:"SE_bg_tax_rate,omitempty"`
-}
-
 type ProductDetail struct {
 	ID                       int               `json:"id"`                         // 产品 ID
 	ProductName              string            `json:"product_name"`               // 产品名称
@@ -269,47 +196,9 @@ func (s productProductService) One(id int) (item ProductDetail, err error) {
 		if item.ID == 0 {
 			err = ErrNotFound
 		} else {
-			fnParse := func(costs float64, hsCode string, taxRate float64, countryCode string) (bool, string) {
-				if costs == 0 && stringx.IsBlank(hsCode) && taxRate == 0 {
-					return false, ""
-				}
-				return true, countryCode
-			}
-			logistics := make([]ProductLogistic, 0)
+			logistics := make([]ProductLogistic, 0, len(item.ProductLogisticsRelation))
 			for _, logistic := range item.ProductLogisticsRelation {
-				items := []struct {
-					Costs       float64
-					HsCode      string
-					Rate        float64
-					CountryCode string
-				}{
-					{logistic.USCgTransportCosts, logistic.USBgImportHsCode, logistic.USBgTaxRate, constant.CountryCodeAmerica},
-					{logistic.CACgTransportCosts, logistic.CABgImportHsCode, logistic.CABgTaxRate, constant.CountryCodeCanada},
-					{logistic.MXCgTransportCosts, logistic.MXBgImportHsCode, logistic.MXBgTaxRate, constant.CountryCodeMexico},
-					{logistic.JPCgTransportCosts, logistic.JPBgImportHsCode, logistic.JPBgTaxRate, constant.CountryCodeJapan},
-					{logistic.UKCgTransportCosts, logistic.UKBgImportHsCode, logistic.UKBgTaxRate, constant.CountryCodeUnitedKingdom},
-					{logistic.DECgTransportCosts, logistic.DEBgImportHsCode, logistic.DEBgTaxRate, constant.CountryCodeGermany},
-					{logistic.FRCgTransportCosts, logistic.FRBgImportHsCode, logistic.FRBgTaxRate, constant.CountryCodeFrance},
-					{logistic.ITCgTransportCosts, logistic.ITBgImportHsCode, logistic.ITBgTaxRate, constant.CountryCodeItaly},
-					{logistic.NLCgTransportCosts, logistic.NLBgImportHsCode, logistic.NLBgTaxRate, constant.CountryCodeNetherlands},
-					{logistic.ESCgTransportCosts, logistic.ESBgImportHsCode, logistic.ESBgTaxRate, constant.CountryCodeSpain},
-					{logistic.AUCgTransportCosts, logistic.AUBgImportHsCode, logistic.AUBgTaxRate, constant.CountryCodeAustralian},
-					{logistic.SGCgTransportCosts, logistic.SGBgImportHsCode, logistic.SGBgTaxRate, constant.CountryCodeSingapore},
-					{logistic.INCgTransportCosts, logistic.INBgImportHsCode, logistic.INBgTaxRate, constant.CountryCodeIndia},
-					{logistic.AECgTransportCosts, logistic.AEBgImportHsCode, logistic.AEBgTaxRate, constant.CountryCodeUnitedArabEmirates},
-					{logistic.SACgTransportCosts, logistic.SABgImportHsCode, logistic.SABgTaxRate, constant.CountryCodeSaudiArabia},
-					{logistic.BRCgTransportCosts, logistic.BRBgImportHsCode, logistic.BRBgTaxRate, constant.CountryCodeBrazil},
-					{logistic.SECgTransportCosts, logistic.SEBgImportHsCode, logistic.SEBgTaxRate, constant.CountryCodeSweden},
-				}
-				hasValid := false
-				for _, d := range items {
-					if ok, code := fnParse(d.Costs, d.HsCode, d.Rate, d.CountryCode); ok {
-						hasValid = true
-						logistic.CountryCode = code
-						break
-					}
-				}
-				if hasValid {
+				if len(logistic) > 0 {
 					logistics = append(logistics, logistic)
 				}
 			}
@@ -318,3 +207,152 @@ func (s productProductService) One(id int) (item ProductDetail, err error) {
 	}
 	return
 }
+
+// 添加、编辑本地产品
+
+// 产品状态
+const (
+	ProductStatusStopSelling = 1 // 停售
+	ProductStatusOnSale      = 2 // 在售
+	ProductStatusDeveloping  = 3 // 开发中
+	ProductStatusClearance   = 4 // 清仓
+)
+
+// UpsertProductComboItem 组合商品子产品
+type UpsertProductComboItem struct {
+	SKU      string `json:"sku"`      // 子产品 SKU
+	Quantity int    `json:"quantity"` // 数量
+}
+
+func (m UpsertProductComboItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("子产品 SKU 不能为空")),
+		validation.Field(&m.Quantity,
+			validation.Required.Error("子产品数量不能为空"),
+			validation.Min(1).Error("子产品数量不能小于 {{.threshold}}"),
+		),
+	)
+}
+
+type UpsertProductRequest struct {
+	SKU                      string                                  `json:"sku"`                                  // SKU（编辑时根据 SKU 匹配产品）
+	ProductName              string                                  `json:"product_name"`                         // 品名
+	PictureList              []ProductPicture                        `json:"picture_list,omitempty"`               // 产品图片
+	Model                    string                                  `json:"model,omitempty"`                      // 产品型号
+	Unit                     string                                  `json:"unit,omitempty"`                       // 商品单位（套、个、台）
+	Status                   int                                     `json:"status,omitempty"`                     // 状态（1：停售、2：在售、3：开发中、4：清仓）
+	CategoryId               int                                     `json:"category_id,omitempty"`                // 分类 ID
+	BrandId                  int                                     `json:"brand_id,omitempty"`                   // 品牌 ID
+	ProductDeveloper         string                                  `json:"product_developer,omitempty"`          // 开发人员
+	Description              string                                  `json:"description,omitempty"`                // 商品描述
+	CgOptUsername            string                                  `json:"cg_opt_username,omitempty"`            // 采购：采购员
+	CgDelivery               int                                     `json:"cg_delivery,omitempty"`                // 采购：交期
+	CgPrice                  float64                                 `json:"cg_price,omitempty"`                   // 采购：采购价格（RMB）
+	CgProductMaterial        string                                  `json:"cg_product_material,omitempty"`        // 采购：材质
+	CgProductLength          float64                                 `json:"cg_product_length,omitempty"`          // 采购：产品规格长（CM）
+	CgProductWidth           float64                                 `json:"cg_product_width,omitempty"`           // 采购：产品规格宽（CM）
+	CgProductHeight          float64                                 `json:"cg_product_height,omitempty"`          // 采购：产品规格高（CM）
+	CgPackageLength          float64                                 `json:"cg_package_length,omitempty"`          // 采购：包装规格长（CM）
+	CgPackageWidth           float64                                 `json:"cg_package_width,omitempty"`           // 采购：包装规格宽（CM）
+	CgPackageHeight          float64                                 `json:"cg_package_height,omitempty"`          // 采购：包装规格高（CM）
+	CgBoxLength              float64                                 `json:"cg_box_length,omitempty"`              // 采购：外箱规格长（CM）
+	CgBoxWidth               float64                                 `json:"cg_box_width,omitempty"`               // 采购：外箱规格宽（CM）
+	CgBoxHeight              float64                                 `json:"cg_box_height,omitempty"`              // 采购：外箱规格高（CM）
+	CgProductNetWeight       float64                                 `json:"cg_product_net_weight,omitempty"`      // 采购：产品净重（G）
+	CgProductGrossWeight     float64                                 `json:"cg_product_gross_weight,omitempty"`    // 采购：产品毛重（G）
+	CgBoxWeight              float64                                 `json:"cg_box_weight,omitempty"`              // 采购：外箱实重（KG）
+	CgBoxPcs                 int                                     `json:"cg_box_pcs,omitempty"`                 // 采购：单箱数量（包装数量）
+	BgCustomsExportName      string                                  `json:"bg_customs_export_name,omitempty"`     // 报关：申报品名（中文）
+	BgCustomsImportName      string                                  `json:"bg_customs_import_name,omitempty"`     // 报关：申报品名（英文）
+	BgCustomsImportPrice     float64                                 `json:"bg_customs_import_price,omitempty"`    // 报关：申报金额（进口国）
+	BgExportHsCode           string                                  `json:"bg_export_hs_code,omitempty"`          // 报关：HS Code（出口国）
+	SupplierQuote            []UpsertProductAuxMaterialSupplierQuote `json:"supplier_quote,omitempty"`             // 供应商报价（不传该参数则清空产品供应商报价，否则覆盖）
+	ProductLogisticsRelation []ProductLogistic                       `json:"product_logistics_relation,omitempty"` // 物流关联
+	IsCombo                  bool                                    `json:"is_combo"`                             // 是否组合商品（0：否、1：是）
+	GroupList                []UpsertProductComboItem                `json:"group_list,omitempty"`                 // 组合商品子产品（组合商品时必填）
+}
+
+// validateProductSupplierQuote 校验本地产品的供应商报价
+// 供应商报价和辅料共用同一数据结构，本地产品的校验规则单独定义，不影响辅料的添加、编辑
+func validateProductSupplierQuote(value interface{}) error {
+	quote, ok := value.(UpsertProductAuxMaterialSupplierQuote)
+	if !ok {
+		return nil
+	}
+	return validation.ValidateStruct(&quote,
+		validation.Field(&quote.ERPSupplierId, validation.When(quote.SupplierId == "", validation.Required.Error("供应商 ID 不能为空"))),
+		validation.Field(&quote.SupplierProductURL, validation.Length(0, 20).Error("采购链接最多 {{.max}} 个")),
+		validation.Field(&quote.Quotes, validation.Each(validation.By(validateProductSupplierQuoteItem), validation.Skip), validation.Skip),
+	)
+}
+
+// validateProductSupplierQuoteItem 校验本地产品的供应商报价信息
+func validateProductSupplierQuoteItem(value interface{}) error {
+	item, ok := value.(UpsertProductAuxMaterialSupplierQuoteItem)
+	if !ok {
+		return nil
+	}
+	return validation.ValidateStruct(&item,
+		validation.Field(&item.Currency,
+			validation.Required.Error("报价币种不能为空"),
+			validation.In("CNY", "USD").Error("无效的报价币种"),
+		),
+		validation.Field(&item.TaxRate,
+			validation.Min(0).Error("税率不能小于 {{.threshold}}"),
+			validation.Max(99).Error("税率不能大于 {{.threshold}}"),
+		),
+		validation.Field(&item.StepPrices, validation.Required.Error("阶梯价信息不能为空")),
+	)
+}
+
+func (m UpsertProductRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("SKU 不能为空")),
+		validation.Field(&m.ProductName, validation.Required.Error("品名不能为空")),
+		validation.Field(&m.Status, validation.In(ProductStatusStopSelling, ProductStatusOnSale, ProductStatusDeveloping, ProductStatusClearance).Error("无效的产品状态")),
+		validation.Field(&m.CgDelivery, validation.Min(0).Error("采购交期不能小于 {{.threshold}}")),
+		validation.Field(&m.CgPrice, validation.Min(0.0).Error("采购价格不能小于 {{.threshold}}")),
+		validation.Field(&m.CgProductLength, validation.Min(0.0).Error("产品规格长不能小于 {{.threshold}}")),
+		validation.Field(&m.CgProductWidth, validation.Min(0.0).Error("产品规格宽不能小于 {{.threshold}}")),
+		validation.Field(&m.CgProductHeight, validation.Min(0.0).Error("产品规格高不能小于 {{.threshold}}")),
+		validation.Field(&m.CgPackageLength, validation.Min(0.0).Error("包装规格长不能小于 {{.threshold}}")),
+		validation.Field(&m.CgPackageWidth, validation.Min(0.0).Error("包装规格宽不能小于 {{.threshold}}")),
+		validation.Field(&m.CgPackageHeight, validation.Min(0.0).Error("包装规格高不能小于 {{.threshold}}")),
+		validation.Field(&m.CgBoxLength, validation.Min(0.0).Error("外箱规格长不能小于 {{.threshold}}")),
+		validation.Field(&m.CgBoxWidth, validation.Min(0.0).Error("外箱规格宽不能小于 {{.threshold}}")),
+		validation.Field(&m.CgBoxHeight, validation.Min(0.0).Error("外箱规格高不能小于 {{.threshold}}")),
+		validation.Field(&m.CgProductNetWeight, validation.Min(0.0).Error("产品净重不能小于 {{.threshold}}")),
+		validation.Field(&m.CgProductGrossWeight, validation.Min(0.0).Error("产品毛重不能小于 {{.threshold}}")),
+		validation.Field(&m.CgBoxWeight, validation.Min(0.0).Error("外箱实重不能小于 {{.threshold}}")),
+		validation.Field(&m.CgBoxPcs, validation.Min(0).Error("单箱数量不能小于 {{.threshold}}")),
+		validation.Field(&m.BgCustomsImportPrice, validation.Min(0.0).Error("申报金额不能小于 {{.threshold}}")),
+		validation.Field(&m.SupplierQuote, validation.Each(validation.By(validateProductSupplierQuote))),
+		validation.Field(&m.GroupList, validation.When(m.IsCombo, validation.Required.Error("组合商品子产品不能为空"))),
+	)
+}
+
+// Upsert 添加、编辑本地产品
+func (s productProductService) Upsert(req UpsertProductRequest) (id int, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			ProductId int    `json:"product_id"` // 产品 ID
+			SKU       string `json:"sku"`        // SKU
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/storage/product/set")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		id = res.Data.ProductId
+	}
+	return
+}
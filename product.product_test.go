@@ -3,6 +3,7 @@ package lingxing
 import (
 	"errors"
 	"github.com/hiscaler/gox/jsonx"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -40,3 +41,64 @@ func TestProductServiceProduct_One(t *testing.T) {
 		t.Log(jsonx.ToPrettyJson(item))
 	}
 }
+
+func TestUpsertProductRequest_Validate(t *testing.T) {
+	quote := UpsertProductAuxMaterialSupplierQuote{
+		ERPSupplierId: "1",
+		Quotes: []UpsertProductAuxMaterialSupplierQuoteItem{
+			{
+				Currency:   "CNY",
+				IsTax:      true,
+				TaxRate:    13,
+				StepPrices: []UpsertProductAuxMaterialSupplierQuoteItemStepPrice{{Moq: 1, PriceWithTax: 10}},
+			},
+		},
+	}
+	badQuote := quote
+	badQuote.Quotes = []UpsertProductAuxMaterialSupplierQuoteItem{{Currency: "EUR"}}
+	noSupplierQuote := quote
+	noSupplierQuote.ERPSupplierId = ""
+	noStepPriceQuote := quote
+	noStepPriceQuote.Quotes = []UpsertProductAuxMaterialSupplierQuoteItem{{Currency: "USD"}}
+	badStepPriceQuote := quote
+	badStepPriceQuote.Quotes = []UpsertProductAuxMaterialSupplierQuoteItem{{Currency: "USD", StepPrices: []UpsertProductAuxMaterialSupplierQuoteItemStepPrice{{Moq: 1, PriceWithTax: -1}}}}
+	tests := []struct {
+		name     string
+		req      UpsertProductRequest
+		hasError bool
+	}{
+		{"t0", UpsertProductRequest{}, true},
+		{"t1", UpsertProductRequest{SKU: "A001", ProductName: "A"}, false},
+		{"t2", UpsertProductRequest{SKU: "A001", ProductName: "A", Status: 5}, true},
+		{"t3", UpsertProductRequest{SKU: "A001", ProductName: "A", CgPrice: -1}, true},
+		{"t4", UpsertProductRequest{SKU: "A001", ProductName: "A", IsCombo: true}, true},
+		{"t5", UpsertProductRequest{SKU: "A001", ProductName: "A", IsCombo: true, GroupList: []UpsertProductComboItem{{SKU: "B001", Quantity: 0}}}, true},
+		{"t6", UpsertProductRequest{SKU: "A001", ProductName: "A", IsCombo: true, GroupList: []UpsertProductComboItem{{SKU: "B001", Quantity: 2}}}, false},
+		{"t7", UpsertProductRequest{SKU: "A001", ProductName: "A", SupplierQuote: []UpsertProductAuxMaterialSupplierQuote{quote}}, false},
+		{"t8", UpsertProductRequest{SKU: "A001", ProductName: "A", SupplierQuote: []UpsertProductAuxMaterialSupplierQuote{badQuote}}, true},
+		{"t9", UpsertProductRequest{SKU: "A001", ProductName: "A", SupplierQuote: []UpsertProductAuxMaterialSupplierQuote{noSupplierQuote}}, true},
+		{"t10", UpsertProductRequest{SKU: "A001", ProductName: "A", SupplierQuote: []UpsertProductAuxMaterialSupplierQuote{noStepPriceQuote}}, true},
+		{"t11", UpsertProductRequest{SKU: "A001", ProductName: "A", SupplierQuote: []UpsertProductAuxMaterialSupplierQuote{badStepPriceQuote}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			assert.Equalf(t, tt.hasError, err != nil, "Validate(%s) error", jsonx.ToJson(tt.req, "{}"))
+		})
+	}
+}
+
+func TestProductServiceProduct_Upsert(t *testing.T) {
+	req := UpsertProductRequest{
+		SKU:         "TEST-SKU-001",
+		ProductName: "Test Product",
+		Status:      ProductStatusDeveloping,
+		CgPrice:     10,
+	}
+	id, err := lingXingClient.Services.Product.Upsert(req)
+	if err != nil {
+		t.Errorf("Services.Product.Upsert() error: %s", err.Error())
+	} else {
+		t.Logf("Product ID: %d", id)
+	}
+}
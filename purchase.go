@@ -13,58 +13,65 @@ type purchaseService service
 
 // PurchasePlan 采购计划
 type PurchasePlan struct {
-	PlanSN               string   `json:"plan_sn"`                 // 采购计划编号
-	StatusText           string   `json:"status_text"`             // 状态
-	Status               int      `json:"status"`                  // 计划状态码
-	CreatorRealName      string   `json:"creator_real_name"`       // 创建人名称
-	CreatorUID           int      `json:"creator_uid"`             // 创建人 ID
-	CreateTime           string   `json:"create_time"`             // 创建时间 Y-m-d H:i:s
-	File                 []string `json:"file"`                    // 附件
-	PlanRemark           string   `json:"plan_remark"`             // 备注
-	PicUrl               string   `json:"pic_url"`                 // 产品图片
-	SpuName              string   `json:"spu_name"`                // 款名
-	Spu                  string   `json:"spu"`                     // SPU
-	ProductName          string   `json:"product_name"`            // 品名
-	ProductId            int      `json:"product_id"`              // 商品 ID
-	SKU                  string   `json:"sku"`                     // SKU
-	Attribute            []string `json:"attribute"`               // 属性
-	SID                  int      `json:"sid"`                     // 店铺 ID
-	SellerName           string   `json:"seller_name"`             // 店铺名称
-	Marketplace          string   `json:"marketplace"`             // 国家
-	FNSKU                string   `json:"fnsku"`                   // FNSKU
-	MSKU                 []string `json:"msku"`                    // MSKU
-	SupplierId           string   `json:"supplier_id"`             // 供应商 ID
-	SupplierName         string   `json:"supplier_name"`           // 供应商名称
-	WID                  int      `json:"wid"`                     // 仓库 ID
-	WarehouseName        string   `json:"warehouse_name"`          // 仓库名称
-	PurchaserId          int      `json:"purchaser_id"`            // 采购方 ID
-	PurchaserName        string   `json:"purchaser_name"`          // 采购方名称
-	CgBoxPcs             int      `json:"cg_box_pcs"`              // 单箱数量
-	QuantityPlan         int      `json:"quantity_plan"`           // 计划采购量
-	ExpectArriveTime     string   `json:"expect_arrive_time"`      // 期望到货时间（Y-m-d）
-	CgUID                int      `json:"cg_uid"`                  // 采购员 ID
-	CgOptUsername        string   `json:"cg_opt_username"`         // 采购员名称
-	Remark               string   `json:"remark"`                  // 产品备注
-	IsCombo              bool     `json:"is_combo"`                // 是否为组合商品（0：否、1：是）
-	IsAux                bool     `json:"is_aux"`                  // 是否为辅料（0：否、1：是）
-	IsRelatedProcessPlan bool     `json:"is_related_process_plan"` // 是否关联了加工计划（0：否、1：是）
+	PlanSN               string             `json:"plan_sn"`                 // 采购计划编号
+	StatusText           string             `json:"status_text"`             // 状态
+	Status               PurchasePlanStatus `json:"status"`                  // 计划状态码
+	CreatorRealName      string             `json:"creator_real_name"`       // 创建人名称
+	CreatorUID           int                `json:"creator_uid"`             // 创建人 ID
+	CreateTime           string             `json:"create_time"`             // 创建时间 Y-m-d H:i:s
+	File                 []string           `json:"file"`                    // 附件
+	PlanRemark           string             `json:"plan_remark"`             // 备注
+	PicUrl               string             `json:"pic_url"`                 // 产品图片
+	SpuName              string             `json:"spu_name"`                // 款名
+	Spu                  string             `json:"spu"`                     // SPU
+	ProductName          string             `json:"product_name"`            // 品名
+	ProductId            int                `json:"product_id"`              // 商品 ID
+	SKU                  string             `json:"sku"`                     // SKU
+	Attribute            []string           `json:"attribute"`               // 属性
+	SID                  int                `json:"sid"`                     // 店铺 ID
+	SellerName           string             `json:"seller_name"`             // 店铺名称
+	Marketplace          string             `json:"marketplace"`             // 国家
+	FNSKU                string             `json:"fnsku"`                   // FNSKU
+	MSKU                 []string           `json:"msku"`                    // MSKU
+	SupplierId           int                `json:"supplier_id"`             // 供应商 ID（接口返回字符串，与采购单、供应商统一为整数）
+	SupplierName         string             `json:"supplier_name"`           // 供应商名称
+	WID                  int                `json:"wid"`                     // 仓库 ID
+	WarehouseName        string             `json:"warehouse_name"`          // 仓库名称
+	PurchaserId          int                `json:"purchaser_id"`            // 采购方 ID
+	PurchaserName        string             `json:"purchaser_name"`          // 采购方名称
+	CgBoxPcs             int                `json:"cg_box_pcs"`              // 单箱数量
+	QuantityPlan         int                `json:"quantity_plan"`           // 计划采购量
+	ExpectArriveTime     string             `json:"expect_arrive_time"`      // 期望到货时间（Y-m-d）
+	CgUID                int                `json:"cg_uid"`                  // 采购员 ID
+	CgOptUsername        string             `json:"cg_opt_username"`         // 采购员名称
+	Remark               string             `json:"remark"`                  // 产品备注
+	IsCombo              bool               `json:"is_combo"`                // 是否为组合商品（0：否、1：是）
+	IsAux                bool               `json:"is_aux"`                  // 是否为辅料（0：否、1：是）
+	IsRelatedProcessPlan bool               `json:"is_related_process_plan"` // 是否关联了加工计划（0：否、1：是）
 }
 
 type PurchasePlansQueryParams struct {
 	Paging
-	SearchFieldTime      string   `json:"search_field_time,omitempty"`       // 时间搜索维度（creator_time：创建时间、expect_arrive_time：预计到货时间）
-	StartDate            string   `json:"start_date"`                        // 开始日期（Y-m-d，闭区间）
-	EndDate              string   `json:"end_date"`                          // 结束日期（Y-m-d，开区间）
-	PlanSNs              []string `json:"plan_sns,omitempty"`                // 采购计划编号
-	IsCombo              bool     `json:"is_combo,omitempty"`                // 是否为组合商品（0：否、1：是）
-	IsRelatedProcessPlan bool     `json:"is_related_process_plan,omitempty"` // 是否关联加工计划（0：否、1：是）
-	Status               []int    `json:"status,omitempty"`                  // 状态（待采购：2、已处理：-2、已驳回：122、已作废：-3、124：?）
-	SIDs                 []int    `json:"sids,omitempty"`                    // 店铺
+	SearchFieldTime      string               `json:"search_field_time,omitempty"`       // 时间搜索维度（creator_time：创建时间、expect_arrive_time：预计到货时间）
+	StartDate            string               `json:"start_date"`                        // 开始日期（Y-m-d，闭区间）
+	EndDate              string               `json:"end_date"`                          // 结束日期（Y-m-d，开区间）
+	PlanSNs              []string             `json:"plan_sns,omitempty"`                // 采购计划编号
+	IsCombo              bool                 `json:"is_combo,omitempty"`                // 是否为组合商品（0：否、1：是）
+	IsRelatedProcessPlan bool                 `json:"is_related_process_plan,omitempty"` // 是否关联加工计划（0：否、1：是）
+	Status               []PurchasePlanStatus `json:"status,omitempty"`                  // 状态（待采购：2、已处理：-2、已驳回：122、已作废：-3、待审批：124）
+	SIDs                 []int                `json:"sids,omitempty"`                    // 店铺
 }
 
 func (m PurchasePlansQueryParams) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.SearchFieldTime, validation.When(m.SearchFieldTime != "", validation.In("creator_time", "expect_arrive_time").Error("时间搜索维度有误"))),
+		validation.Field(&m.Status, validation.Each(validation.In(
+			PurchasePlanStatusPendingPurchase,
+			PurchasePlanStatusProcessed,
+			PurchasePlanStatusRejected,
+			PurchasePlanStatusVoided,
+			PurchasePlanStatusPendingApproval,
+		).Error("无效的采购计划状态"))),
 		validation.Field(&m.StartDate,
 			validation.Required.Error("开始时间不能为空"),
 			validation.Date(constant.DateFormat).Error("开始时间格式有误"),
@@ -119,6 +126,183 @@ func (s purchaseService) Plans(params PurchasePlansQueryParams) (items []Purchas
 	return
 }
 
+// 创建采购计划
+
+// CreatePurchasePlanItem 采购计划产品
+type CreatePurchasePlanItem struct {
+	SKU              string `json:"sku"`                          // SKU
+	SID              int    `json:"sid,omitempty"`                // 店铺 ID
+	FNSKU            string `json:"fnsku,omitempty"`              // FNSKU
+	WID              int    `json:"wid"`                          // 仓库 ID
+	SupplierId       int    `json:"supplier_id,omitempty"`        // 供应商 ID
+	PurchaserId      int    `json:"purchaser_id,omitempty"`       // 采购方 ID
+	CgUID            int    `json:"cg_uid,omitempty"`             // 采购员 ID
+	QuantityPlan     int    `json:"quantity_plan"`                // 计划采购量
+	ExpectArriveTime string `json:"expect_arrive_time,omitempty"` // 期望到货时间（Y-m-d）
+	Remark           string `json:"remark,omitempty"`             // 产品备注
+}
+
+func (m CreatePurchasePlanItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("SKU 不能为空")),
+		validation.Field(&m.WID, validation.Required.Error("仓库不能为空")),
+		validation.Field(&m.QuantityPlan,
+			validation.Required.Error("计划采购量不能为空"),
+			validation.Min(1).Error("计划采购量不能小于 {{.threshold}}"),
+		),
+		validation.Field(&m.ExpectArriveTime, validation.When(m.ExpectArriveTime != "", validation.Date(constant.DateFormat).Error("期望到货时间格式有误"))),
+	)
+}
+
+type CreatePurchasePlanRequest struct {
+	PlanRemark string                   `json:"plan_remark,omitempty"` // 备注
+	File       []string                 `json:"file,omitempty"`        // 附件
+	Items      []CreatePurchasePlanItem `json:"product_list"`          // 采购产品
+}
+
+func (m CreatePurchasePlanRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Items, validation.Required.Error("采购产品不能为空")),
+	)
+}
+
+// CreatePlan 创建采购计划，返回采购计划编号
+func (s purchaseService) CreatePlan(req CreatePurchasePlanRequest) (planSNs []string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			PlanSNs []string `json:"plan_sns"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchasePlan/create")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		planSNs = res.Data.PlanSNs
+	}
+	return
+}
+
+// 采购计划状态变更
+
+// purchasePlanStatusTransitions 采购计划状态允许变更的目标状态
+var purchasePlanStatusTransitions = statusTransitions[PurchasePlanStatus]{
+	PurchasePlanStatusPendingApproval: {PurchasePlanStatusPendingPurchase, PurchasePlanStatusRejected, PurchasePlanStatusVoided},
+	PurchasePlanStatusPendingPurchase: {PurchasePlanStatusVoided},
+	PurchasePlanStatusRejected:        {PurchasePlanStatusVoided},
+}
+
+// CanChangePurchasePlanStatus 判断采购计划是否可以从 from 状态变更为 to 状态
+// 已处理、已作废的采购计划不能再变更状态，待采购的采购计划转为采购单后变为已处理
+func CanChangePurchasePlanStatus(from, to PurchasePlanStatus) bool {
+	return purchasePlanStatusTransitions.can(from, to)
+}
+
+type PurchasePlanStatusRequest struct {
+	PlanSNs []string           `json:"plan_sns"` // 采购计划编号
+	Status  PurchasePlanStatus `json:"status"`   // 目标状态（2：待采购（审批通过）、122：已驳回、-3：已作废）
+	Remark  string             `json:"remark"`   // 备注（驳回、作废原因）
+}
+
+func (m PurchasePlanStatusRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.PlanSNs, validation.Required.Error("采购计划编号不能为空")),
+		validation.Field(&m.Status,
+			validation.Required.Error("目标状态不能为空"),
+			validation.In(PurchasePlanStatusPendingPurchase, PurchasePlanStatusRejected, PurchasePlanStatusVoided).Error("无效的目标状态"),
+		),
+		validation.Field(&m.Remark, validation.When(m.Status != PurchasePlanStatusPendingPurchase, validation.Required.Error("原因不能为空"))),
+	)
+}
+
+// ChangePlanStatus 检查采购计划是否可以变更为 status 状态后变更采购计划状态
+func (s purchaseService) ChangePlanStatus(status PurchasePlanStatus, reason string, plans ...PurchasePlan) (err error) {
+	req := PurchasePlanStatusRequest{
+		PlanSNs: make([]string, len(plans)),
+		Status:  status,
+		Remark:  reason,
+	}
+	for i, plan := range plans {
+		if !CanChangePurchasePlanStatus(plan.Status, status) {
+			return fmt.Errorf("lingxing: 采购计划 %s 不能从%s变更为%s", plan.PlanSN, plan.Status, status)
+		}
+		req.PlanSNs[i] = plan.PlanSN
+	}
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchasePlan/changeStatus")
+	return
+}
+
+// ApprovePlans 审批通过采购计划（待审批 -> 待采购）
+func (s purchaseService) ApprovePlans(plans ...PurchasePlan) error {
+	return s.ChangePlanStatus(PurchasePlanStatusPendingPurchase, "", plans...)
+}
+
+// RejectPlans 驳回采购计划（待审批 -> 已驳回）
+func (s purchaseService) RejectPlans(reason string, plans ...PurchasePlan) error {
+	return s.ChangePlanStatus(PurchasePlanStatusRejected, reason, plans...)
+}
+
+// VoidPlans 作废采购计划（待审批、待采购、已驳回 -> 已作废）
+func (s purchaseService) VoidPlans(reason string, plans ...PurchasePlan) error {
+	return s.ChangePlanStatus(PurchasePlanStatusVoided, reason, plans...)
+}
+
+// 采购计划转采购单
+
+type PurchasePlansToOrdersRequest struct {
+	PlanSNs     []string `json:"plan_sns"`               // 采购计划编号（仅待采购状态的采购计划可以转为采购单）
+	SupplierId  int      `json:"supplier_id,omitempty"`  // 供应商 ID（为空时使用采购计划中的供应商）
+	WID         int      `json:"wid,omitempty"`          // 仓库 ID（为空时使用采购计划中的仓库）
+	PurchaserId int      `json:"purchaser_id,omitempty"` // 采购方 ID
+	Remark      string   `json:"remark,omitempty"`       // 采购单备注
+}
+
+func (m PurchasePlansToOrdersRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.PlanSNs, validation.Required.Error("采购计划编号不能为空")),
+	)
+}
+
+// PlansToOrders 采购计划转采购单，返回生成的采购单号
+// 采购计划按照供应商、仓库、采购方合并生成采购单，转单成功后采购计划状态变为已处理
+func (s purchaseService) PlansToOrders(req PurchasePlansToOrdersRequest) (orderSNs []string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			OrderSNs []string `json:"order_sns"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchasePlan/transformPurchaseOrder")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		orderSNs = res.Data.OrderSNs
+	}
+	return
+}
+
 // 采购单处理
 
 // PurchaseOrderItem 采购单子项
@@ -151,6 +335,34 @@ type PurchaseOrderItem struct {
 	SPUName           string   `json:"spu_name"`            // 款名
 }
 
+// 采购单子项到货状态
+const (
+	PurchaseOrderItemNotReceived       = 1 // 未到货
+	PurchaseOrderItemPartiallyReceived = 2 // 部分到货
+	PurchaseOrderItemFullyReceived     = 3 // 全部到货
+)
+
+// ArrivedQuantity 已到货量（实际采购量 - 待到货量）
+func (m PurchaseOrderItem) ArrivedQuantity() int {
+	n := m.QuantityReal - m.QuantityReceive
+	if n < 0 {
+		n = 0
+	}
+	return n
+}
+
+// ReceivingStatus 到货状态（1：未到货、2：部分到货、3：全部到货），与采购单的 StatusShipped 取值一致
+func (m PurchaseOrderItem) ReceivingStatus() int {
+	arrived := m.ArrivedQuantity()
+	if arrived == 0 && m.QuantityEntry == 0 {
+		return PurchaseOrderItemNotReceived
+	}
+	if m.QuantityReceive <= 0 {
+		return PurchaseOrderItemFullyReceived
+	}
+	return PurchaseOrderItemPartiallyReceived
+}
+
 // PurchaseOrderLogisticsInformation 物流信息
 type PurchaseOrderLogisticsInformation struct {
 	LogisticsCompany string `json:"logistics_company"`  // 物流公司
@@ -181,7 +393,7 @@ type PurchaseOrder struct {
 	Reason                string                              `json:"reason"`                 // 作废原因
 	WID                   int                                 `json:"wid"`                    // 仓库 ID
 	IsTax                 bool                                `json:"is_tax"`                 // 是否含税（0：否、1：是）
-	Status                int                                 `json:"status"`                 // 状态（-1：作废、0：待审核 - 草稿、1：待下单 - 已审核、2：待签收(待到货) - 已下单、9：完成、121：(审批流)待审核、122：(审批流)驳回、124：(审批流)作废）
+	Status                PurchaseOrderStatus                 `json:"status"`                 // 状态（-1：作废、0：待审核 - 草稿、1：待下单 - 已审核、2：待签收(待到货) - 已下单、9：完成、121：(审批流)待审核、122：(审批流)驳回、124：(审批流)作废）
 	WareHouseBakName      string                              `json:"ware_house_bak_name"`    // 仓库名(备份)
 	StatusText            string                              `json:"status_text"`            // 状态文本
 	PayStatusText         string                              `json:"pay_status_text"`        // 支付状态文本
@@ -274,3 +486,251 @@ func (s purchaseService) Orders(params PurchaseOrdersQueryParams) (items []Purch
 	}
 	return
 }
+
+// 创建采购单
+
+// PurchaseOrderItemRequest 采购单子项
+type PurchaseOrderItemRequest struct {
+	ID               string  `json:"id,omitempty"`                 // 子项 ID（编辑已有子项时必填）
+	PlanSN           string  `json:"plan_sn,omitempty"`            // 采购计划号
+	SKU              string  `json:"sku"`                          // SKU
+	FNSKU            string  `json:"fnsku,omitempty"`              // FNSKU
+	SID              int     `json:"sid,omitempty"`                // 店铺 ID
+	Price            float64 `json:"price"`                        // 含税单价
+	TaxRate          float64 `json:"tax_rate,omitempty"`           // 税率（百分比）
+	QuantityReal     int     `json:"quantity_real"`                // 实际采购量
+	ExpectArriveTime string  `json:"expect_arrive_time,omitempty"` // 期待到货时间（Y-m-d）
+	Remark           string  `json:"remark,omitempty"`             // 备注
+}
+
+func (m PurchaseOrderItemRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("SKU 不能为空")),
+		validation.Field(&m.Price, validation.Min(0.0).Error("含税单价不能小于 {{.threshold}}")),
+		validation.Field(&m.TaxRate,
+			validation.Min(0.0).Error("税率不能小于 {{.threshold}}"),
+			validation.Max(100.0).Error("税率不能大于 {{.threshold}}"),
+		),
+		validation.Field(&m.QuantityReal,
+			validation.Required.Error("实际采购量不能为空"),
+			validation.Min(1).Error("实际采购量不能小于 {{.threshold}}"),
+		),
+		validation.Field(&m.ExpectArriveTime, validation.When(m.ExpectArriveTime != "", validation.Date(constant.DateFormat).Error("期待到货时间格式有误"))),
+	)
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierId       int                        `json:"supplier_id"`                 // 供应商 ID
+	WID              int                        `json:"wid"`                         // 仓库 ID
+	PurchaserId      int                        `json:"purchaser_id,omitempty"`      // 采购方 ID
+	PurchaseCurrency string                     `json:"purchase_currency,omitempty"` // 采购币种
+	ShippingPrice    float64                    `json:"shipping_price,omitempty"`    // 运费
+	ShippingCurrency string                     `json:"shipping_currency,omitempty"` // 运费币种
+	OtherFee         float64                    `json:"other_fee,omitempty"`         // 其他费用
+	OtherCurrency    string                     `json:"other_currency,omitempty"`    // 其他费用币种
+	FeePartType      int                        `json:"fee_part_type,omitempty"`     // 费用分摊方式（0：不分摊、1：按金额、2：按数量）
+	Remark           string                     `json:"remark,omitempty"`            // 备注
+	Items            []PurchaseOrderItemRequest `json:"item_list"`                   // 采购单子项
+}
+
+func (m CreatePurchaseOrderRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SupplierId, validation.Required.Error("供应商不能为空")),
+		validation.Field(&m.WID, validation.Required.Error("仓库不能为空")),
+		validation.Field(&m.ShippingPrice, validation.Min(0.0).Error("运费不能小于 {{.threshold}}")),
+		validation.Field(&m.OtherFee, validation.Min(0.0).Error("其他费用不能小于 {{.threshold}}")),
+		validation.Field(&m.FeePartType, validation.In(FeePartTypeNone, FeePartTypeAmount, FeePartTypeQuantity).Error("无效的费用分摊方式")),
+		validation.Field(&m.Items, validation.Required.Error("采购单子项不能为空")),
+	)
+}
+
+// CreateOrder 创建采购单，返回采购单号
+// 子项中设置了采购计划号时，将根据采购计划创建采购单
+func (s purchaseService) CreateOrder(req CreatePurchaseOrderRequest) (orderSN string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			OrderSN string `json:"order_sn"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchaseOrder/create")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		orderSN = res.Data.OrderSN
+	}
+	return
+}
+
+// 编辑采购单
+
+type UpdatePurchaseOrderRequest struct {
+	OrderSN string `json:"order_sn"` // 采购单号
+	CreatePurchaseOrderRequest
+}
+
+func (m UpdatePurchaseOrderRequest) Validate() error {
+	if err := validation.ValidateStruct(&m,
+		validation.Field(&m.OrderSN, validation.Required.Error("采购单号不能为空")),
+	); err != nil {
+		return err
+	}
+	return m.CreatePurchaseOrderRequest.Validate()
+}
+
+// UpdateOrder 编辑采购单（仅待审核、待下单状态的采购单可以编辑），未提交的子项将被删除
+func (s purchaseService) UpdateOrder(req UpdatePurchaseOrderRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchaseOrder/edit")
+	return
+}
+
+// 作废采购单
+
+type CancelPurchaseOrdersRequest struct {
+	OrderSNs []string `json:"order_sns"` // 采购单号
+	Reason   string   `json:"reason"`    // 作废原因
+}
+
+func (m CancelPurchaseOrdersRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderSNs, validation.Required.Error("采购单号不能为空")),
+		validation.Field(&m.Reason, validation.Required.Error("作废原因不能为空")),
+	)
+}
+
+// CancelOrders 作废采购单
+func (s purchaseService) CancelOrders(reason string, orderSNs ...string) (err error) {
+	req := CancelPurchaseOrdersRequest{
+		OrderSNs: orderSNs,
+		Reason:   reason,
+	}
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchaseOrder/cancel")
+	return
+}
+
+// 添加采购单物流信息
+
+type PurchaseOrderLogisticsItem struct {
+	LogisticsCompany string `json:"logistics_company"`  // 物流公司
+	LogisticsOrderNo string `json:"logistics_order_no"` // 物流单号
+}
+
+func (m PurchaseOrderLogisticsItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.LogisticsCompany, validation.Required.Error("物流公司不能为空")),
+		validation.Field(&m.LogisticsOrderNo, validation.Required.Error("物流单号不能为空")),
+	)
+}
+
+type PurchaseOrderLogisticsRequest struct {
+	OrderSN       string                       `json:"purchase_order_sn"` // 采购单号
+	LogisticsInfo []PurchaseOrderLogisticsItem `json:"logistics_info"`    // 物流信息
+}
+
+func (m PurchaseOrderLogisticsRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderSN, validation.Required.Error("采购单号不能为空")),
+		validation.Field(&m.LogisticsInfo, validation.Required.Error("物流信息不能为空")),
+	)
+}
+
+// AddOrderLogistics 添加采购单物流信息
+func (s purchaseService) AddOrderLogistics(req PurchaseOrderLogisticsRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchaseOrder/addLogistics")
+	return
+}
+
+// 采购单到货
+
+type PurchaseOrderArrivalItem struct {
+	ID       string `json:"id"`       // 采购单子项 ID
+	Quantity int    `json:"quantity"` // 到货量
+}
+
+func (m PurchaseOrderArrivalItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.ID, validation.Required.Error("采购单子项 ID 不能为空")),
+		validation.Field(&m.Quantity,
+			validation.Required.Error("到货量不能为空"),
+			validation.Min(1).Error("到货量不能小于 {{.threshold}}"),
+		),
+	)
+}
+
+type PurchaseOrderArrivalRequest struct {
+	OrderSN string                     `json:"order_sn"`         // 采购单号
+	Items   []PurchaseOrderArrivalItem `json:"item_list"`        // 到货子项
+	Remark  string                     `json:"remark,omitempty"` // 备注
+}
+
+func (m PurchaseOrderArrivalRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderSN, validation.Required.Error("采购单号不能为空")),
+		validation.Field(&m.Items, validation.Required.Error("到货子项不能为空")),
+	)
+}
+
+// ValidateWith 根据采购单校验到货量，仅待签收的采购单可以登记到货，到货量不能超过子项的待到货量
+func (m PurchaseOrderArrivalRequest) ValidateWith(order PurchaseOrder) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if m.OrderSN != order.OrderSN {
+		return fmt.Errorf("采购单号 %s 与 %s 不一致", m.OrderSN, order.OrderSN)
+	}
+	if order.Status != PurchaseOrderStatusPendingArrival {
+		return fmt.Errorf("采购单 %s 的状态为%s，不能登记到货", order.OrderSN, order.Status)
+	}
+	items := make(map[string]PurchaseOrderItem, len(order.ItemList))
+	for _, item := range order.ItemList {
+		items[item.ID] = item
+	}
+	for _, arrival := range m.Items {
+		item, ok := items[arrival.ID]
+		if !ok || item.IsDelete {
+			return fmt.Errorf("采购单子项 %s 不存在", arrival.ID)
+		}
+		if arrival.Quantity > item.QuantityReceive {
+			return fmt.Errorf("%s 到货量 %d 不能大于待到货量 %d", item.SKU, arrival.Quantity, item.QuantityReceive)
+		}
+	}
+	return nil
+}
+
+// RegisterOrderArrival 采购单到货登记，order 为到货登记的采购单，用于校验采购单状态和待到货量
+func (s purchaseService) RegisterOrderArrival(order PurchaseOrder, req PurchaseOrderArrivalRequest) (err error) {
+	if err = req.ValidateWith(order); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/purchase/purchaseOrder/arrival")
+	return
+}
@@ -0,0 +1,306 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/lingxing/constant"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 采购三方对账
+// 以采购单为基准，根据采购单号关联采购入库单，根据 SKU 和供应商关联供应商报价，核对以下内容：
+// 1. 数量：实际采购量与入库量（良品 + 次品）的差异
+// 2. 价格：采购单含税单价与供应商报价中对应阶梯的含税单价的差异
+// 3. 质量：各供应商入库的次品率
+// 4. 无法关联到采购单的采购入库单
+//
+// 已作废的采购单、已删除的采购单子项和未完成（待提交、待审批、待入库、已撤销）的入库单不参与对账
+
+// PurchaseReconciliationLine 对账明细（按采购单 + SKU 汇总）
+type PurchaseReconciliationLine struct {
+	OrderSN           string  `json:"order_sn"`            // 采购单号
+	SupplierId        int     `json:"supplier_id"`         // 供应商 ID
+	SupplierName      string  `json:"supplier_name"`       // 供应商名称
+	Currency          string  `json:"currency"`            // 采购币种
+	SKU               string  `json:"sku"`                 // SKU
+	ProductName       string  `json:"product_name"`        // 品名
+	QuantityReal      int     `json:"quantity_real"`       // 实际采购量
+	Amount            float64 `json:"amount"`              // 价税合计
+	Price             float64 `json:"price"`               // 含税单价（价税合计 / 实际采购量）
+	QuantityGood      int     `json:"quantity_good"`       // 入库良品量
+	QuantityBad       int     `json:"quantity_bad"`        // 入库次品量
+	InboundAmount     float64 `json:"inbound_amount"`      // 入库成本
+	ShortQuantity     int     `json:"short_quantity"`      // 短缺量（实际采购量 - 入库量，负数表示超收）
+	HasQuote          bool    `json:"has_quote"`           // 是否存在供应商报价
+	QuotedPrice       float64 `json:"quoted_price"`        // 报价含税单价
+	PriceVariance     float64 `json:"price_variance"`      // 价格差异（含税单价 - 报价含税单价）
+	PriceVarianceRate float64 `json:"price_variance_rate"` // 价格差异率（价格差异 / 报价含税单价）
+}
+
+// PurchaseSupplierQuality 供应商质量
+type PurchaseSupplierQuality struct {
+	SupplierId   int     `json:"supplier_id"`   // 供应商 ID
+	SupplierName string  `json:"supplier_name"` // 供应商名称
+	QuantityGood int     `json:"quantity_good"` // 入库良品量
+	QuantityBad  int     `json:"quantity_bad"`  // 入库次品量
+	DefectRate   float64 `json:"defect_rate"`   // 次品率
+}
+
+// PurchaseReconciliationReport 对账报告
+type PurchaseReconciliationReport struct {
+	Lines               []PurchaseReconciliationLine    `json:"lines"`                 // 对账明细
+	Shortfalls          []PurchaseReconciliationLine    `json:"shortfalls"`            // 数量短缺明细
+	PriceVariances      []PurchaseReconciliationLine    `json:"price_variances"`       // 价格差异超出容差的明细
+	Suppliers           map[int]PurchaseSupplierQuality `json:"suppliers"`             // 供应商质量
+	OrphanInboundOrders []InboundOrder                  `json:"orphan_inbound_orders"` // 无法关联采购单的采购入库单
+}
+
+// PurchaseReconciliation 采购三方对账
+type PurchaseReconciliation struct {
+	priceTolerance float64                    // 价格差异容差（比例，如 0.01 表示 1%）
+	orders         []PurchaseOrder            // 采购单
+	inboundOrders  []InboundOrder             // 入库单
+	quotes         map[string][]SupplierQuote // 供应商报价（以大写的 SKU 为键）
+}
+
+// NewPurchaseReconciliation 创建采购三方对账，priceTolerance 为价格差异率的容差
+func NewPurchaseReconciliation(priceTolerance float64) *PurchaseReconciliation {
+	return &PurchaseReconciliation{
+		priceTolerance: math.Abs(priceTolerance),
+		orders:         make([]PurchaseOrder, 0),
+		inboundOrders:  make([]InboundOrder, 0),
+		quotes:         make(map[string][]SupplierQuote),
+	}
+}
+
+// AddPurchaseOrders 添加采购单
+func (r *PurchaseReconciliation) AddPurchaseOrders(orders ...PurchaseOrder) {
+	r.orders = append(r.orders, orders...)
+}
+
+// AddInboundOrders 添加入库单
+func (r *PurchaseReconciliation) AddInboundOrders(orders ...InboundOrder) {
+	r.inboundOrders = append(r.inboundOrders, orders...)
+}
+
+// AddProducts 添加本地产品，用于获取供应商报价
+func (r *PurchaseReconciliation) AddProducts(products ...Product) {
+	for _, product := range products {
+		if product.SKU != "" {
+			r.quotes[strings.ToUpper(product.SKU)] = product.SupplierQuote
+		}
+	}
+}
+
+// purchaseOrderCurrency 采购币种，未设置时为人民币
+func purchaseOrderCurrency(order PurchaseOrder) string {
+	if order.PurchaseCurrency == "" {
+		return constant.CNY
+	}
+	return order.PurchaseCurrency
+}
+
+// quotedPrice 获取供应商报价中适用于 quantity 的含税单价（最小采购量不超过 quantity 的最高阶梯，不存在时取最低阶梯）
+func (r *PurchaseReconciliation) quotedPrice(sku string, supplierId int, currency string, quantity int) (price float64, exists bool) {
+	for _, quote := range r.quotes[strings.ToUpper(sku)] {
+		if quote.SupplierId != supplierId {
+			continue
+		}
+		for _, item := range quote.Quotes {
+			if !strings.EqualFold(item.Currency, currency) || len(item.StepPrices) == 0 {
+				continue
+			}
+			steps := make([]SupplierQuoteItemStepPrice, len(item.StepPrices))
+			copy(steps, item.StepPrices)
+			sort.Slice(steps, func(i, j int) bool {
+				return steps[i].Moq < steps[j].Moq
+			})
+			step := steps[0]
+			for _, s := range steps {
+				if s.Moq <= quantity {
+					step = s
+				}
+			}
+			return step.PriceWithTax, true
+		}
+	}
+	return
+}
+
+// Reconcile 对账
+func (r *PurchaseReconciliation) Reconcile() PurchaseReconciliationReport {
+	report := PurchaseReconciliationReport{
+		Lines:               make([]PurchaseReconciliationLine, 0),
+		Shortfalls:          make([]PurchaseReconciliationLine, 0),
+		PriceVariances:      make([]PurchaseReconciliationLine, 0),
+		Suppliers:           make(map[int]PurchaseSupplierQuality),
+		OrphanInboundOrders: make([]InboundOrder, 0),
+	}
+
+	lineKey := func(orderSN, sku string) string {
+		return orderSN + "\x00" + strings.ToUpper(sku)
+	}
+	lines := make(map[string]*PurchaseReconciliationLine)
+	keys := make([]string, 0)
+	orders := make(map[string]PurchaseOrder, len(r.orders))
+	for _, order := range r.orders {
+		if order.Status == PurchaseOrderStatusVoided {
+			continue
+		}
+		orders[order.OrderSN] = order
+		currency := purchaseOrderCurrency(order)
+		for _, item := range order.ItemList {
+			if item.IsDelete {
+				continue
+			}
+			key := lineKey(order.OrderSN, item.SKU)
+			line, ok := lines[key]
+			if !ok {
+				line = &PurchaseReconciliationLine{
+					OrderSN:      order.OrderSN,
+					SupplierId:   order.SupplierId,
+					SupplierName: order.SupplierName,
+					Currency:     currency,
+					SKU:          item.SKU,
+					ProductName:  item.ProductName,
+				}
+				lines[key] = line
+				keys = append(keys, key)
+			}
+			line.QuantityReal += item.QuantityReal
+			amount := item.Amount
+			if amount == 0 {
+				amount = item.Price * float64(item.QuantityReal)
+			}
+			line.Amount += amount
+		}
+	}
+
+	for _, inboundOrder := range r.inboundOrders {
+		if inboundOrder.Status != InboundOrderStatusCompleted {
+			continue
+		}
+		order, ok := orders[inboundOrder.PurchaseOrderSN]
+		if !ok {
+			if inboundOrder.PurchaseOrderSN != "" || inboundOrder.Type == InboundOrderTypePurchase {
+				report.OrphanInboundOrders = append(report.OrphanInboundOrders, inboundOrder)
+			}
+			continue
+		}
+		for _, item := range inboundOrder.ItemList {
+			key := lineKey(order.OrderSN, item.SKU)
+			line, ok := lines[key]
+			if !ok {
+				// 采购单中不存在的 SKU
+				line = &PurchaseReconciliationLine{
+					OrderSN:      order.OrderSN,
+					SupplierId:   order.SupplierId,
+					SupplierName: order.SupplierName,
+					Currency:     purchaseOrderCurrency(order),
+					SKU:          item.SKU,
+					ProductName:  item.ProductName,
+				}
+				lines[key] = line
+				keys = append(keys, key)
+			}
+			line.QuantityGood += item.ProductGoodNum
+			line.QuantityBad += item.ProductBadNum
+			line.InboundAmount += item.Amount
+		}
+	}
+
+	for _, key := range keys {
+		line := lines[key]
+		line.ShortQuantity = line.QuantityReal - line.QuantityGood - line.QuantityBad
+		if line.QuantityReal > 0 {
+			line.Price = line.Amount / float64(line.QuantityReal)
+			line.QuotedPrice, line.HasQuote = r.quotedPrice(line.SKU, line.SupplierId, line.Currency, line.QuantityReal)
+		}
+		if line.HasQuote {
+			line.PriceVariance = line.Price - line.QuotedPrice
+			if line.QuotedPrice != 0 {
+				line.PriceVarianceRate = line.PriceVariance / line.QuotedPrice
+			}
+		}
+		report.Lines = append(report.Lines, *line)
+		if line.ShortQuantity > 0 {
+			report.Shortfalls = append(report.Shortfalls, *line)
+		}
+		if line.HasQuote && line.PriceVariance != 0 && (line.QuotedPrice == 0 || math.Abs(line.PriceVarianceRate) > r.priceTolerance) {
+			report.PriceVariances = append(report.PriceVariances, *line)
+		}
+
+		q := report.Suppliers[line.SupplierId]
+		q.SupplierId = line.SupplierId
+		q.SupplierName = line.SupplierName
+		q.QuantityGood += line.QuantityGood
+		q.QuantityBad += line.QuantityBad
+		report.Suppliers[line.SupplierId] = q
+	}
+	for id, q := range report.Suppliers {
+		if n := q.QuantityGood + q.QuantityBad; n > 0 {
+			q.DefectRate = float64(q.QuantityBad) / float64(n)
+		}
+		report.Suppliers[id] = q
+	}
+	return report
+}
+
+// Load 从接口加载指定日期范围（Y-m-d）内创建的采购单、入库的采购入库单，以及相关产品的供应商报价
+// 入库单关联的采购单创建时间早于 startDate 时，该入库单将被视为无法关联采购单，请根据采购周期适当提前 startDate
+func (r *PurchaseReconciliation) Load(client *LingXing, startDate, endDate string) error {
+	skus := make(map[string]string)
+	orderParams := PurchaseOrdersQueryParams{
+		SearchFieldTime: "create_time",
+		StartDate:       startDate,
+		EndDate:         endDate,
+	}
+	orders, err := queryAll(func(offset int) ([]PurchaseOrder, int, bool, error) {
+		orderParams.Offset = offset
+		return client.Services.Purchase.Orders(orderParams)
+	})
+	if err != nil {
+		return err
+	}
+	r.AddPurchaseOrders(orders...)
+	for _, order := range orders {
+		for _, item := range order.ItemList {
+			skus[strings.ToUpper(item.SKU)] = item.SKU
+		}
+	}
+
+	warehouses, err := client.Services.Warehouse.allWarehouses()
+	if err != nil {
+		return err
+	}
+	for _, warehouse := range warehouses {
+		inboundParams := InboundOrdersQueryParams{
+			WID:             strconv.Itoa(warehouse.WID),
+			SearchFieldTime: "opt_time",
+			StartDate:       startDate,
+			EndDate:         endDate,
+			Type:            InboundOrderTypePurchase,
+		}
+		inboundOrders, e := queryAll(func(offset int) ([]InboundOrder, int, bool, error) {
+			inboundParams.Offset = offset
+			return client.Services.Warehouse.InboundOrders(inboundParams)
+		})
+		if e != nil {
+			return e
+		}
+		r.AddInboundOrders(inboundOrders...)
+	}
+
+	values := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		values = append(values, sku)
+	}
+	sort.Strings(values)
+	products, err := client.Services.Product.BySKUs(values...)
+	if err != nil {
+		return err
+	}
+	r.AddProducts(products...)
+	return nil
+}
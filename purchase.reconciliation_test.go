@@ -0,0 +1,75 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPurchaseReconciliation_Reconcile(t *testing.T) {
+	r := NewPurchaseReconciliation(0.01)
+	r.AddProducts(Product{
+		SKU: "A",
+		SupplierQuote: []SupplierQuote{
+			{SupplierId: 1, Quotes: []SupplierQuoteItem{{Currency: "CNY", StepPrices: []SupplierQuoteItemStepPrice{{Moq: 100, PriceWithTax: 9}, {Moq: 1, PriceWithTax: 10}}}}},
+		},
+	}, Product{
+		SKU: "B",
+		SupplierQuote: []SupplierQuote{
+			{SupplierId: 2, Quotes: []SupplierQuoteItem{{Currency: "CNY", StepPrices: []SupplierQuoteItemStepPrice{{Moq: 1, PriceWithTax: 5}}}}},
+		},
+	})
+	r.AddPurchaseOrders(
+		PurchaseOrder{
+			OrderSN:      "PO1",
+			SupplierId:   1,
+			SupplierName: "S1",
+			Status:       PurchaseOrderStatusPendingArrival,
+			ItemList: []PurchaseOrderItem{
+				{SKU: "A", Price: 10, Amount: 1000, QuantityReal: 100},
+				{SKU: "X", Price: 1, QuantityReal: 10, IsDelete: true},
+			},
+		},
+		PurchaseOrder{
+			OrderSN:      "PO2",
+			SupplierId:   2,
+			SupplierName: "S2",
+			Status:       PurchaseOrderStatusCompleted,
+			ItemList:     []PurchaseOrderItem{{SKU: "B", Price: 5, QuantityReal: 20}},
+		},
+		PurchaseOrder{OrderSN: "PO3", Status: PurchaseOrderStatusVoided, ItemList: []PurchaseOrderItem{{SKU: "A", QuantityReal: 1}}},
+	)
+	r.AddInboundOrders(
+		InboundOrder{OrderSN: "IB1", PurchaseOrderSN: "PO1", Type: InboundOrderTypePurchase, Status: InboundOrderStatusCompleted, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductGoodNum: 80, ProductBadNum: 10}}},
+		InboundOrder{OrderSN: "IB2", PurchaseOrderSN: "PO1", Type: InboundOrderTypePurchase, Status: InboundOrderStatusRevoked, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductGoodNum: 10}}},
+		InboundOrder{OrderSN: "IB7", PurchaseOrderSN: "PO1", Type: InboundOrderTypePurchase, Status: InboundOrderStatusPendingInbound, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductGoodNum: 10}}},
+		InboundOrder{OrderSN: "IB3", PurchaseOrderSN: "PO2", Type: InboundOrderTypePurchase, Status: InboundOrderStatusCompleted, ItemList: []OutboundInboundOrderItem{{SKU: "B", ProductGoodNum: 20}}},
+		InboundOrder{OrderSN: "IB4", PurchaseOrderSN: "PO9", Type: InboundOrderTypePurchase, Status: InboundOrderStatusCompleted},
+		InboundOrder{OrderSN: "IB5", PurchaseOrderSN: "PO3", Type: InboundOrderTypePurchase, Status: InboundOrderStatusCompleted},
+		InboundOrder{OrderSN: "IB6", Type: InboundOrderTypeOther, Status: InboundOrderStatusCompleted},
+	)
+
+	report := r.Reconcile()
+	assert.Len(t, report.Lines, 2)
+	a := report.Lines[0]
+	assert.Equal(t, "A", a.SKU)
+	assert.Equal(t, 10, a.ShortQuantity)
+	assert.Equal(t, 10.0, a.Price)
+	assert.True(t, a.HasQuote)
+	assert.Equal(t, 9.0, a.QuotedPrice)
+	assert.InDelta(t, 1.0/9, a.PriceVarianceRate, 0.0001)
+
+	b := report.Lines[1]
+	assert.Equal(t, 0, b.ShortQuantity)
+	assert.Equal(t, 100.0, b.Amount)
+	assert.Equal(t, 0.0, b.PriceVariance)
+
+	assert.Len(t, report.Shortfalls, 1)
+	assert.Len(t, report.PriceVariances, 1)
+	assert.InDelta(t, 10.0/90, report.Suppliers[1].DefectRate, 0.0001)
+	assert.Equal(t, 0.0, report.Suppliers[2].DefectRate)
+	orphans := make([]string, 0)
+	for _, order := range report.OrphanInboundOrders {
+		orphans = append(orphans, order.OrderSN)
+	}
+	assert.Equal(t, []string{"IB4", "IB5"}, orphans)
+}
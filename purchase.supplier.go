@@ -0,0 +1,217 @@
+package lingxing
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// 供应商
+
+// 结算方式
+const (
+	SupplierSettlementMethodCashOnDelivery = 7  // 货到付款
+	SupplierSettlementMethodCashInAdvance  = 8  // 款到发货
+	SupplierSettlementMethodMonthly        = 9  // 月结
+	SupplierSettlementMethodSemiMonthly    = 10 // 半月结
+	SupplierSettlementMethodWeekly         = 11 // 周结
+	SupplierSettlementMethodOther          = 12 // 其他
+)
+
+// SupplierContact 供应商联系人
+type SupplierContact struct {
+	Name     string `json:"name"`     // 联系人
+	Phone    string `json:"phone"`    // 联系电话
+	Email    string `json:"email"`    // 邮箱
+	QQ       string `json:"qq"`       // QQ
+	WeChat   string `json:"wechat"`   // 微信
+	Position string `json:"position"` // 职位
+}
+
+func (m SupplierContact) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Name, validation.Required.Error("联系人不能为空")),
+		validation.Field(&m.Email, is.EmailFormat.Error("无效的邮箱")),
+	)
+}
+
+// Supplier 供应商
+type Supplier struct {
+	SupplierId       int               `json:"supplier_id"`       // 供应商 ID
+	SupplierName     string            `json:"supplier_name"`     // 供应商名称
+	SupplierCode     string            `json:"supplier_code"`     // 供应商代码
+	URL              string            `json:"url"`               // 供应商网址
+	Address          string            `json:"address"`           // 地址
+	Currency         string            `json:"currency"`          // 结算币种
+	SettlementMethod int               `json:"settlement_method"` // 结算方式
+	PaymentTerms     string            `json:"payment_terms"`     // 付款条件
+	AccountPeriod    int               `json:"account_period"`    // 账期（天）
+	BankName         string            `json:"bank_name"`         // 开户行
+	BankAccount      string            `json:"bank_account"`      // 银行账号
+	BankAccountName  string            `json:"bank_account_name"` // 开户名
+	PurchaserId      int               `json:"purchaser_id"`      // 采购员 ID
+	Purchaser        string            `json:"purchaser"`         // 采购员
+	Remark           string            `json:"remark"`            // 备注
+	IsDisabled       bool              `json:"is_disabled"`       // 是否停用（0：否、1：是）
+	Contacts         []SupplierContact `json:"contacts"`          // 联系人
+	CreateTime       string            `json:"create_time"`       // 创建时间
+	UpdateTime       string            `json:"update_time"`       // 更新时间
+}
+
+type SuppliersQueryParams struct {
+	Paging
+	SupplierIds  []int  `json:"supplier_ids,omitempty"`  // 供应商 ID
+	SupplierName string `json:"supplier_name,omitempty"` // 供应商名称
+}
+
+func (m SuppliersQueryParams) Validate() error {
+	return nil
+}
+
+// Suppliers 查询供应商列表
+// https://openapidoc.lingxing.com/#/docs/Purchase/Supplier
+func (s purchaseService) Suppliers(params SuppliersQueryParams) (items []Supplier, nextOffset int, isLastPage bool, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	params.SetPagingVars()
+	res := struct {
+		NormalResponse
+		Data []Supplier `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(params).
+		Post("/routing/data/local_inventory/supplier")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		items = res.Data
+		nextOffset = params.nextOffset
+		isLastPage = res.Total <= nextOffset
+	}
+	return
+}
+
+// Supplier 查询供应商
+func (s purchaseService) Supplier(id int) (item Supplier, err error) {
+	if id <= 0 {
+		err = ErrNotFound
+		return
+	}
+
+	items, _, _, err := s.Suppliers(SuppliersQueryParams{SupplierIds: []int{id}})
+	if err != nil {
+		return
+	}
+	for _, supplier := range items {
+		if supplier.SupplierId == id {
+			return supplier, nil
+		}
+	}
+	err = ErrNotFound
+	return
+}
+
+// MissingSupplierIds 返回 ids 中不存在的供应商 ID，用于创建采购计划、采购单前校验供应商
+func (s purchaseService) MissingSupplierIds(ids ...int) (missingIds []int, err error) {
+	missingIds = make([]int, 0)
+	if len(ids) == 0 {
+		return
+	}
+
+	params := SuppliersQueryParams{SupplierIds: ids}
+	suppliers, err := queryAll(func(offset int) ([]Supplier, int, bool, error) {
+		params.Offset = offset
+		return s.Suppliers(params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[int]bool, len(suppliers))
+	for _, supplier := range suppliers {
+		exists[supplier.SupplierId] = true
+	}
+	for _, id := range ids {
+		if !exists[id] {
+			missingIds = append(missingIds, id)
+		}
+	}
+	return
+}
+
+// 添加、编辑供应商
+
+type UpsertSupplierRequest struct {
+	SupplierId       int               `json:"supplier_id,omitempty"`       // 供应商 ID（编辑时必填）
+	SupplierName     string            `json:"supplier_name"`               // 供应商名称
+	SupplierCode     string            `json:"supplier_code,omitempty"`     // 供应商代码
+	URL              string            `json:"url,omitempty"`               // 供应商网址
+	Address          string            `json:"address,omitempty"`           // 地址
+	Currency         string            `json:"currency"`                    // 结算币种
+	SettlementMethod int               `json:"settlement_method,omitempty"` // 结算方式
+	PaymentTerms     string            `json:"payment_terms,omitempty"`     // 付款条件
+	AccountPeriod    int               `json:"account_period,omitempty"`    // 账期（天）
+	BankName         string            `json:"bank_name,omitempty"`         // 开户行
+	BankAccount      string            `json:"bank_account,omitempty"`      // 银行账号
+	BankAccountName  string            `json:"bank_account_name,omitempty"` // 开户名
+	PurchaserId      int               `json:"purchaser_id,omitempty"`      // 采购员 ID
+	Remark           string            `json:"remark,omitempty"`            // 备注
+	Contacts         []SupplierContact `json:"contacts,omitempty"`          // 联系人
+}
+
+func (m UpsertSupplierRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SupplierId, validation.Min(0).Error("无效的供应商 ID")),
+		validation.Field(&m.SupplierName, validation.Required.Error("供应商名称不能为空")),
+		validation.Field(&m.URL, is.URL.Error("无效的供应商网址")),
+		validation.Field(&m.Currency,
+			validation.Required.Error("结算币种不能为空"),
+			validation.Length(3, 3).Error("无效的结算币种"),
+		),
+		validation.Field(&m.SettlementMethod, validation.In(
+			SupplierSettlementMethodCashOnDelivery,
+			SupplierSettlementMethodCashInAdvance,
+			SupplierSettlementMethodMonthly,
+			SupplierSettlementMethodSemiMonthly,
+			SupplierSettlementMethodWeekly,
+			SupplierSettlementMethodOther,
+		).Error("无效的结算方式")),
+		validation.Field(&m.AccountPeriod, validation.Min(0).Error("账期不能小于 {{.threshold}}")),
+		validation.Field(&m.Contacts),
+	)
+}
+
+// UpsertSupplier 添加、编辑供应商，返回供应商 ID
+func (s purchaseService) UpsertSupplier(req UpsertSupplierRequest) (id int, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			SupplierId int `json:"supplier_id"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/storage/supplier/set")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		id = res.Data.SupplierId
+		if id == 0 {
+			id = req.SupplierId
+		}
+		if id == 0 {
+			err = errors.New("lingxing: 未返回供应商 ID")
+		}
+	}
+	return
+}
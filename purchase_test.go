@@ -1,6 +1,7 @@
 package lingxing
 
 import (
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -25,3 +26,134 @@ func TestPurchaseService_Orders(t *testing.T) {
 	_, _, _, err := lingXingClient.Services.Purchase.Orders(params)
 	assert.Equal(t, nil, err, "error")
 }
+
+func TestPurchaseService_Suppliers(t *testing.T) {
+	params := SuppliersQueryParams{}
+	params.Limit = 2
+	_, _, _, err := lingXingClient.Services.Purchase.Suppliers(params)
+	assert.Equal(t, nil, err, "error")
+}
+
+func TestUpsertSupplierRequest_Validate(t *testing.T) {
+	req := UpsertSupplierRequest{
+		SupplierName:     "Supplier",
+		Currency:         "CNY",
+		SettlementMethod: SupplierSettlementMethodMonthly,
+		Contacts:         []SupplierContact{{Name: "Tom", Email: "tom@example.com"}},
+	}
+	assert.Nil(t, req.Validate())
+
+	req.SettlementMethod = 99
+	assert.NotNil(t, req.Validate())
+	req.SettlementMethod = 0
+	req.Contacts = []SupplierContact{{Name: "Tom", Email: "tom"}}
+	assert.NotNil(t, req.Validate())
+	req.Contacts = nil
+	req.Currency = ""
+	assert.NotNil(t, req.Validate())
+}
+
+func TestCreatePurchasePlanRequest_Validate(t *testing.T) {
+	req := CreatePurchasePlanRequest{
+		Items: []CreatePurchasePlanItem{{SKU: "A", WID: 1, QuantityPlan: 10, ExpectArriveTime: "2022-09-01"}},
+	}
+	assert.Nil(t, req.Validate())
+
+	req.Items[0].QuantityPlan = 0
+	assert.NotNil(t, req.Validate())
+	req.Items[0].QuantityPlan = 10
+	req.Items[0].ExpectArriveTime = "2022/09/01"
+	assert.NotNil(t, req.Validate())
+	req.Items = nil
+	assert.NotNil(t, req.Validate())
+}
+
+func TestPurchasePlanStatusRequest_Validate(t *testing.T) {
+	assert.Nil(t, PurchasePlanStatusRequest{PlanSNs: []string{"PP1"}, Status: PurchasePlanStatusPendingPurchase}.Validate())
+	assert.NotNil(t, PurchasePlanStatusRequest{PlanSNs: []string{"PP1"}, Status: PurchasePlanStatusRejected}.Validate())
+	assert.Nil(t, PurchasePlanStatusRequest{PlanSNs: []string{"PP1"}, Status: PurchasePlanStatusRejected, Remark: "price"}.Validate())
+	assert.NotNil(t, PurchasePlanStatusRequest{PlanSNs: []string{"PP1"}, Status: PurchasePlanStatusProcessed, Remark: "x"}.Validate())
+	assert.NotNil(t, PurchasePlanStatusRequest{Status: PurchasePlanStatusPendingPurchase}.Validate())
+}
+
+func TestCanChangePurchasePlanStatus(t *testing.T) {
+	assert.True(t, CanChangePurchasePlanStatus(PurchasePlanStatusPendingApproval, PurchasePlanStatusPendingPurchase))
+	assert.True(t, CanChangePurchasePlanStatus(PurchasePlanStatusPendingPurchase, PurchasePlanStatusVoided))
+	assert.False(t, CanChangePurchasePlanStatus(PurchasePlanStatusVoided, PurchasePlanStatusPendingPurchase))
+	assert.False(t, CanChangePurchasePlanStatus(PurchasePlanStatusProcessed, PurchasePlanStatusVoided))
+}
+
+func TestPurchaseService_ChangePlanStatusTransition(t *testing.T) {
+	s := purchaseService{}
+	err := s.ApprovePlans(PurchasePlan{PlanSN: "PP1", Status: PurchasePlanStatusPendingApproval}, PurchasePlan{PlanSN: "PP2", Status: PurchasePlanStatusProcessed})
+	assert.EqualError(t, err, "lingxing: 采购计划 PP2 不能从已处理变更为待采购")
+	err = s.VoidPlans("reason", PurchasePlan{PlanSN: "PP3", Status: PurchasePlanStatusVoided})
+	assert.EqualError(t, err, "lingxing: 采购计划 PP3 不能从已作废变更为已作废")
+	assert.NotNil(t, s.RejectPlans("reason"), "empty plans")
+}
+
+func TestPurchaseOrderItem_ReceivingStatus(t *testing.T) {
+	tests := []struct {
+		item   PurchaseOrderItem
+		status int
+	}{
+		{PurchaseOrderItem{QuantityReal: 10, QuantityReceive: 10}, PurchaseOrderItemNotReceived},
+		{PurchaseOrderItem{QuantityReal: 10, QuantityReceive: 4, QuantityEntry: 6}, PurchaseOrderItemPartiallyReceived},
+		{PurchaseOrderItem{QuantityReal: 10, QuantityReceive: 4, QuantityQcPrepare: 6}, PurchaseOrderItemPartiallyReceived},
+		{PurchaseOrderItem{QuantityReal: 10, QuantityReceive: 0, QuantityEntry: 10}, PurchaseOrderItemFullyReceived},
+		{PurchaseOrderItem{}, PurchaseOrderItemNotReceived},
+	}
+	for i, test := range tests {
+		assert.Equal(t, test.status, test.item.ReceivingStatus(), "test %d", i+1)
+	}
+}
+
+func TestPurchaseOrderArrivalRequest_ValidateWith(t *testing.T) {
+	order := PurchaseOrder{
+		OrderSN: "PO1",
+		Status:  PurchaseOrderStatusPendingArrival,
+		ItemList: []PurchaseOrderItem{
+			{ID: "1", SKU: "A", QuantityReal: 10, QuantityReceive: 4},
+			{ID: "2", SKU: "B", QuantityReal: 5, QuantityReceive: 5, IsDelete: true},
+		},
+	}
+	assert.Nil(t, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "1", Quantity: 4}}}.ValidateWith(order))
+	assert.NotNil(t, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "1", Quantity: 5}}}.ValidateWith(order))
+	assert.NotNil(t, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "2", Quantity: 1}}}.ValidateWith(order))
+	assert.NotNil(t, PurchaseOrderArrivalRequest{OrderSN: "PO2", Items: []PurchaseOrderArrivalItem{{ID: "1", Quantity: 1}}}.ValidateWith(order))
+	assert.NotNil(t, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "1"}}}.ValidateWith(order))
+	order.Status = PurchaseOrderStatusCompleted
+	assert.NotNil(t, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "1", Quantity: 4}}}.ValidateWith(order))
+}
+
+func TestUpdatePurchaseOrderRequest_Validate(t *testing.T) {
+	req := UpdatePurchaseOrderRequest{
+		OrderSN: "PO1",
+		CreatePurchaseOrderRequest: CreatePurchaseOrderRequest{
+			SupplierId: 1,
+			WID:        1,
+			Items:      []PurchaseOrderItemRequest{{SKU: "A", Price: 1.5, QuantityReal: 10}},
+		},
+	}
+	assert.Nil(t, req.Validate())
+	req.Items[0].QuantityReal = 0
+	assert.NotNil(t, req.Validate())
+	req.Items[0].QuantityReal = 10
+	req.OrderSN = ""
+	assert.NotNil(t, req.Validate())
+}
+
+func TestPurchaseService_RegisterOrderArrivalStatus(t *testing.T) {
+	s := purchaseService{}
+	order := PurchaseOrder{OrderSN: "PO1", Status: PurchaseOrderStatusCompleted, ItemList: []PurchaseOrderItem{{ID: "1", SKU: "A", QuantityReceive: 10}}}
+	err := s.RegisterOrderArrival(order, PurchaseOrderArrivalRequest{OrderSN: "PO1", Items: []PurchaseOrderArrivalItem{{ID: "1", Quantity: 5}}})
+	assert.EqualError(t, err, "采购单 PO1 的状态为完成，不能登记到货")
+}
+
+func TestPurchasePlan_UnmarshalSupplierId(t *testing.T) {
+	var plan PurchasePlan
+	assert.Nil(t, jsoniter.UnmarshalFromString(`{"plan_sn":"PP1","supplier_id":"12"}`, &plan))
+	assert.Equal(t, 12, plan.SupplierId)
+	assert.Nil(t, jsoniter.UnmarshalFromString(`{"plan_sn":"PP1","supplier_id":""}`, &plan))
+	assert.Equal(t, 0, plan.SupplierId)
+}
@@ -1,14 +1,27 @@
 package lingxing
 
 import (
+	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/gox/inx"
 	"github.com/hiscaler/lingxing/constant"
 	jsoniter "github.com/json-iterator/go"
+	"strconv"
+	"strings"
 )
 
 // 自发货订单
 // https://openapidoc.lingxing.com/#/docs/Sale/FBMOrderList
 
+// 自发货订单状态
+const (
+	FBMOrderStatusShipped         = 2 // 已发货
+	FBMOrderStatusUnpaid          = 3 // 未付款
+	FBMOrderStatusPendingReview   = 4 // 待审核
+	FBMOrderStatusPendingShipment = 5 // 待发货
+	FBMOrderStatusCanceled        = 6 // 已取消
+)
+
 type AmazonFBMOrder struct {
 	OrderNumber           string   `json:"order_number"`            // 系统单号
 	Status                string   `json:"status"`                  // 订单状态
@@ -36,6 +49,15 @@ type AmazonFBMOrdersQueryParams struct {
 func (m AmazonFBMOrdersQueryParams) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.SID, validation.Required.Error("店铺 ID 不能为空")),
+		validation.Field(&m.OrderStatus, validation.When(m.OrderStatus != "", validation.By(func(value interface{}) error {
+			for _, v := range strings.Split(value.(string), ",") {
+				status, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil || !inx.IntIn(status, FBMOrderStatusShipped, FBMOrderStatusUnpaid, FBMOrderStatusPendingReview, FBMOrderStatusPendingShipment, FBMOrderStatusCanceled) {
+					return fmt.Errorf("无效的订单状态：%s", v)
+				}
+			}
+			return nil
+		}))),
 		validation.Field(&m.StartTime, validation.When(m.StartTime != "", validation.Date(constant.DatetimeFormat).Error("查询开始时间格式有误"))),
 		validation.Field(&m.EndTime, validation.When(m.EndTime != "", validation.Date(constant.DatetimeFormat).Error("查询结束时间格式有误"))),
 	)
@@ -158,3 +180,163 @@ func (s fbmOrderService) One(number string) (item FBMOrderDetail, err error) {
 	}
 	return
 }
+
+// 自发货订单发货
+
+type FBMOrderShipmentItem struct {
+	OrderNumber    string `json:"order_number"`    // 系统单号
+	TrackingNumber string `json:"tracking_number"` // 跟踪号
+	ShipTime       string `json:"ship_time"`       // 发货时间（Y-m-d H:i:s，为空表示当前时间）
+}
+
+type FBMOrderShipmentRequest struct {
+	LogisticsProviderId string                 `json:"logistics_provider_id,omitempty"` // 物流商 ID
+	LogisticsTypeId     string                 `json:"logistics_type_id,omitempty"`     // 物流方式 ID
+	Items               []FBMOrderShipmentItem `json:"data"`                            // 发货订单
+}
+
+func (m FBMOrderShipmentItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderNumber, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.TrackingNumber, validation.Required.Error("跟踪号不能为空")),
+		validation.Field(&m.ShipTime, validation.When(m.ShipTime != "", validation.Date(constant.DatetimeFormat).Error("发货时间格式有误"))),
+	)
+}
+
+func (m FBMOrderShipmentRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Items, validation.Required.Error("发货订单不能为空")),
+	)
+}
+
+// Ship 自发货订单确认发货并回传跟踪号
+func (s fbmOrderService) Ship(req FBMOrderShipmentRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/order/Order/shipOrder")
+	return
+}
+
+// 自发货订单修改物流
+
+type FBMOrderLogisticsRequest struct {
+	OrderNumbers        []string `json:"order_numbers"`         // 系统单号
+	WID                 int      `json:"wid,omitempty"`         // 发货仓库 ID
+	LogisticsProviderId string   `json:"logistics_provider_id"` // 物流商 ID
+	LogisticsTypeId     string   `json:"logistics_type_id"`     // 物流方式 ID
+}
+
+func (m FBMOrderLogisticsRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderNumbers, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.LogisticsProviderId, validation.Required.Error("物流商 ID 不能为空")),
+		validation.Field(&m.LogisticsTypeId, validation.Required.Error("物流方式 ID 不能为空")),
+	)
+}
+
+// SetLogistics 自发货订单指定物流商、物流方式
+func (s fbmOrderService) SetLogistics(req FBMOrderLogisticsRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/order/Order/updateLogistics")
+	return
+}
+
+// 自发货订单客服备注
+
+type FBMOrderRemarkRequest struct {
+	OrderNumber     string `json:"order_number"`     // 系统单号
+	CustomerComment string `json:"customer_comment"` // 客服备注
+}
+
+func (m FBMOrderRemarkRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderNumber, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.CustomerComment, validation.Length(0, 500).Error("客服备注不能超过 {{.max}} 个字符")),
+	)
+}
+
+// Remark 自发货订单添加客服备注
+func (s fbmOrderService) Remark(req FBMOrderRemarkRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/order/Order/updateCustomerComment")
+	return
+}
+
+// 自发货订单状态变更
+
+// fbmOrderStatusTransitions 订单状态允许变更的目标状态
+var fbmOrderStatusTransitions = statusTransitions[int]{
+	FBMOrderStatusUnpaid:          {FBMOrderStatusCanceled},
+	FBMOrderStatusPendingReview:   {FBMOrderStatusPendingShipment, FBMOrderStatusCanceled},
+	FBMOrderStatusPendingShipment: {FBMOrderStatusPendingReview, FBMOrderStatusCanceled},
+}
+
+// CanChangeFBMOrderStatus 判断自发货订单是否可以从 from 状态变更为 to 状态
+// 已发货、已取消的订单不能再变更状态
+func CanChangeFBMOrderStatus(from, to int) bool {
+	return fbmOrderStatusTransitions.can(from, to)
+}
+
+type FBMOrderStatusRequest struct {
+	OrderNumbers []string `json:"order_numbers"` // 系统单号
+	Status       int      `json:"status"`        // 目标状态（4：待审核（截单）、5：待发货（审核通过）、6：已取消）
+	Reason       string   `json:"reason"`        // 原因
+}
+
+func (m FBMOrderStatusRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderNumbers, validation.Required.Error("系统单号不能为空")),
+		validation.Field(&m.Status,
+			validation.Required.Error("目标状态不能为空"),
+			validation.In(FBMOrderStatusPendingReview, FBMOrderStatusPendingShipment, FBMOrderStatusCanceled).Error("无效的目标状态"),
+		),
+	)
+}
+
+// ChangeStatus 检查订单是否可以变更为 status 状态后变更自发货订单状态
+func (s fbmOrderService) ChangeStatus(status int, reason string, orders ...AmazonFBMOrder) (err error) {
+	req := FBMOrderStatusRequest{
+		OrderNumbers: make([]string, len(orders)),
+		Status:       status,
+		Reason:       reason,
+	}
+	for i, order := range orders {
+		from, e := strconv.Atoi(strings.TrimSpace(order.Status))
+		if e != nil || !CanChangeFBMOrderStatus(from, status) {
+			return fmt.Errorf("lingxing: 自发货订单 %s 不能从状态 %s 变更为 %d", order.OrderNumber, order.Status, status)
+		}
+		req.OrderNumbers[i] = order.OrderNumber
+	}
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post("/routing/order/Order/updateOrderStatus")
+	return
+}
+
+// Hold 截单（待发货 -> 待审核）
+func (s fbmOrderService) Hold(reason string, orders ...AmazonFBMOrder) error {
+	return s.ChangeStatus(FBMOrderStatusPendingReview, reason, orders...)
+}
+
+// Cancel 取消订单（未付款、待审核、待发货 -> 已取消）
+func (s fbmOrderService) Cancel(reason string, orders ...AmazonFBMOrder) error {
+	return s.ChangeStatus(FBMOrderStatusCanceled, reason, orders...)
+}
@@ -2,6 +2,7 @@ package lingxing
 
 import (
 	"github.com/hiscaler/gox/jsonx"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -27,3 +28,48 @@ func TestFbmOrderService_One(t *testing.T) {
 		t.Log(jsonx.ToPrettyJson(order))
 	}
 }
+
+func TestAmazonFBMOrdersQueryParams_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   AmazonFBMOrdersQueryParams
+		hasError bool
+	}{
+		{"t0", AmazonFBMOrdersQueryParams{SID: "172"}, false},
+		{"t1", AmazonFBMOrdersQueryParams{SID: "172", OrderStatus: "2,5"}, false},
+		{"t2", AmazonFBMOrdersQueryParams{SID: "172", OrderStatus: "2,1"}, true},
+		{"t3", AmazonFBMOrdersQueryParams{SID: "172", OrderStatus: "a"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			assert.Equalf(t, tt.hasError, err != nil, "Validate(%s) error", jsonx.ToJson(tt.params, "{}"))
+		})
+	}
+}
+
+func TestCanChangeFBMOrderStatus(t *testing.T) {
+	assert.Equal(t, true, CanChangeFBMOrderStatus(FBMOrderStatusPendingShipment, FBMOrderStatusCanceled))
+	assert.Equal(t, true, CanChangeFBMOrderStatus(FBMOrderStatusPendingShipment, FBMOrderStatusPendingReview))
+	assert.Equal(t, false, CanChangeFBMOrderStatus(FBMOrderStatusShipped, FBMOrderStatusCanceled))
+	assert.Equal(t, false, CanChangeFBMOrderStatus(FBMOrderStatusCanceled, FBMOrderStatusPendingShipment))
+}
+
+func TestFbmOrderService_ChangeStatusTransition(t *testing.T) {
+	s := fbmOrderService{}
+	err := s.Cancel("reason", AmazonFBMOrder{OrderNumber: "N1", Status: "5"}, AmazonFBMOrder{OrderNumber: "N2", Status: "2"})
+	assert.EqualError(t, err, "lingxing: 自发货订单 N2 不能从状态 2 变更为 6")
+	err = s.Hold("reason", AmazonFBMOrder{OrderNumber: "N3", Status: ""})
+	assert.EqualError(t, err, "lingxing: 自发货订单 N3 不能从状态  变更为 4")
+	assert.NotNil(t, s.Hold("reason"), "empty orders")
+}
+
+func TestFbmOrderService_Remark(t *testing.T) {
+	err := lingXingClient.Services.Sale.FBM.Order.Remark(FBMOrderRemarkRequest{
+		OrderNumber:     "103138899667546112",
+		CustomerComment: "test",
+	})
+	if err != nil {
+		t.Errorf("Services.Sale.FBM.Order.Remark() error: %s", err.Error())
+	}
+}
@@ -0,0 +1,226 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/lingxing/constant"
+	"math"
+	"strings"
+)
+
+// 亚马逊订单利润计算
+// 基于订单详情中的各项费用计算商品、订单、SKU、店铺维度的利润和利润率
+//
+// 计算公式：
+// 毛利润 = 销售收益 - 税费（仅订单含税时扣减） - 促销费 - 平台费 - FBA 发货费 - 其他费用 - 其他费（测评费等） - 采购成本 - 头程费用
+// 毛利率 = 毛利润 / 销售收益
+//
+// 领星返回的费用字段可能为负数，计算时统一取绝对值作为成本
+// 采购成本和头程费用的币种为人民币，其他费的币种取 fee_currency，其余金额的币种取订单币种
+
+// AmazonOrderProfitBreakdown 利润构成
+type AmazonOrderProfitBreakdown struct {
+	Quantity          int     `json:"quantity"`            // 销量
+	SalesAmount       float64 `json:"sales_amount"`        // 销售收益
+	TaxAmount         float64 `json:"tax_amount"`          // 税费
+	PromotionAmount   float64 `json:"promotion_amount"`    // 促销费
+	CommissionAmount  float64 `json:"commission_amount"`   // 平台费
+	FBAShipmentAmount float64 `json:"fba_shipment_amount"` // FBA 发货费
+	OtherAmount       float64 `json:"other_amount"`        // 亚马逊收取的其他费用
+	FeeAmount         float64 `json:"fee_amount"`          // 其他费（测评费等）
+	CgPrice           float64 `json:"cg_price"`            // 采购成本
+	CgTransportCosts  float64 `json:"cg_transport_costs"`  // 头程费用
+	Profit            float64 `json:"profit"`              // 毛利润
+	Margin            float64 `json:"margin"`              // 毛利率
+}
+
+// add 累加利润构成，累加后需要调用 calculate 重新计算利润和利润率
+func (b *AmazonOrderProfitBreakdown) add(v AmazonOrderProfitBreakdown) {
+	b.Quantity += v.Quantity
+	b.SalesAmount += v.SalesAmount
+	b.TaxAmount += v.TaxAmount
+	b.PromotionAmount += v.PromotionAmount
+	b.CommissionAmount += v.CommissionAmount
+	b.FBAShipmentAmount += v.FBAShipmentAmount
+	b.OtherAmount += v.OtherAmount
+	b.FeeAmount += v.FeeAmount
+	b.CgPrice += v.CgPrice
+	b.CgTransportCosts += v.CgTransportCosts
+}
+
+func (b *AmazonOrderProfitBreakdown) calculate() {
+	b.Profit = b.SalesAmount -
+		b.TaxAmount -
+		b.PromotionAmount -
+		b.CommissionAmount -
+		b.FBAShipmentAmount -
+		b.OtherAmount -
+		b.FeeAmount -
+		b.CgPrice -
+		b.CgTransportCosts
+	if b.SalesAmount != 0 {
+		b.Margin = b.Profit / b.SalesAmount
+	} else {
+		b.Margin = 0
+	}
+}
+
+// AmazonOrderItemProfit 订单商品利润
+type AmazonOrderItemProfit struct {
+	AmazonOrderProfitBreakdown
+	OrderItemId string `json:"order_item_id"` // 订单商品 ID
+	SID         int    `json:"sid"`           // 店铺 ID
+	SellerSKU   string `json:"seller_sku"`    // MSKU
+	SKU         string `json:"sku"`           // 本地 SKU
+}
+
+// AmazonOrderProfit 订单利润
+type AmazonOrderProfit struct {
+	AmazonOrderProfitBreakdown
+	AmazonOrderId     string                  `json:"amazon_order_id"`     // 订单号
+	PurchaseDateLocal string                  `json:"purchase_date_local"` // 订购时间（站点时间）
+	Currency          string                  `json:"currency"`            // 结算币种
+	Items             []AmazonOrderItemProfit `json:"items"`               // 商品利润
+}
+
+// AmazonOrderProfitReport 利润汇总报表
+type AmazonOrderProfitReport struct {
+	Currency string                                `json:"currency"` // 结算币种
+	Total    AmazonOrderProfitBreakdown            `json:"total"`    // 合计
+	Orders   []AmazonOrderProfit                   `json:"orders"`   // 订单利润
+	SKUs     map[string]AmazonOrderProfitBreakdown `json:"skus"`     // SKU 利润（本地 SKU 为空时使用 MSKU）
+	SIDs     map[int]AmazonOrderProfitBreakdown    `json:"sids"`     // 店铺利润
+}
+
+// AmazonOrderProfitCalculator 订单利润计算器
+type AmazonOrderProfitCalculator struct {
+	currency  string             // 结算币种
+	converter *CurrencyConverter // 币种转换器
+}
+
+// NewAmazonOrderProfitCalculator 创建订单利润计算器，所有金额将被转换为 currency 币种
+func NewAmazonOrderProfitCalculator(currency string, converter *CurrencyConverter) *AmazonOrderProfitCalculator {
+	if converter == nil {
+		converter = NewCurrencyConverter()
+	}
+	return &AmazonOrderProfitCalculator{
+		currency:  strings.ToUpper(currency),
+		converter: converter,
+	}
+}
+
+// Item 计算订单商品利润
+func (c AmazonOrderProfitCalculator) Item(order AmazonOrderDetail, item AmazonOrderDetailItem) (profit AmazonOrderItemProfit, err error) {
+	month := order.PurchaseDateLocal
+	fnConvert := func(amount float64, currency string) (float64, error) {
+		return c.converter.Convert(math.Abs(amount), currency, c.currency, month)
+	}
+	b := AmazonOrderProfitBreakdown{Quantity: item.QuantityOrdered}
+	taxAmount := 0.0
+	if order.TaxesIncluded == "1" {
+		taxAmount = item.TaxAmount
+	}
+	orderAmounts := []struct {
+		amount float64
+		value  *float64
+	}{
+		{item.SalesPriceAmount, &b.SalesAmount},
+		{taxAmount, &b.TaxAmount},
+		{item.PromotionAmount, &b.PromotionAmount},
+		{item.CommissionAmount, &b.CommissionAmount},
+		{item.FBAShipmentAmount, &b.FBAShipmentAmount},
+		{item.OtherAmount, &b.OtherAmount},
+	}
+	for _, v := range orderAmounts {
+		if *v.value, err = fnConvert(v.amount, order.Currency); err != nil {
+			return
+		}
+	}
+	// 销售收益允许为负数（如退款）
+	if item.SalesPriceAmount < 0 {
+		b.SalesAmount = -b.SalesAmount
+	}
+
+	feeCurrency := item.FeeCurrency
+	if feeCurrency == "" {
+		feeCurrency = order.Currency
+	}
+	if b.FeeAmount, err = fnConvert(item.FeeCost, feeCurrency); err != nil {
+		return
+	}
+	if b.CgPrice, err = fnConvert(item.CgPrice, constant.CNY); err != nil {
+		return
+	}
+	if b.CgTransportCosts, err = fnConvert(item.CgTransportCosts, constant.CNY); err != nil {
+		return
+	}
+	b.calculate()
+
+	profit = AmazonOrderItemProfit{
+		AmazonOrderProfitBreakdown: b,
+		OrderItemId:                item.OrderItemId,
+		SID:                        item.SID,
+		SellerSKU:                  item.SellerSKU,
+		SKU:                        item.SKU,
+	}
+	return
+}
+
+// Order 计算订单利润
+func (c AmazonOrderProfitCalculator) Order(order AmazonOrderDetail) (profit AmazonOrderProfit, err error) {
+	profit = AmazonOrderProfit{
+		AmazonOrderId:     order.AmazonOrderId,
+		PurchaseDateLocal: order.PurchaseDateLocal,
+		Currency:          c.currency,
+		Items:             make([]AmazonOrderItemProfit, 0, len(order.ItemList)),
+	}
+	for _, item := range order.ItemList {
+		var itemProfit AmazonOrderItemProfit
+		if itemProfit, err = c.Item(order, item); err != nil {
+			return
+		}
+		profit.Items = append(profit.Items, itemProfit)
+		profit.add(itemProfit.AmazonOrderProfitBreakdown)
+	}
+	profit.calculate()
+	return
+}
+
+// Report 计算多个订单的利润并按订单、SKU、店铺汇总
+func (c AmazonOrderProfitCalculator) Report(orders ...AmazonOrderDetail) (report AmazonOrderProfitReport, err error) {
+	report = AmazonOrderProfitReport{
+		Currency: c.currency,
+		Orders:   make([]AmazonOrderProfit, 0, len(orders)),
+		SKUs:     make(map[string]AmazonOrderProfitBreakdown),
+		SIDs:     make(map[int]AmazonOrderProfitBreakdown),
+	}
+	for _, order := range orders {
+		var orderProfit AmazonOrderProfit
+		if orderProfit, err = c.Order(order); err != nil {
+			return
+		}
+		report.Orders = append(report.Orders, orderProfit)
+		report.Total.add(orderProfit.AmazonOrderProfitBreakdown)
+		for _, item := range orderProfit.Items {
+			sku := item.SKU
+			if sku == "" {
+				sku = item.SellerSKU
+			}
+			b := report.SKUs[sku]
+			b.add(item.AmazonOrderProfitBreakdown)
+			report.SKUs[sku] = b
+
+			b = report.SIDs[item.SID]
+			b.add(item.AmazonOrderProfitBreakdown)
+			report.SIDs[item.SID] = b
+		}
+	}
+	report.Total.calculate()
+	for k, b := range report.SKUs {
+		b.calculate()
+		report.SKUs[k] = b
+	}
+	for k, b := range report.SIDs {
+		b.calculate()
+		report.SIDs[k] = b
+	}
+	return
+}
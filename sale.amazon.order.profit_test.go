@@ -0,0 +1,69 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAmazonOrderProfitCalculator_Report(t *testing.T) {
+	converter := NewCurrencyConverter(
+		Rate{Date: "2022-10", Code: "USD", RateOrg: 7},
+		Rate{Date: "2022-11", Code: "USD", RateOrg: 7.2, MyRate: 7.1},
+	)
+	order := AmazonOrderDetail{
+		AmazonOrderId:     "111-1",
+		Currency:          "USD",
+		PurchaseDateLocal: "2022-11-11 10:00:00",
+		TaxesIncluded:     "1",
+		ItemList: []AmazonOrderDetailItem{
+			{
+				SID:               1,
+				SellerSKU:         "MSKU-1",
+				SKU:               "SKU-1",
+				QuantityOrdered:   2,
+				SalesPriceAmount:  100,
+				TaxAmount:         10,
+				PromotionAmount:   -5,
+				CommissionAmount:  -15,
+				FBAShipmentAmount: -6,
+				OtherAmount:       -1,
+				CgPrice:           71,
+				CgTransportCosts:  14.2,
+			},
+			{
+				SID:              2,
+				SellerSKU:        "MSKU-2",
+				QuantityOrdered:  1,
+				SalesPriceAmount: 50,
+				FeeCost:          5,
+				FeeCurrency:      "USD",
+			},
+		},
+	}
+	calculator := NewAmazonOrderProfitCalculator("USD", converter)
+	report, err := calculator.Report(order)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Orders))
+
+	item := report.Orders[0].Items[0]
+	assert.InDelta(t, 10.0, item.CgPrice, 0.0001)
+	assert.InDelta(t, 2.0, item.CgTransportCosts, 0.0001)
+	assert.InDelta(t, 100.0-10-5-15-6-1-10-2, item.Profit, 0.0001)
+	assert.InDelta(t, 0.51, item.Margin, 0.0001)
+
+	assert.InDelta(t, 45.0, report.SKUs["MSKU-2"].Profit, 0.0001)
+	assert.InDelta(t, 51.0, report.SIDs[1].Profit, 0.0001)
+	assert.InDelta(t, 96.0, report.Total.Profit, 0.0001)
+	assert.Equal(t, 3, report.Total.Quantity)
+	assert.InDelta(t, 96.0/150, report.Orders[0].Margin, 0.0001)
+
+	// CNY report uses 2022-11 my rate
+	report, err = NewAmazonOrderProfitCalculator("CNY", converter).Report(order)
+	assert.Nil(t, err)
+	assert.InDelta(t, 96.0*7.1, report.Total.Profit, 0.0001)
+
+	// Missing rate
+	order.Currency = "EUR"
+	_, err = calculator.Report(order)
+	assert.NotNil(t, err)
+}
@@ -65,6 +65,36 @@ type ProductReport struct {
 	SmallRankList               json.RawMessage `json:"smallRankList"`                  // 小类排名数组（格式：[{"smallRankName":"","rankValue":""}]）
 }
 
+// asinSales 按店铺 ID + ASIN 汇总的日均销量
+type asinSales map[string]float64
+
+// add 添加产品表现，days 为产品表现的统计天数，日均销量 = 销量 / days
+func (s asinSales) add(days int, reports ...ProductReport) {
+	if days <= 0 {
+		return
+	}
+	for _, report := range reports {
+		if report.ASIN == "" {
+			continue
+		}
+		s[sidKey(report.SID, report.ASIN)] += float64(report.Volume) / float64(days)
+	}
+}
+
+// split 产品表现按 ASIN 统计，同一店铺下多个 Listing 对应同一 ASIN 时日均销量平均分配到各 Listing
+// keys 为各 Listing 的店铺 ID + ASIN 键，返回店铺 ID + ASIN => 每个 Listing 的日均销量
+func (s asinSales) split(keys ...string) map[string]float64 {
+	counts := make(map[string]int, len(keys))
+	for _, key := range keys {
+		counts[key]++
+	}
+	sales := make(map[string]float64, len(counts))
+	for key, n := range counts {
+		sales[key] = s[key] / float64(n)
+	}
+	return sales
+}
+
 type ProductStatisticQueryParams struct {
 	Paging
 	SID       int    `json:"sid"`                 // 店铺 ID
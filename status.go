@@ -0,0 +1,413 @@
+package lingxing
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 状态码
+// 领星接口返回的状态码存在数字、数字字符串两种格式，以下类型在 JSON 解析时同时支持这两种格式，
+// 另外也支持使用状态的中文或英文名称进行解析，序列化时统一输出为数字
+
+// StatusUnknown 未知状态
+// 部分状态的有效值为 0（例如 FBA 发货计划的待审核），为避免将缺失的状态误认为有效状态，
+// JSON 中的状态值为空（""、null）时解析为该值，序列化时输出为 null
+const StatusUnknown = -1 << 31
+
+type statusText struct {
+	zh string // 中文名称
+	en string // 英文名称
+}
+
+type statusTexts map[int]statusText
+
+func (t statusTexts) chinese(v int) string {
+	if v == StatusUnknown {
+		return "未知"
+	}
+	if text, ok := t[v]; ok {
+		return text.zh
+	}
+	return strconv.Itoa(v)
+}
+
+func (t statusTexts) english(v int) string {
+	if v == StatusUnknown {
+		return "Unknown"
+	}
+	if text, ok := t[v]; ok {
+		return text.en
+	}
+	return strconv.Itoa(v)
+}
+
+func (t statusTexts) valid(v int) bool {
+	_, ok := t[v]
+	return ok
+}
+
+// marshal 序列化状态值
+func (t statusTexts) marshal(v int) []byte {
+	if v == StatusUnknown {
+		return []byte("null")
+	}
+	return []byte(strconv.Itoa(v))
+}
+
+// statusTransitions 状态允许变更的目标状态，未列出的状态不能再变更
+type statusTransitions[T comparable] map[T][]T
+
+// can 判断是否可以从 from 状态变更为 to 状态
+func (t statusTransitions[T]) can(from, to T) bool {
+	for _, status := range t[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// parse 解析 JSON 格式的状态值，值为空时返回 StatusUnknown
+func (t statusTexts) parse(b []byte) (int, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return StatusUnknown, nil
+	}
+
+	s := string(b)
+	if b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return 0, err
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return StatusUnknown, nil
+		}
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v, nil
+	}
+	for v, text := range t {
+		if s == text.zh || strings.EqualFold(s, text.en) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("lingxing: 无效的状态值 %s", s)
+}
+
+// InboundOrderStatus 入库单状态
+type InboundOrderStatus int
+
+const (
+	InboundOrderStatusPendingSubmit   InboundOrderStatus = 10  // 待提交
+	InboundOrderStatusPendingApproval InboundOrderStatus = 121 // 待审批
+	InboundOrderStatusPendingInbound  InboundOrderStatus = 20  // 待入库
+	InboundOrderStatusCompleted       InboundOrderStatus = 40  // 已完成
+	InboundOrderStatusRevoked         InboundOrderStatus = 50  // 已撤销
+)
+
+var inboundOrderStatusTexts = statusTexts{
+	int(InboundOrderStatusPendingSubmit):   {"待提交", "Pending Submit"},
+	int(InboundOrderStatusPendingApproval): {"待审批", "Pending Approval"},
+	int(InboundOrderStatusPendingInbound):  {"待入库", "Pending Inbound"},
+	int(InboundOrderStatusCompleted):       {"已完成", "Completed"},
+	int(InboundOrderStatusRevoked):         {"已撤销", "Revoked"},
+}
+
+func (s InboundOrderStatus) String() string {
+	return inboundOrderStatusTexts.chinese(int(s))
+}
+
+func (s InboundOrderStatus) EnglishString() string {
+	return inboundOrderStatusTexts.english(int(s))
+}
+
+func (s InboundOrderStatus) IsValid() bool {
+	return inboundOrderStatusTexts.valid(int(s))
+}
+
+func (s InboundOrderStatus) MarshalJSON() ([]byte, error) {
+	return inboundOrderStatusTexts.marshal(int(s)), nil
+}
+
+func (s *InboundOrderStatus) UnmarshalJSON(b []byte) error {
+	v, err := inboundOrderStatusTexts.parse(b)
+	if err == nil {
+		*s = InboundOrderStatus(v)
+	}
+	return err
+}
+
+// OutboundOrderStatus 出库单状态
+type OutboundOrderStatus int
+
+const (
+	OutboundOrderStatusPendingSubmit   OutboundOrderStatus = 10  // 待提交
+	OutboundOrderStatusPendingApproval OutboundOrderStatus = 121 // 待审批
+	OutboundOrderStatusPendingOutbound OutboundOrderStatus = 30  // 待出库
+	OutboundOrderStatusCompleted       OutboundOrderStatus = 40  // 已完成
+	OutboundOrderStatusRevoked         OutboundOrderStatus = 50  // 已撤销
+)
+
+var outboundOrderStatusTexts = statusTexts{
+	int(OutboundOrderStatusPendingSubmit):   {"待提交", "Pending Submit"},
+	int(OutboundOrderStatusPendingApproval): {"待审批", "Pending Approval"},
+	int(OutboundOrderStatusPendingOutbound): {"待出库", "Pending Outbound"},
+	int(OutboundOrderStatusCompleted):       {"已完成", "Completed"},
+	int(OutboundOrderStatusRevoked):         {"已撤销", "Revoked"},
+}
+
+func (s OutboundOrderStatus) String() string {
+	return outboundOrderStatusTexts.chinese(int(s))
+}
+
+func (s OutboundOrderStatus) EnglishString() string {
+	return outboundOrderStatusTexts.english(int(s))
+}
+
+func (s OutboundOrderStatus) IsValid() bool {
+	return outboundOrderStatusTexts.valid(int(s))
+}
+
+func (s OutboundOrderStatus) MarshalJSON() ([]byte, error) {
+	return outboundOrderStatusTexts.marshal(int(s)), nil
+}
+
+func (s *OutboundOrderStatus) UnmarshalJSON(b []byte) error {
+	v, err := outboundOrderStatusTexts.parse(b)
+	if err == nil {
+		*s = OutboundOrderStatus(v)
+	}
+	return err
+}
+
+// PurchasePlanStatus 采购计划状态
+type PurchasePlanStatus int
+
+const (
+	PurchasePlanStatusPendingPurchase PurchasePlanStatus = 2   // 待采购
+	PurchasePlanStatusProcessed       PurchasePlanStatus = -2  // 已处理
+	PurchasePlanStatusRejected        PurchasePlanStatus = 122 // 已驳回
+	PurchasePlanStatusVoided          PurchasePlanStatus = -3  // 已作废
+	PurchasePlanStatusPendingApproval PurchasePlanStatus = 124 // 待审批
+)
+
+var purchasePlanStatusTexts = statusTexts{
+	int(PurchasePlanStatusPendingPurchase): {"待采购", "Pending Purchase"},
+	int(PurchasePlanStatusProcessed):       {"已处理", "Processed"},
+	int(PurchasePlanStatusRejected):        {"已驳回", "Rejected"},
+	int(PurchasePlanStatusVoided):          {"已作废", "Voided"},
+	int(PurchasePlanStatusPendingApproval): {"待审批", "Pending Approval"},
+}
+
+func (s PurchasePlanStatus) String() string {
+	return purchasePlanStatusTexts.chinese(int(s))
+}
+
+func (s PurchasePlanStatus) EnglishString() string {
+	return purchasePlanStatusTexts.english(int(s))
+}
+
+func (s PurchasePlanStatus) IsValid() bool {
+	return purchasePlanStatusTexts.valid(int(s))
+}
+
+func (s PurchasePlanStatus) MarshalJSON() ([]byte, error) {
+	return purchasePlanStatusTexts.marshal(int(s)), nil
+}
+
+func (s *PurchasePlanStatus) UnmarshalJSON(b []byte) error {
+	v, err := purchasePlanStatusTexts.parse(b)
+	if err == nil {
+		*s = PurchasePlanStatus(v)
+	}
+	return err
+}
+
+// PurchaseOrderStatus 采购单状态
+type PurchaseOrderStatus int
+
+const (
+	PurchaseOrderStatusVoided          PurchaseOrderStatus = -1  // 作废
+	PurchaseOrderStatusDraft           PurchaseOrderStatus = 0   // 待审核（草稿）
+	PurchaseOrderStatusPendingOrder    PurchaseOrderStatus = 1   // 待下单（已审核）
+	PurchaseOrderStatusPendingArrival  PurchaseOrderStatus = 2   // 待签收（待到货）
+	PurchaseOrderStatusCompleted       PurchaseOrderStatus = 9   // 完成
+	PurchaseOrderStatusPendingApproval PurchaseOrderStatus = 121 // （审批流）待审核
+	PurchaseOrderStatusRejected        PurchaseOrderStatus = 122 // （审批流）驳回
+	PurchaseOrderStatusApprovalVoided  PurchaseOrderStatus = 124 // （审批流）作废
+)
+
+var purchaseOrderStatusTexts = statusTexts{
+	int(PurchaseOrderStatusVoided):          {"作废", "Voided"},
+	int(PurchaseOrderStatusDraft):           {"待审核", "Draft"},
+	int(PurchaseOrderStatusPendingOrder):    {"待下单", "Pending Order"},
+	int(PurchaseOrderStatusPendingArrival):  {"待签收", "Pending Arrival"},
+	int(PurchaseOrderStatusCompleted):       {"完成", "Completed"},
+	int(PurchaseOrderStatusPendingApproval): {"审批流待审核", "Pending Approval"},
+	int(PurchaseOrderStatusRejected):        {"审批流驳回", "Rejected"},
+	int(PurchaseOrderStatusApprovalVoided):  {"审批流作废", "Approval Voided"},
+}
+
+func (s PurchaseOrderStatus) String() string {
+	return purchaseOrderStatusTexts.chinese(int(s))
+}
+
+func (s PurchaseOrderStatus) EnglishString() string {
+	return purchaseOrderStatusTexts.english(int(s))
+}
+
+func (s PurchaseOrderStatus) IsValid() bool {
+	return purchaseOrderStatusTexts.valid(int(s))
+}
+
+func (s PurchaseOrderStatus) MarshalJSON() ([]byte, error) {
+	return purchaseOrderStatusTexts.marshal(int(s)), nil
+}
+
+func (s *PurchaseOrderStatus) UnmarshalJSON(b []byte) error {
+	v, err := purchaseOrderStatusTexts.parse(b)
+	if err == nil {
+		*s = PurchaseOrderStatus(v)
+	}
+	return err
+}
+
+// FBAShipmentPlanStatus FBA 发货计划状态
+type FBAShipmentPlanStatus int
+
+const (
+	FBAShipmentPlanStatusRejected      FBAShipmentPlanStatus = -5 // 已驳回
+	FBAShipmentPlanStatusPendingReview FBAShipmentPlanStatus = 0  // 待审核
+	FBAShipmentPlanStatusPending       FBAShipmentPlanStatus = 5  // 待处理
+	FBAShipmentPlanStatusProcessed     FBAShipmentPlanStatus = 10 // 已处理
+)
+
+var fbaShipmentPlanStatusTexts = statusTexts{
+	int(FBAShipmentPlanStatusRejected):      {"已驳回", "Rejected"},
+	int(FBAShipmentPlanStatusPendingReview): {"待审核", "Pending Review"},
+	int(FBAShipmentPlanStatusPending):       {"待处理", "Pending"},
+	int(FBAShipmentPlanStatusProcessed):     {"已处理", "Processed"},
+}
+
+func (s FBAShipmentPlanStatus) String() string {
+	return fbaShipmentPlanStatusTexts.chinese(int(s))
+}
+
+func (s FBAShipmentPlanStatus) EnglishString() string {
+	return fbaShipmentPlanStatusTexts.english(int(s))
+}
+
+func (s FBAShipmentPlanStatus) IsValid() bool {
+	return fbaShipmentPlanStatusTexts.valid(int(s))
+}
+
+func (s FBAShipmentPlanStatus) MarshalJSON() ([]byte, error) {
+	return fbaShipmentPlanStatusTexts.marshal(int(s)), nil
+}
+
+func (s *FBAShipmentPlanStatus) UnmarshalJSON(b []byte) error {
+	v, err := fbaShipmentPlanStatusTexts.parse(b)
+	if err == nil {
+		*s = FBAShipmentPlanStatus(v)
+	}
+	return err
+}
+
+// MultiPlatformOrderStatus 多平台订单系统状态
+// 注意：MultiPlatformOrder.OrderStatus 原为 string 类型，现调整为该类型，原有使用字符串比较的代码需改为使用以下常量
+type MultiPlatformOrderStatus int
+
+const (
+	MultiPlatformOrderStatusSyncing          MultiPlatformOrderStatus = 1 // 同步中
+	MultiPlatformOrderStatusSynced           MultiPlatformOrderStatus = 2 // 已同步
+	MultiPlatformOrderStatusUnpaid           MultiPlatformOrderStatus = 3 // 未付款
+	MultiPlatformOrderStatusPendingReview    MultiPlatformOrderStatus = 4 // 待审核
+	MultiPlatformOrderStatusPendingShipment  MultiPlatformOrderStatus = 5 // 待发货
+	MultiPlatformOrderStatusShipped          MultiPlatformOrderStatus = 6 // 已发货
+	MultiPlatformOrderStatusCanceled         MultiPlatformOrderStatus = 7 // 已取消
+	MultiPlatformOrderStatusHidden           MultiPlatformOrderStatus = 8 // 不显示
+	MultiPlatformOrderStatusPlatformShipment MultiPlatformOrderStatus = 9 // 平台发货
+)
+
+var multiPlatformOrderStatusTexts = statusTexts{
+	int(MultiPlatformOrderStatusSyncing):          {"同步中", "Syncing"},
+	int(MultiPlatformOrderStatusSynced):           {"已同步", "Synced"},
+	int(MultiPlatformOrderStatusUnpaid):           {"未付款", "Unpaid"},
+	int(MultiPlatformOrderStatusPendingReview):    {"待审核", "Pending Review"},
+	int(MultiPlatformOrderStatusPendingShipment):  {"待发货", "Pending Shipment"},
+	int(MultiPlatformOrderStatusShipped):          {"已发货", "Shipped"},
+	int(MultiPlatformOrderStatusCanceled):         {"已取消", "Canceled"},
+	int(MultiPlatformOrderStatusHidden):           {"不显示", "Hidden"},
+	int(MultiPlatformOrderStatusPlatformShipment): {"平台发货", "Platform Shipment"},
+}
+
+func (s MultiPlatformOrderStatus) String() string {
+	return multiPlatformOrderStatusTexts.chinese(int(s))
+}
+
+func (s MultiPlatformOrderStatus) EnglishString() string {
+	return multiPlatformOrderStatusTexts.english(int(s))
+}
+
+func (s MultiPlatformOrderStatus) IsValid() bool {
+	return multiPlatformOrderStatusTexts.valid(int(s))
+}
+
+func (s MultiPlatformOrderStatus) MarshalJSON() ([]byte, error) {
+	return multiPlatformOrderStatusTexts.marshal(int(s)), nil
+}
+
+func (s *MultiPlatformOrderStatus) UnmarshalJSON(b []byte) error {
+	v, err := multiPlatformOrderStatusTexts.parse(b)
+	if err == nil {
+		*s = MultiPlatformOrderStatus(v)
+	}
+	return err
+}
+
+// ShipmentSheetStatus FBA 发货单状态
+type ShipmentSheetStatus int
+
+const (
+	ShipmentSheetStatusPendingAllocation ShipmentSheetStatus = -1 // 待配货
+	ShipmentSheetStatusPendingShipment   ShipmentSheetStatus = 0  // 待发货
+	ShipmentSheetStatusShipped           ShipmentSheetStatus = 1  // 已发货
+	ShipmentSheetStatusCompleted         ShipmentSheetStatus = 2  // 已完成
+	ShipmentSheetStatusVoided            ShipmentSheetStatus = 3  // 已作废
+)
+
+var shipmentSheetStatusTexts = statusTexts{
+	int(ShipmentSheetStatusPendingAllocation): {"待配货", "Pending Allocation"},
+	int(ShipmentSheetStatusPendingShipment):   {"待发货", "Pending Shipment"},
+	int(ShipmentSheetStatusShipped):           {"已发货", "Shipped"},
+	int(ShipmentSheetStatusCompleted):         {"已完成", "Completed"},
+	int(ShipmentSheetStatusVoided):            {"已作废", "Voided"},
+}
+
+func (s ShipmentSheetStatus) String() string {
+	return shipmentSheetStatusTexts.chinese(int(s))
+}
+
+func (s ShipmentSheetStatus) EnglishString() string {
+	return shipmentSheetStatusTexts.english(int(s))
+}
+
+func (s ShipmentSheetStatus) IsValid() bool {
+	return shipmentSheetStatusTexts.valid(int(s))
+}
+
+func (s ShipmentSheetStatus) MarshalJSON() ([]byte, error) {
+	return shipmentSheetStatusTexts.marshal(int(s)), nil
+}
+
+func (s *ShipmentSheetStatus) UnmarshalJSON(b []byte) error {
+	v, err := shipmentSheetStatusTexts.parse(b)
+	if err == nil {
+		*s = ShipmentSheetStatus(v)
+	}
+	return err
+}
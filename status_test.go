@@ -0,0 +1,93 @@
+package lingxing
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInboundOrderStatus_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected InboundOrderStatus
+		hasError bool
+	}{
+		{"t0", `{"status":40}`, InboundOrderStatusCompleted, false},
+		{"t1", `{"status":"121"}`, InboundOrderStatusPendingApproval, false},
+		{"t2", `{"status":"待入库"}`, InboundOrderStatusPendingInbound, false},
+		{"t3", `{"status":"revoked"}`, InboundOrderStatusRevoked, false},
+		{"t4", `{"status":null}`, StatusUnknown, false},
+		{"t5", `{"status":""}`, StatusUnknown, false},
+		{"t6", `{"status":"bad"}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := InboundOrder{}
+			err := jsoniter.Unmarshal([]byte(tt.json), &order)
+			assert.Equalf(t, tt.hasError, err != nil, "Unmarshal(%s) error", tt.json)
+			if err == nil {
+				assert.Equalf(t, tt.expected, order.Status, "Unmarshal(%s) status", tt.json)
+			}
+		})
+	}
+}
+
+func TestMultiPlatformOrderStatus(t *testing.T) {
+	order := MultiPlatformOrder{}
+	err := jsoniter.Unmarshal([]byte(`{"order_status":"6"}`), &order)
+	assert.Nil(t, err)
+	assert.Equal(t, MultiPlatformOrderStatusShipped, order.OrderStatus)
+	assert.Equal(t, "已发货", order.OrderStatus.String())
+	assert.Equal(t, "Shipped", order.OrderStatus.EnglishString())
+	assert.Equal(t, true, order.OrderStatus.IsValid())
+	assert.Equal(t, false, MultiPlatformOrderStatus(100).IsValid())
+	assert.Equal(t, "100", MultiPlatformOrderStatus(100).String())
+
+	b, err := jsoniter.Marshal(struct {
+		Status ShipmentSheetStatus `json:"status"`
+	}{ShipmentSheetStatusPendingAllocation})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"status":-1}`, string(b))
+}
+
+func TestFBAShipmentPlanStatus_Unknown(t *testing.T) {
+	var v struct {
+		Status FBAShipmentPlanStatus `json:"status"`
+	}
+	err := jsoniter.Unmarshal([]byte(`{"status":""}`), &v)
+	assert.Nil(t, err)
+	assert.NotEqual(t, FBAShipmentPlanStatusPendingReview, v.Status)
+	assert.Equal(t, FBAShipmentPlanStatus(StatusUnknown), v.Status)
+	assert.Equal(t, false, v.Status.IsValid())
+	assert.Equal(t, "未知", v.Status.String())
+
+	b, err := jsoniter.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"status":null}`, string(b))
+
+	err = jsoniter.Unmarshal([]byte(`{"status":0}`), &v)
+	assert.Nil(t, err)
+	assert.Equal(t, FBAShipmentPlanStatusPendingReview, v.Status)
+}
+
+func TestPurchasePlansQueryParams_ValidateStatus(t *testing.T) {
+	params := PurchasePlansQueryParams{
+		StartDate: "2022-01-01",
+		EndDate:   "2022-01-02",
+		Status:    []PurchasePlanStatus{PurchasePlanStatusPendingPurchase, PurchasePlanStatusRejected},
+	}
+	assert.Nil(t, params.Validate())
+	params.Status = append(params.Status, 1)
+	assert.NotNil(t, params.Validate())
+}
+
+func TestPurchaseOrderStatus_UnmarshalJSON(t *testing.T) {
+	order := PurchaseOrder{}
+	err := jsoniter.Unmarshal([]byte(`{"status":"9"}`), &order)
+	assert.Nil(t, err)
+	assert.Equal(t, PurchaseOrderStatusCompleted, order.Status)
+	assert.Equal(t, "完成", order.Status.String())
+	assert.Equal(t, true, PurchaseOrderStatusRejected.IsValid())
+	assert.Equal(t, false, PurchaseOrderStatus(3).IsValid())
+}
@@ -0,0 +1,276 @@
+package lingxing
+
+import (
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	jsoniter "github.com/json-iterator/go"
+	"strings"
+)
+
+// 出入库单据
+// 支持创建其他入库（1）、退货入库（26）、其他出库（11）、FBA 出库（12）单据，
+// 单据创建后为待提交状态，通过提交 -> 审批 -> 入库（出库）完成，未完成的单据可以撤销，
+// 提交、审批、入库（出库）、撤销时根据单据的当前状态检查是否允许变更，请传入最新查询的单据
+
+// OutboundInboundOrderItemRequest 出入库单产品
+type OutboundInboundOrderItemRequest struct {
+	SKU            string  `json:"sku"`                 // SKU
+	FnSKU          string  `json:"fnsku,omitempty"`     // FNSKU
+	SellerId       string  `json:"seller_id,omitempty"` // 店铺 ID
+	Price          float64 `json:"price"`               // 单价
+	ProductGoodNum int     `json:"product_good_num"`    // 良品量
+	ProductBadNum  int     `json:"product_bad_num"`     // 次品量
+}
+
+func (m OutboundInboundOrderItemRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("SKU 不能为空")),
+		validation.Field(&m.Price, validation.Min(0.0).Error("单价不能小于 {{.threshold}}")),
+		validation.Field(&m.ProductGoodNum,
+			validation.Min(0).Error("良品量不能小于 {{.threshold}}"),
+			validation.When(m.ProductBadNum == 0, validation.Required.Error("良品量和次品量不能同时为空")),
+		),
+		validation.Field(&m.ProductBadNum, validation.Min(0).Error("次品量不能小于 {{.threshold}}")),
+	)
+}
+
+// 创建入库单
+
+type CreateInboundOrderRequest struct {
+	WID         int                               `json:"wid"`                     // 仓库 ID
+	Type        int                               `json:"type"`                    // 入库类型（1：其他入库、26：退货入库）
+	SupplierId  int                               `json:"supplier_id,omitempty"`   // 供应商 ID
+	SourceSN    string                            `json:"source_sn,omitempty"`     // 关联单据号
+	ReturnPrice float64                           `json:"return_price,omitempty"`  // 运费
+	OtherFee    float64                           `json:"other_fee,omitempty"`     // 其他费用
+	Currency    string                            `json:"currency,omitempty"`      // 费用币种
+	FeePartType int                               `json:"fee_part_type,omitempty"` // 费用分摊方式（0：不分摊、1：按金额、2：按数量）
+	Remark      string                            `json:"remark,omitempty"`        // 备注
+	Items       []OutboundInboundOrderItemRequest `json:"item_list"`               // 入库产品
+}
+
+func (m CreateInboundOrderRequest) Validate() error {
+	hasFee := m.ReturnPrice > 0 || m.OtherFee > 0
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.WID, validation.Required.Error("仓库不能为空")),
+		validation.Field(&m.Type,
+			validation.Required.Error("入库类型不能为空"),
+			validation.In(InboundOrderTypeOther, InboundOrderTypeReturn).Error("无效的入库类型"),
+		),
+		validation.Field(&m.ReturnPrice, validation.Min(0.0).Error("运费不能小于 {{.threshold}}")),
+		validation.Field(&m.OtherFee, validation.Min(0.0).Error("其他费用不能小于 {{.threshold}}")),
+		validation.Field(&m.Currency, validation.When(hasFee,
+			validation.Required.Error("费用币种不能为空"),
+			validation.Length(3, 3).Error("无效的费用币种"),
+		)),
+		validation.Field(&m.FeePartType,
+			validation.In(FeePartTypeNone, FeePartTypeAmount, FeePartTypeQuantity).Error("无效的费用分摊方式"),
+			validation.When(hasFee, validation.Required.Error("存在运费或其他费用时费用分摊方式不能为空")),
+		),
+		validation.Field(&m.Items,
+			validation.Required.Error("入库产品不能为空"),
+			validation.By(outboundInboundOrderItemsUnique),
+		),
+	)
+}
+
+// CreateInboundOrder 创建入库单，返回入库单号
+func (s warehouseService) CreateInboundOrder(req CreateInboundOrderRequest) (orderSN string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	return s.createOrder("/routing/storage/inbound/create", req)
+}
+
+// 创建出库单
+
+type CreateOutboundOrderRequest struct {
+	WID      int                               `json:"wid"`                 // 仓库 ID
+	Type     int                               `json:"type"`                // 出库类型（11：其他出库、12：FBA 出库）
+	SourceSN string                            `json:"source_sn,omitempty"` // 关联单据号（FBA 出库时为发货单号）
+	Remark   string                            `json:"remark,omitempty"`    // 备注
+	Items    []OutboundInboundOrderItemRequest `json:"item_list"`           // 出库产品
+}
+
+func (m CreateOutboundOrderRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.WID, validation.Required.Error("仓库不能为空")),
+		validation.Field(&m.Type,
+			validation.Required.Error("出库类型不能为空"),
+			validation.In(OutboundOrderTypeOther, OutboundOrderTypeFBA).Error("无效的出库类型"),
+		),
+		validation.Field(&m.Items,
+			validation.Required.Error("出库产品不能为空"),
+			validation.By(outboundInboundOrderItemsUnique),
+			validation.When(m.Type == OutboundOrderTypeFBA, validation.By(func(value interface{}) error {
+				for _, item := range value.([]OutboundInboundOrderItemRequest) {
+					if item.SellerId == "" || item.FnSKU == "" {
+						return fmt.Errorf("FBA 出库产品 %s 的店铺和 FNSKU 不能为空", item.SKU)
+					}
+				}
+				return nil
+			})),
+		),
+	)
+}
+
+// CreateOutboundOrder 创建出库单，返回出库单号
+func (s warehouseService) CreateOutboundOrder(req CreateOutboundOrderRequest) (orderSN string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	return s.createOrder("/routing/storage/outbound/create", req)
+}
+
+// outboundInboundOrderItemsUnique 检查出入库单产品是否重复（SKU + FNSKU + 店铺）
+func outboundInboundOrderItemsUnique(value interface{}) error {
+	keys := make(map[string]bool)
+	for _, item := range value.([]OutboundInboundOrderItemRequest) {
+		key := strings.Join([]string{strings.ToUpper(item.SKU), strings.ToUpper(item.FnSKU), item.SellerId}, "\x00")
+		if keys[key] {
+			return fmt.Errorf("产品 %s 重复", item.SKU)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+func (s warehouseService) createOrder(url string, req interface{}) (orderSN string, err error) {
+	res := struct {
+		NormalResponse
+		Data struct {
+			OrderSN string `json:"order_sn"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post(url)
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		orderSN = res.Data.OrderSN
+		if orderSN == "" {
+			err = errors.New("lingxing: 未返回单号")
+		}
+	}
+	return
+}
+
+// 出入库单状态变更
+
+// inboundOrderStatusTransitions 入库单状态允许变更的目标状态
+var inboundOrderStatusTransitions = statusTransitions[InboundOrderStatus]{
+	InboundOrderStatusPendingSubmit:   {InboundOrderStatusPendingApproval, InboundOrderStatusRevoked},
+	InboundOrderStatusPendingApproval: {InboundOrderStatusPendingInbound, InboundOrderStatusRevoked},
+	InboundOrderStatusPendingInbound:  {InboundOrderStatusCompleted, InboundOrderStatusRevoked},
+}
+
+// CanChangeInboundOrderStatus 判断入库单是否可以从 from 状态变更为 to 状态
+// 已完成、已撤销的入库单不能再变更状态
+func CanChangeInboundOrderStatus(from, to InboundOrderStatus) bool {
+	return inboundOrderStatusTransitions.can(from, to)
+}
+
+// outboundOrderStatusTransitions 出库单状态允许变更的目标状态
+var outboundOrderStatusTransitions = statusTransitions[OutboundOrderStatus]{
+	OutboundOrderStatusPendingSubmit:   {OutboundOrderStatusPendingApproval, OutboundOrderStatusRevoked},
+	OutboundOrderStatusPendingApproval: {OutboundOrderStatusPendingOutbound, OutboundOrderStatusRevoked},
+	OutboundOrderStatusPendingOutbound: {OutboundOrderStatusCompleted, OutboundOrderStatusRevoked},
+}
+
+// CanChangeOutboundOrderStatus 判断出库单是否可以从 from 状态变更为 to 状态
+// 已完成、已撤销的出库单不能再变更状态
+func CanChangeOutboundOrderStatus(from, to OutboundOrderStatus) bool {
+	return outboundOrderStatusTransitions.can(from, to)
+}
+
+type OutboundInboundOrderActionRequest struct {
+	OrderSNs []string `json:"order_sns"`        // 单号
+	Remark   string   `json:"remark,omitempty"` // 备注（撤销原因）
+}
+
+func (m OutboundInboundOrderActionRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.OrderSNs, validation.Required.Error("单号不能为空")),
+	)
+}
+
+func (s warehouseService) orderAction(url string, req OutboundInboundOrderActionRequest) (err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	_, err = s.httpClient.R().
+		SetBody(req).
+		Post(url)
+	return
+}
+
+// inboundOrderAction 检查入库单是否可以变更为 to 状态后执行操作
+func (s warehouseService) inboundOrderAction(url string, to InboundOrderStatus, reason string, orders []InboundOrder) error {
+	orderSNs := make([]string, len(orders))
+	for i, order := range orders {
+		if !CanChangeInboundOrderStatus(order.Status, to) {
+			return fmt.Errorf("lingxing: 入库单 %s 不能从%s变更为%s", order.OrderSN, order.Status, to)
+		}
+		orderSNs[i] = order.OrderSN
+	}
+	return s.orderAction(url, OutboundInboundOrderActionRequest{OrderSNs: orderSNs, Remark: reason})
+}
+
+// outboundOrderAction 检查出库单是否可以变更为 to 状态后执行操作
+func (s warehouseService) outboundOrderAction(url string, to OutboundOrderStatus, reason string, orders []OutboundOrder) error {
+	orderSNs := make([]string, len(orders))
+	for i, order := range orders {
+		if !CanChangeOutboundOrderStatus(order.Status, to) {
+			return fmt.Errorf("lingxing: 出库单 %s 不能从%s变更为%s", order.OrderSN, order.Status, to)
+		}
+		orderSNs[i] = order.OrderSN
+	}
+	return s.orderAction(url, OutboundInboundOrderActionRequest{OrderSNs: orderSNs, Remark: reason})
+}
+
+// SubmitInboundOrders 提交入库单（待提交 -> 待审批）
+func (s warehouseService) SubmitInboundOrders(orders ...InboundOrder) error {
+	return s.inboundOrderAction("/routing/storage/inbound/submit", InboundOrderStatusPendingApproval, "", orders)
+}
+
+// ApproveInboundOrders 审批通过入库单（待审批 -> 待入库）
+func (s warehouseService) ApproveInboundOrders(orders ...InboundOrder) error {
+	return s.inboundOrderAction("/routing/storage/inbound/approve", InboundOrderStatusPendingInbound, "", orders)
+}
+
+// CompleteInboundOrders 入库单确认入库（待入库 -> 已完成）
+func (s warehouseService) CompleteInboundOrders(orders ...InboundOrder) error {
+	return s.inboundOrderAction("/routing/storage/inbound/complete", InboundOrderStatusCompleted, "", orders)
+}
+
+// RevokeInboundOrders 撤销入库单
+func (s warehouseService) RevokeInboundOrders(reason string, orders ...InboundOrder) error {
+	return s.inboundOrderAction("/routing/storage/inbound/revoke", InboundOrderStatusRevoked, reason, orders)
+}
+
+// SubmitOutboundOrders 提交出库单（待提交 -> 待审批）
+func (s warehouseService) SubmitOutboundOrders(orders ...OutboundOrder) error {
+	return s.outboundOrderAction("/routing/storage/outbound/submit", OutboundOrderStatusPendingApproval, "", orders)
+}
+
+// ApproveOutboundOrders 审批通过出库单（待审批 -> 待出库）
+func (s warehouseService) ApproveOutboundOrders(orders ...OutboundOrder) error {
+	return s.outboundOrderAction("/routing/storage/outbound/approve", OutboundOrderStatusPendingOutbound, "", orders)
+}
+
+// CompleteOutboundOrders 出库单确认出库（待出库 -> 已完成）
+func (s warehouseService) CompleteOutboundOrders(orders ...OutboundOrder) error {
+	return s.outboundOrderAction("/routing/storage/outbound/complete", OutboundOrderStatusCompleted, "", orders)
+}
+
+// RevokeOutboundOrders 撤销出库单
+func (s warehouseService) RevokeOutboundOrders(reason string, orders ...OutboundOrder) error {
+	return s.outboundOrderAction("/routing/storage/outbound/revoke", OutboundOrderStatusRevoked, reason, orders)
+}
@@ -0,0 +1,74 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateInboundOrderRequest_Validate(t *testing.T) {
+	req := CreateInboundOrderRequest{
+		WID:   1,
+		Type:  InboundOrderTypeOther,
+		Items: []OutboundInboundOrderItemRequest{{SKU: "A", ProductGoodNum: 1}},
+	}
+	assert.Nil(t, req.Validate())
+
+	req.Type = InboundOrderTypePurchase
+	assert.NotNil(t, req.Validate(), "purchase inbound")
+	req.Type = InboundOrderTypeReturn
+	assert.Nil(t, req.Validate())
+
+	req.ReturnPrice = 10
+	assert.NotNil(t, req.Validate(), "fee without currency and fee part type")
+	req.Currency = "CNY"
+	req.FeePartType = FeePartTypeQuantity
+	assert.Nil(t, req.Validate())
+	req.FeePartType = 3
+	assert.NotNil(t, req.Validate(), "invalid fee part type")
+	req.FeePartType = FeePartTypeAmount
+
+	req.Items = append(req.Items, OutboundInboundOrderItemRequest{SKU: "a", ProductBadNum: 1})
+	assert.NotNil(t, req.Validate(), "duplicate item")
+	req.Items[1].SKU = "B"
+	assert.Nil(t, req.Validate())
+	req.Items[1].ProductBadNum = 0
+	assert.NotNil(t, req.Validate(), "empty quantity")
+}
+
+func TestCreateOutboundOrderRequest_Validate(t *testing.T) {
+	req := CreateOutboundOrderRequest{
+		WID:   1,
+		Type:  OutboundOrderTypeOther,
+		Items: []OutboundInboundOrderItemRequest{{SKU: "A", ProductGoodNum: 1}},
+	}
+	assert.Nil(t, req.Validate())
+
+	req.Type = OutboundOrderTypeFBA
+	assert.NotNil(t, req.Validate(), "fba without seller")
+	req.Items[0].SellerId = "1"
+	req.Items[0].FnSKU = "X001"
+	assert.Nil(t, req.Validate())
+
+	req.Type = OutboundOrderTypeTransfer
+	assert.NotNil(t, req.Validate(), "transfer outbound")
+}
+
+func TestCanChangeOutboundInboundOrderStatus(t *testing.T) {
+	assert.True(t, CanChangeInboundOrderStatus(InboundOrderStatusPendingSubmit, InboundOrderStatusPendingApproval))
+	assert.True(t, CanChangeInboundOrderStatus(InboundOrderStatusPendingInbound, InboundOrderStatusCompleted))
+	assert.False(t, CanChangeInboundOrderStatus(InboundOrderStatusPendingSubmit, InboundOrderStatusCompleted))
+	assert.False(t, CanChangeInboundOrderStatus(InboundOrderStatusCompleted, InboundOrderStatusRevoked))
+
+	assert.True(t, CanChangeOutboundOrderStatus(OutboundOrderStatusPendingApproval, OutboundOrderStatusPendingOutbound))
+	assert.True(t, CanChangeOutboundOrderStatus(OutboundOrderStatusPendingOutbound, OutboundOrderStatusRevoked))
+	assert.False(t, CanChangeOutboundOrderStatus(OutboundOrderStatusRevoked, OutboundOrderStatusPendingSubmit))
+}
+
+func TestWarehouseService_OrderActionStatus(t *testing.T) {
+	s := warehouseService{}
+	err := s.CompleteInboundOrders(InboundOrder{OrderSN: "IB1", Status: InboundOrderStatusPendingInbound}, InboundOrder{OrderSN: "IB2", Status: InboundOrderStatusCompleted})
+	assert.EqualError(t, err, "lingxing: 入库单 IB2 不能从已完成变更为已完成")
+	err = s.RevokeOutboundOrders("reason", OutboundOrder{OrderSN: "OB1", Status: OutboundOrderStatusRevoked})
+	assert.EqualError(t, err, "lingxing: 出库单 OB1 不能从已撤销变更为已撤销")
+	assert.NotNil(t, s.SubmitOutboundOrders(), "empty orders")
+}
@@ -58,8 +58,33 @@ func (s warehouseService) All(params WarehousesQueryParams) (items []Warehouse,
 	return
 }
 
+// allWarehouses 查询所有本地仓库
+func (s warehouseService) allWarehouses() (warehouses []Warehouse, err error) {
+	params := WarehousesQueryParams{}
+	return queryAll(func(offset int) ([]Warehouse, int, bool, error) {
+		params.Offset = offset
+		return s.All(params)
+	})
+}
+
+// 费用分摊方式
+const (
+	FeePartTypeNone     = 0 // 不分摊
+	FeePartTypeAmount   = 1 // 按金额
+	FeePartTypeQuantity = 2 // 按数量
+)
+
 // 入库单
 
+// 入库类型
+const (
+	InboundOrderTypeOther    = 1  // 其他入库
+	InboundOrderTypePurchase = 2  // 采购入库
+	InboundOrderTypeTransfer = 3  // 调拨入库
+	InboundOrderTypeReturn   = 26 // 退货入库
+	InboundOrderTypeRemoval  = 27 // 移除入库
+)
+
 // OutboundInboundOrderItem 出入库单项
 type OutboundInboundOrderItem struct {
 	ProductName     string  `json:"product_name"`      // 品名
@@ -86,7 +111,7 @@ type InboundOrder struct {
 	CommitUID       string                     `json:"commit_uid"`         // 提交人 ID
 	CommitTime      string                     `json:"commit_time"`        // 提交时间
 	OrderSN         string                     `json:"order_sn"`           // 订单号
-	Status          int                        `json:"status"`             // 入库单状态
+	Status          InboundOrderStatus         `json:"status"`             // 入库单状态
 	StatusText      string                     `json:"status_text"`        // 入库单状态名称
 	CreateTime      string                     `json:"create_time"`        // 创建时间
 	CreateUID       int                        `json:"create_uid"`         // 创建人 ID
@@ -116,13 +141,13 @@ type InboundOrder struct {
 
 type InboundOrdersQueryParams struct {
 	Paging
-	WID             string `json:"wid"`               // 系统仓库 ID
-	SearchFieldTime string `json:"search_field_time"` // 时间搜索维度（create_time：创建时间、opt_time：入库时间）
-	StartDate       string `json:"start_date"`        // 开始日期（Y-m-d，闭区间）
-	EndDate         string `json:"end_date"`          // 结束日期（Y-m-d，开区间）
-	OrderSN         string `json:"order_sn"`          // 入库单单号，支持多个，分号隔离
-	Status          int    `json:"status"`            // 入库单状态（10：待提交、121：待审批、20：待入库、40：已完成、50：已撤销）
-	Type            int    `json:"type"`              // 入库类型（1：其他入库、2：采购入库、3：调拨入库、26：退货入库、27：移除入库）
+	WID             string             `json:"wid"`               // 系统仓库 ID
+	SearchFieldTime string             `json:"search_field_time"` // 时间搜索维度（create_time：创建时间、opt_time：入库时间）
+	StartDate       string             `json:"start_date"`        // 开始日期（Y-m-d，闭区间）
+	EndDate         string             `json:"end_date"`          // 结束日期（Y-m-d，开区间）
+	OrderSN         string             `json:"order_sn"`          // 入库单单号，支持多个，分号隔离
+	Status          InboundOrderStatus `json:"status"`            // 入库单状态（10：待提交、121：待审批、20：待入库、40：已完成、50：已撤销）
+	Type            int                `json:"type"`              // 入库类型（1：其他入库、2：采购入库、3：调拨入库、26：退货入库、27：移除入库）
 }
 
 func (m InboundOrdersQueryParams) Validate() error {
@@ -156,8 +181,20 @@ func (m InboundOrdersQueryParams) Validate() error {
 				return nil
 			}),
 		),
-		validation.Field(&m.Status, validation.In(10, 121, 20, 40, 50).Error("无效的入库单状态")),
-		validation.Field(&m.Type, validation.In(1, 2, 3, 26, 27).Error("无效的入库类型")),
+		validation.Field(&m.Status, validation.In(
+			InboundOrderStatusPendingSubmit,
+			InboundOrderStatusPendingApproval,
+			InboundOrderStatusPendingInbound,
+			InboundOrderStatusCompleted,
+			InboundOrderStatusRevoked,
+		).Error("无效的入库单状态")),
+		validation.Field(&m.Type, validation.In(
+			InboundOrderTypeOther,
+			InboundOrderTypePurchase,
+			InboundOrderTypeTransfer,
+			InboundOrderTypeReturn,
+			InboundOrderTypeRemoval,
+		).Error("无效的入库类型")),
 	)
 }
 
@@ -190,6 +227,14 @@ func (s warehouseService) InboundOrders(params InboundOrdersQueryParams) (items
 // 出库单
 // https://openapidoc.lingxing.com/#/docs/Warehouse/outboundgetOrders
 
+// 出库类型
+const (
+	OutboundOrderTypeOther    = 11 // 其他出库
+	OutboundOrderTypeFBA      = 12 // FBA 出库
+	OutboundOrderTypeReturn   = 14 // 退货出库
+	OutboundOrderTypeTransfer = 15 // 调拨出库
+)
+
 type OutboundOrder struct {
 	OptRealName     string                     `json:"opt_realname"`       // 出库人姓名
 	OptTime         string                     `json:"opt_time"`           // 操作时间
@@ -198,7 +243,7 @@ type OutboundOrder struct {
 	CommitUID       int                        `json:"commit_uid"`         // 提交人 ID
 	CommitTime      string                     `json:"commit_time"`        // 提交时间
 	OrderSN         string                     `json:"order_sn"`           // 订单号
-	Status          int                        `json:"status"`             // 出库单状态
+	Status          OutboundOrderStatus        `json:"status"`             // 出库单状态
 	StatusText      string                     `json:"status_text"`        // 出库单状态名称
 	CreateTime      string                     `json:"create_time"`        // 创建时间
 	CreateUID       int                        `json:"create_uid"`         // 创建人 ID
@@ -230,13 +275,13 @@ type OutboundOrder struct {
 
 type OutboundOrdersQueryParams struct {
 	Paging
-	WID             string `json:"wid"`               // 系统仓库 ID
-	SearchFieldTime string `json:"search_field_time"` // 时间搜索维度（create_time：创建时间、opt_time：出库时间）
-	StartDate       string `json:"start_date"`        // 开始日期（Y-m-d，闭区间）
-	EndDate         string `json:"end_date"`          // 结束日期（Y-m-d，开区间）
-	OrderSN         string `json:"order_sn"`          // 入库单单号（多个使用逗号分隔）
-	Status          int    `json:"status"`            // 出库单状态（10：待提交、121：待审批、30：待出库、40：已完成、50：已撤销）
-	Type            int    `json:"type"`              // 出库类型（11：其他出库、12：FBA 出库、14：退货出库、15：调拨出库）
+	WID             string              `json:"wid"`               // 系统仓库 ID
+	SearchFieldTime string              `json:"search_field_time"` // 时间搜索维度（create_time：创建时间、opt_time：出库时间）
+	StartDate       string              `json:"start_date"`        // 开始日期（Y-m-d，闭区间）
+	EndDate         string              `json:"end_date"`          // 结束日期（Y-m-d，开区间）
+	OrderSN         string              `json:"order_sn"`          // 入库单单号（多个使用逗号分隔）
+	Status          OutboundOrderStatus `json:"status"`            // 出库单状态（10：待提交、121：待审批、30：待出库、40：已完成、50：已撤销）
+	Type            int                 `json:"type"`              // 出库类型（11：其他出库、12：FBA 出库、14：退货出库、15：调拨出库）
 }
 
 func (m OutboundOrdersQueryParams) Validate() error {
@@ -270,8 +315,19 @@ func (m OutboundOrdersQueryParams) Validate() error {
 				return nil
 			}),
 		),
-		validation.Field(&m.Status, validation.In(10, 121, 30, 40, 50).Error("无效的出库单状态")),
-		validation.Field(&m.Type, validation.In(11, 12, 14, 15).Error("无效的出库类型")),
+		validation.Field(&m.Status, validation.In(
+			OutboundOrderStatusPendingSubmit,
+			OutboundOrderStatusPendingApproval,
+			OutboundOrderStatusPendingOutbound,
+			OutboundOrderStatusCompleted,
+			OutboundOrderStatusRevoked,
+		).Error("无效的出库单状态")),
+		validation.Field(&m.Type, validation.In(
+			OutboundOrderTypeOther,
+			OutboundOrderTypeFBA,
+			OutboundOrderTypeReturn,
+			OutboundOrderTypeTransfer,
+		).Error("无效的出库类型")),
 	)
 }
 
@@ -0,0 +1,310 @@
+package lingxing
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	jsoniter "github.com/json-iterator/go"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 仓库库存
+
+// 查询仓库库存明细
+// https://openapidoc.lingxing.com/#/docs/Warehouse/InventoryDetails
+
+// WarehouseInventory 仓库库存
+type WarehouseInventory struct {
+	WID                  int     `json:"wid"`                     // 仓库 ID
+	ProductId            int     `json:"product_id"`              // 产品 ID
+	SKU                  string  `json:"sku"`                     // SKU
+	SellerId             string  `json:"seller_id"`               // 店铺 ID
+	FNSKU                string  `json:"fnsku"`                   // FNSKU
+	ProductTotal         int     `json:"product_total"`           // 实际库存总量
+	ProductValidNum      int     `json:"product_valid_num"`       // 可用量
+	ProductBadNum        int     `json:"product_bad_num"`         // 次品量
+	ProductQcNum         int     `json:"product_qc_num"`          // 待检待上架量
+	ProductLockNum       int     `json:"product_lock_num"`        // 锁定量
+	GoodLockNum          int     `json:"good_lock_num"`           // 可用锁定量
+	BadLockNum           int     `json:"bad_lock_num"`            // 次品锁定量
+	ProductOnway         int     `json:"product_onway"`           // 调拨在途量
+	QuantityReceive      int     `json:"quantity_receive"`        // 待到货量
+	StockCost            float64 `json:"stock_cost"`              // 单位库存成本
+	StockCostTotal       float64 `json:"stock_cost_total"`        // 库存成本
+	TransitHeadCost      float64 `json:"transit_head_cost"`       // 调拨在途头程成本
+	AverageAge           int     `json:"average_age"`             // 平均库龄（天）
+	ThirdInventoryNum    int     `json:"third_inventory_num"`     // 第三方库存量
+	AvailableInventories int     `json:"available_inventories"`   // 可售量（海外仓）
+	ThirdPartyLockNum    int     `json:"third_party_lock_num"`    // 第三方锁定量
+	ThirdPartyOnwayNum   int     `json:"third_party_onway_num"`   // 第三方在途量
+	ThirdPartyReserveNum int     `json:"third_party_reserve_num"` // 第三方预留量
+}
+
+type WarehouseInventoriesQueryParams struct {
+	Paging
+	WID   string `json:"wid,omitempty"`   // 仓库 ID（多个使用英文逗号分隔）
+	SKU   string `json:"sku,omitempty"`   // SKU（模糊搜索）
+	FNSKU string `json:"fnsku,omitempty"` // FNSKU
+}
+
+func (m WarehouseInventoriesQueryParams) Validate() error {
+	return nil
+}
+
+// Inventories 查询仓库库存明细
+func (s warehouseService) Inventories(params WarehouseInventoriesQueryParams) (items []WarehouseInventory, nextOffset int, isLastPage bool, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	params.SetPagingVars()
+	res := struct {
+		NormalResponse
+		Data []WarehouseInventory `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(params).
+		Post("/routing/data/local_inventory/inventoryDetails")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		items = res.Data
+		nextOffset = params.nextOffset
+		isLastPage = len(items) < params.Limit
+	}
+	return
+}
+
+// 查询仓位库存明细
+// https://openapidoc.lingxing.com/#/docs/Warehouse/inventoryBinDetails
+
+// 仓位类型
+const (
+	WarehouseBinTypeToBeChecked = 1 // 待检暂存
+	WarehouseBinTypeAvailable   = 2 // 可用暂存
+	WarehouseBinTypeDefective   = 3 // 次品暂存
+	WarehouseBinTypePicking     = 4 // 拣货暂存
+	WarehouseBinTypeStorage     = 5 // 可用
+	WarehouseBinTypeBad         = 6 // 次品
+)
+
+// WarehouseInventoryBin 仓位库存
+type WarehouseInventoryBin struct {
+	WID           int    `json:"wid"`            // 仓库 ID
+	WarehouseName string `json:"wh_name"`        // 仓库名称
+	WhbId         int    `json:"whb_id"`         // 仓位 ID
+	WhbName       string `json:"whb_name"`       // 仓位名称
+	WhbType       int    `json:"whb_type"`       // 仓位类型（1：待检暂存、2：可用暂存、3：次品暂存、4：拣货暂存、5：可用、6：次品）
+	WhbTypeName   string `json:"whb_type_name"`  // 仓位类型名称
+	StoreId       string `json:"store_id"`       // 店铺 ID
+	ProductId     int    `json:"product_id"`     // 产品 ID
+	ProductName   string `json:"product_name"`   // 品名
+	SKU           string `json:"sku"`            // SKU
+	FNSKU         string `json:"fnsku"`          // FNSKU
+	Total         int    `json:"total"`          // 库存总量
+	ValidNum      int    `json:"validNum"`       // 可用量
+	LockNum       int    `json:"lockNum"`        // 锁定量
+	BatchNo       string `json:"batch_no"`       // 批次号
+	ReceivingTime string `json:"receiving_time"` // 入库时间
+}
+
+type WarehouseInventoryBinsQueryParams struct {
+	Paging
+	WID     string `json:"wid,omitempty"`      // 仓库 ID（多个使用英文逗号分隔）
+	BinType string `json:"bin_type,omitempty"` // 仓位类型（多个使用英文逗号分隔）
+}
+
+func (m WarehouseInventoryBinsQueryParams) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.BinType, validation.When(m.BinType != "", validation.By(func(value interface{}) error {
+			for _, v := range strings.Split(value.(string), ",") {
+				t, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil {
+					return err
+				}
+				if err = validation.Validate(t, validation.In(
+					WarehouseBinTypeToBeChecked,
+					WarehouseBinTypeAvailable,
+					WarehouseBinTypeDefective,
+					WarehouseBinTypePicking,
+					WarehouseBinTypeStorage,
+					WarehouseBinTypeBad,
+				).Error("无效的仓位类型")); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))),
+	)
+}
+
+// InventoryBins 查询仓位库存明细
+func (s warehouseService) InventoryBins(params WarehouseInventoryBinsQueryParams) (items []WarehouseInventoryBin, nextOffset int, isLastPage bool, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+
+	params.SetPagingVars()
+	res := struct {
+		NormalResponse
+		Data []WarehouseInventoryBin `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(params).
+		Post("/routing/data/local_inventory/inventoryBinDetails")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		items = res.Data
+		nextOffset = params.nextOffset
+		isLastPage = len(items) < params.Limit
+	}
+	return
+}
+
+// 库存快照
+
+// WarehouseInventorySnapshotItem 库存快照项
+type WarehouseInventorySnapshotItem struct {
+	WarehouseInventory
+	WarehouseName string                  `json:"warehouse_name"` // 仓库名称
+	Bins          []WarehouseInventoryBin `json:"bins"`           // 仓位库存
+}
+
+// WarehouseInventoryTotal 库存合计
+type WarehouseInventoryTotal struct {
+	ProductTotal    int `json:"product_total"`     // 实际库存总量
+	ProductValidNum int `json:"product_valid_num"` // 可用量
+	ProductLockNum  int `json:"product_lock_num"`  // 锁定量
+	ProductOnway    int `json:"product_onway"`     // 调拨在途量
+	ProductQcNum    int `json:"product_qc_num"`    // 待检待上架量
+	ProductBadNum   int `json:"product_bad_num"`   // 次品量
+}
+
+func (t *WarehouseInventoryTotal) add(v WarehouseInventory) {
+	t.ProductTotal += v.ProductTotal
+	t.ProductValidNum += v.ProductValidNum
+	t.ProductLockNum += v.ProductLockNum
+	t.ProductOnway += v.ProductOnway
+	t.ProductQcNum += v.ProductQcNum
+	t.ProductBadNum += v.ProductBadNum
+}
+
+// WarehouseInventorySnapshot 库存快照
+type WarehouseInventorySnapshot struct {
+	Time  time.Time                          `json:"time"`  // 快照时间
+	Items []WarehouseInventorySnapshotItem   `json:"items"` // 库存明细（按仓库 ID、SKU、FNSKU 排序）
+	SKUs  map[string]WarehouseInventoryTotal `json:"skus"`  // SKU 合计（所有仓库，键为大写的 SKU，请使用 SKU 方法查询）
+}
+
+// NewWarehouseInventorySnapshot 根据仓库、库存和仓位库存数据生成库存快照
+// 仓位库存根据仓库 ID、SKU、FNSKU 关联到库存明细
+func NewWarehouseInventorySnapshot(warehouses []Warehouse, inventories []WarehouseInventory, bins []WarehouseInventoryBin) WarehouseInventorySnapshot {
+	names := make(map[int]string, len(warehouses))
+	for _, warehouse := range warehouses {
+		names[warehouse.WID] = warehouse.Name
+	}
+	key := func(wid int, sku, fnSKU string) string {
+		return strconv.Itoa(wid) + "\x00" + skuKey(sku) + "\x00" + skuKey(fnSKU)
+	}
+	binGroups := make(map[string][]WarehouseInventoryBin)
+	for _, bin := range bins {
+		k := key(bin.WID, bin.SKU, bin.FNSKU)
+		binGroups[k] = append(binGroups[k], bin)
+	}
+
+	snapshot := WarehouseInventorySnapshot{
+		Time:  time.Now(),
+		Items: make([]WarehouseInventorySnapshotItem, 0, len(inventories)),
+		SKUs:  make(map[string]WarehouseInventoryTotal),
+	}
+	for _, inventory := range inventories {
+		item := WarehouseInventorySnapshotItem{
+			WarehouseInventory: inventory,
+			WarehouseName:      names[inventory.WID],
+			Bins:               binGroups[key(inventory.WID, inventory.SKU, inventory.FNSKU)],
+		}
+		if item.Bins == nil {
+			item.Bins = make([]WarehouseInventoryBin, 0)
+		}
+		snapshot.Items = append(snapshot.Items, item)
+		k := skuKey(inventory.SKU)
+		total := snapshot.SKUs[k]
+		total.add(inventory)
+		snapshot.SKUs[k] = total
+	}
+	sort.SliceStable(snapshot.Items, func(i, j int) bool {
+		a, b := snapshot.Items[i], snapshot.Items[j]
+		if a.WID != b.WID {
+			return a.WID < b.WID
+		}
+		if ak, bk := skuKey(a.SKU), skuKey(b.SKU); ak != bk {
+			return ak < bk
+		}
+		return skuKey(a.FNSKU) < skuKey(b.FNSKU)
+	})
+	return snapshot
+}
+
+// SKU 指定 SKU 在所有仓库的库存合计，SKU 不区分大小写
+func (s WarehouseInventorySnapshot) SKU(sku string) WarehouseInventoryTotal {
+	return s.SKUs[skuKey(sku)]
+}
+
+// Warehouse 指定仓库的库存明细
+func (s WarehouseInventorySnapshot) Warehouse(wid int) []WarehouseInventorySnapshotItem {
+	items := make([]WarehouseInventorySnapshotItem, 0)
+	for _, item := range s.Items {
+		if item.WID == wid {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// InventorySnapshot 生成本地仓库的库存快照，wids 为空时包含所有本地仓库，withBins 为 true 时包含仓位库存
+func (s warehouseService) InventorySnapshot(withBins bool, wids ...int) (snapshot WarehouseInventorySnapshot, err error) {
+	warehouses, err := s.allWarehouses()
+	if err != nil {
+		return
+	}
+	if len(wids) == 0 {
+		for _, warehouse := range warehouses {
+			wids = append(wids, warehouse.WID)
+		}
+	}
+	if len(wids) == 0 {
+		return NewWarehouseInventorySnapshot(warehouses, nil, nil), nil
+	}
+	ids := make([]string, len(wids))
+	for i, wid := range wids {
+		ids[i] = strconv.Itoa(wid)
+	}
+	wid := strings.Join(ids, ",")
+
+	inventoryParams := WarehouseInventoriesQueryParams{WID: wid}
+	inventories, err := queryAll(func(offset int) ([]WarehouseInventory, int, bool, error) {
+		inventoryParams.Offset = offset
+		return s.Inventories(inventoryParams)
+	})
+	if err != nil {
+		return
+	}
+
+	bins := make([]WarehouseInventoryBin, 0)
+	if withBins {
+		binParams := WarehouseInventoryBinsQueryParams{WID: wid}
+		if bins, err = queryAll(func(offset int) ([]WarehouseInventoryBin, int, bool, error) {
+			binParams.Offset = offset
+			return s.InventoryBins(binParams)
+		}); err != nil {
+			return
+		}
+	}
+	return NewWarehouseInventorySnapshot(warehouses, inventories, bins), nil
+}
@@ -0,0 +1,36 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWarehouseInventoryBinsQueryParams_Validate(t *testing.T) {
+	assert.Nil(t, WarehouseInventoryBinsQueryParams{BinType: "5,6"}.Validate())
+	assert.NotNil(t, WarehouseInventoryBinsQueryParams{BinType: "5,9"}.Validate())
+	assert.NotNil(t, WarehouseInventoryBinsQueryParams{BinType: "a"}.Validate())
+}
+
+func TestNewWarehouseInventorySnapshot(t *testing.T) {
+	snapshot := NewWarehouseInventorySnapshot(
+		[]Warehouse{{WID: 1, Name: "SZ"}, {WID: 2, Name: "GZ"}},
+		[]WarehouseInventory{
+			{WID: 2, SKU: "A", ProductTotal: 5, ProductValidNum: 5},
+			{WID: 1, SKU: "B", ProductTotal: 3, ProductValidNum: 1, ProductLockNum: 2},
+			{WID: 1, SKU: "a", FNSKU: "X001", ProductTotal: 10, ProductValidNum: 8, ProductBadNum: 2},
+		},
+		[]WarehouseInventoryBin{
+			{WID: 1, SKU: "a", FNSKU: "x001", WhbName: "A-01", Total: 10},
+			{WID: 2, SKU: "B", WhbName: "B-01", Total: 1},
+		},
+	)
+	assert.Len(t, snapshot.Items, 3)
+	assert.Equal(t, "SZ", snapshot.Items[0].WarehouseName)
+	assert.Equal(t, "a", snapshot.Items[0].SKU)
+	assert.Len(t, snapshot.Items[0].Bins, 1)
+	assert.Len(t, snapshot.Items[1].Bins, 0)
+	assert.Equal(t, "GZ", snapshot.Items[2].WarehouseName)
+	assert.Equal(t, WarehouseInventoryTotal{ProductTotal: 15, ProductValidNum: 13, ProductBadNum: 2}, snapshot.SKUs["A"])
+	assert.Equal(t, snapshot.SKUs["A"], snapshot.SKU(" a "))
+	assert.Len(t, snapshot.Warehouse(1), 2)
+}
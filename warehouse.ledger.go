@@ -0,0 +1,360 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/lingxing/constant"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 库存流水
+// 根据已完成的入库单和出库单生成库存变动记录（入库为正数、出库为负数），按仓库 + SKU 汇总变动和结存
+// 未设置库存快照时结存从 0 开始累计，仅反映已加载单据的净变动；
+// 设置库存快照后以快照时间的实际库存总量为基准，快照时间之前的结存根据之后的变动倒推，SKU 不区分大小写
+
+// InventoryMovement 库存变动
+type InventoryMovement struct {
+	WID           string `json:"wid"`             // 仓库 ID
+	WarehouseName string `json:"warehouse_name"`  // 仓库名称
+	SKU           string `json:"sku"`             // SKU
+	FnSKU         string `json:"fnsku"`           // FNSKU
+	SellerId      string `json:"seller_id"`       // 店铺 ID
+	ProductName   string `json:"product_name"`    // 品名
+	Time          string `json:"time"`            // 出入库时间
+	IsInbound     bool   `json:"is_inbound"`      // 是否为入库
+	OrderSN       string `json:"order_sn"`        // 出入库单号
+	OrderType     int    `json:"order_type"`      // 出入库类型
+	OrderTypeText string `json:"order_type_text"` // 出入库类型名称
+	SourceSN      string `json:"source_sn"`       // 关联单据号
+	Quantity      int    `json:"quantity"`        // 变动数量（入库为正数、出库为负数）
+	Balance       int    `json:"balance"`         // 变动后结存
+}
+
+// InventoryLedgerBalance 仓库 SKU 期间汇总
+type InventoryLedgerBalance struct {
+	WID              string `json:"wid"`               // 仓库 ID
+	WarehouseName    string `json:"warehouse_name"`    // 仓库名称
+	SKU              string `json:"sku"`               // SKU
+	OpeningBalance   int    `json:"opening_balance"`   // 期初结存
+	InboundQuantity  int    `json:"inbound_quantity"`  // 期间入库量
+	OutboundQuantity int    `json:"outbound_quantity"` // 期间出库量（正数）
+	ClosingBalance   int    `json:"closing_balance"`   // 期末结存
+}
+
+// inventoryLedgerStock 库存快照中仓库 SKU 的实际库存总量
+type inventoryLedgerStock struct {
+	WID           string
+	WarehouseName string
+	SKU           string
+	Quantity      int
+}
+
+// InventoryLedger 库存流水台账
+type InventoryLedger struct {
+	movements    map[string][]InventoryMovement   // 键为仓库 ID + SKU
+	orderSNs     map[string]bool                  // 已添加的单号，避免重复添加
+	stocks       map[string]*inventoryLedgerStock // 库存快照，键为仓库 ID + SKU
+	snapshotTime string                           // 库存快照时间
+	bases        map[string]int                   // 第一条变动之前的结存
+	sorted       bool
+}
+
+func NewInventoryLedger() *InventoryLedger {
+	return &InventoryLedger{
+		movements: make(map[string][]InventoryMovement),
+		orderSNs:  make(map[string]bool),
+		stocks:    make(map[string]*inventoryLedgerStock),
+		bases:     make(map[string]int),
+	}
+}
+
+// SetInventorySnapshot 设置库存快照作为结存基准，快照时间的结存等于快照中的实际库存总量（同一仓库 SKU 的所有 FNSKU 合计）
+// 快照时间与出入库时间按相同时区比较，需要加载快照时间之后的所有已完成单据才能正确倒推之前的结存
+func (l *InventoryLedger) SetInventorySnapshot(snapshot WarehouseInventorySnapshot) {
+	l.stocks = make(map[string]*inventoryLedgerStock)
+	l.snapshotTime = snapshot.Time.Format(constant.DatetimeFormat)
+	for _, item := range snapshot.Items {
+		if item.SKU == "" {
+			continue
+		}
+		wid := strconv.Itoa(item.WID)
+		key := inventoryLedgerKey(wid, item.SKU)
+		stock, ok := l.stocks[key]
+		if !ok {
+			stock = &inventoryLedgerStock{WID: wid, WarehouseName: item.WarehouseName, SKU: item.SKU}
+			l.stocks[key] = stock
+		}
+		stock.Quantity += item.ProductTotal
+	}
+	l.sorted = false
+}
+
+func inventoryLedgerKey(wid, sku string) string {
+	return strings.TrimSpace(wid) + "\x00" + skuKey(sku)
+}
+
+func (l *InventoryLedger) add(m InventoryMovement) {
+	if m.SKU == "" || m.Quantity == 0 {
+		return
+	}
+	key := inventoryLedgerKey(m.WID, m.SKU)
+	l.movements[key] = append(l.movements[key], m)
+	l.sorted = false
+}
+
+// AddInboundOrders 添加入库单，仅已完成的入库单产生库存变动，重复的单号将被忽略
+func (l *InventoryLedger) AddInboundOrders(orders ...InboundOrder) {
+	for _, order := range orders {
+		if order.Status != InboundOrderStatusCompleted || l.orderSNs["I"+order.OrderSN] {
+			continue
+		}
+		l.orderSNs["I"+order.OrderSN] = true
+		for _, item := range order.ItemList {
+			l.add(InventoryMovement{
+				WID:           order.WID,
+				WarehouseName: order.WarehouseName,
+				SKU:           item.SKU,
+				FnSKU:         item.FnSKU,
+				SellerId:      item.SellerId,
+				ProductName:   item.ProductName,
+				Time:          order.OptTime,
+				IsInbound:     true,
+				OrderSN:       order.OrderSN,
+				OrderType:     order.Type,
+				OrderTypeText: order.TypeText,
+				SourceSN:      order.SourceSN,
+				Quantity:      outboundInboundOrderItemQuantity(item),
+			})
+		}
+	}
+}
+
+// AddOutboundOrders 添加出库单，仅已完成的出库单产生库存变动，重复的单号将被忽略
+func (l *InventoryLedger) AddOutboundOrders(orders ...OutboundOrder) {
+	for _, order := range orders {
+		if order.Status != OutboundOrderStatusCompleted || l.orderSNs["O"+order.OrderSN] {
+			continue
+		}
+		l.orderSNs["O"+order.OrderSN] = true
+		for _, item := range order.ItemList {
+			l.add(InventoryMovement{
+				WID:           order.WID,
+				WarehouseName: order.WarehouseName,
+				SKU:           item.SKU,
+				FnSKU:         item.FnSKU,
+				SellerId:      item.SellerId,
+				ProductName:   item.ProductName,
+				Time:          order.OptTime,
+				OrderSN:       order.OrderSN,
+				OrderType:     order.Type,
+				OrderTypeText: order.TypeText,
+				SourceSN:      order.SourceSN,
+				Quantity:      -outboundInboundOrderItemQuantity(item),
+			})
+		}
+	}
+}
+
+// sort 按时间排序（同一时间入库在前）并计算结存
+func (l *InventoryLedger) sort() {
+	if l.sorted {
+		return
+	}
+	l.bases = make(map[string]int)
+	for key, movements := range l.movements {
+		sort.SliceStable(movements, func(i, j int) bool {
+			if movements[i].Time != movements[j].Time {
+				return movements[i].Time < movements[j].Time
+			}
+			return movements[i].IsInbound && !movements[j].IsInbound
+		})
+		balance := 0
+		if stock, ok := l.stocks[key]; ok {
+			// 快照时间的结存减去快照时间之前的变动即为第一条变动之前的结存
+			balance = stock.Quantity
+			for _, m := range movements {
+				if m.Time > l.snapshotTime {
+					break
+				}
+				balance -= m.Quantity
+			}
+		}
+		l.bases[key] = balance
+		for i := range movements {
+			balance += movements[i].Quantity
+			movements[i].Balance = balance
+		}
+	}
+	l.sorted = true
+}
+
+// base 第一条变动之前的结存，没有变动时为库存快照中的实际库存总量
+func (l *InventoryLedger) base(key string) int {
+	if balance, ok := l.bases[key]; ok {
+		return balance
+	}
+	if stock, ok := l.stocks[key]; ok {
+		return stock.Quantity
+	}
+	return 0
+}
+
+// inDateRange 判断时间是否在日期范围（Y-m-d）内，开始日期为闭区间，结束日期为开区间，日期为空时不限制
+func inDateRange(t, startDate, endDate string) bool {
+	date := t
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	if startDate != "" && date < startDate {
+		return false
+	}
+	if endDate != "" && date >= endDate {
+		return false
+	}
+	return true
+}
+
+// History 查询仓库 SKU 在日期范围（Y-m-d，开始日期为闭区间，结束日期为开区间）内的库存变动，返回期初结存和变动记录（按时间升序）
+func (l *InventoryLedger) History(wid, sku, startDate, endDate string) (openingBalance int, movements []InventoryMovement) {
+	l.sort()
+	key := inventoryLedgerKey(wid, sku)
+	openingBalance = l.base(key)
+	movements = make([]InventoryMovement, 0)
+	for _, m := range l.movements[key] {
+		if startDate != "" && inDateRange(m.Time, "", startDate) {
+			openingBalance = m.Balance
+			continue
+		}
+		if !inDateRange(m.Time, startDate, endDate) {
+			break
+		}
+		movements = append(movements, m)
+	}
+	return
+}
+
+// Balance 仓库 SKU 截至日期（Y-m-d，开区间）的结存，日期为空时返回全部变动的结存
+func (l *InventoryLedger) Balance(wid, sku, date string) int {
+	l.sort()
+	key := inventoryLedgerKey(wid, sku)
+	balance := l.base(key)
+	for _, m := range l.movements[key] {
+		if !inDateRange(m.Time, "", date) {
+			break
+		}
+		balance = m.Balance
+	}
+	return balance
+}
+
+// Balances 按仓库 + SKU 汇总日期范围（Y-m-d，开始日期为闭区间，结束日期为开区间）内的期初结存、出入库量和期末结存
+// wid 为空时返回所有仓库，结果按仓库 ID、SKU 排序
+func (l *InventoryLedger) Balances(wid, startDate, endDate string) []InventoryLedgerBalance {
+	l.sort()
+	balances := make([]InventoryLedgerBalance, 0)
+	keys := make([]string, 0, len(l.movements)+len(l.stocks))
+	for key := range l.movements {
+		keys = append(keys, key)
+	}
+	for key := range l.stocks {
+		if _, ok := l.movements[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		movements := l.movements[key]
+		var b InventoryLedgerBalance
+		if len(movements) > 0 {
+			b = InventoryLedgerBalance{WID: movements[0].WID, WarehouseName: movements[0].WarehouseName, SKU: movements[0].SKU}
+		} else if stock, ok := l.stocks[key]; ok {
+			b = InventoryLedgerBalance{WID: stock.WID, WarehouseName: stock.WarehouseName, SKU: stock.SKU}
+		} else {
+			continue
+		}
+		if wid != "" && b.WID != wid {
+			continue
+		}
+		b.OpeningBalance = l.base(key)
+		hasMovement := false
+		for _, m := range movements {
+			if startDate != "" && inDateRange(m.Time, "", startDate) {
+				b.OpeningBalance = m.Balance
+				continue
+			}
+			if !inDateRange(m.Time, startDate, endDate) {
+				break
+			}
+			hasMovement = true
+			if m.Quantity > 0 {
+				b.InboundQuantity += m.Quantity
+			} else {
+				b.OutboundQuantity -= m.Quantity
+			}
+		}
+		if !hasMovement && b.OpeningBalance == 0 {
+			continue
+		}
+		b.ClosingBalance = b.OpeningBalance + b.InboundQuantity - b.OutboundQuantity
+		balances = append(balances, b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].WID != balances[j].WID {
+			return balances[i].WID < balances[j].WID
+		}
+		return strings.ToUpper(balances[i].SKU) < strings.ToUpper(balances[j].SKU)
+	})
+	return balances
+}
+
+// InventoryLedger 以当前的库存快照为结存基准，加载所有仓库自开始日期（Y-m-d，闭区间）至今出入库的已完成单据并生成库存流水台账
+// 快照时间之后的变动需要全部加载才能倒推之前的结存，查询时请通过 History、Balances 的日期范围筛选
+func (s warehouseService) InventoryLedger(startDate string) (*InventoryLedger, error) {
+	ledger := NewInventoryLedger()
+	snapshot, err := s.InventorySnapshot(false)
+	if err != nil {
+		return nil, err
+	}
+	ledger.SetInventorySnapshot(snapshot)
+	warehouses, err := s.allWarehouses()
+	if err != nil {
+		return nil, err
+	}
+
+	// 结束日期为开区间
+	tomorrow := snapshot.Time.AddDate(0, 0, 1).Format(constant.DateFormat)
+	for _, warehouse := range warehouses {
+		wid := strconv.Itoa(warehouse.WID)
+		inboundParams := InboundOrdersQueryParams{
+			WID:             wid,
+			SearchFieldTime: "opt_time",
+			StartDate:       startDate,
+			EndDate:         tomorrow,
+			Status:          InboundOrderStatusCompleted,
+		}
+		inboundOrders, e := queryAll(func(offset int) ([]InboundOrder, int, bool, error) {
+			inboundParams.Offset = offset
+			return s.InboundOrders(inboundParams)
+		})
+		if e != nil {
+			return nil, e
+		}
+		ledger.AddInboundOrders(inboundOrders...)
+
+		outboundParams := OutboundOrdersQueryParams{
+			WID:             wid,
+			SearchFieldTime: "opt_time",
+			StartDate:       startDate,
+			EndDate:         tomorrow,
+			Status:          OutboundOrderStatusCompleted,
+		}
+		outboundOrders, e := queryAll(func(offset int) ([]OutboundOrder, int, bool, error) {
+			outboundParams.Offset = offset
+			return s.OutboundOrders(outboundParams)
+		})
+		if e != nil {
+			return nil, e
+		}
+		ledger.AddOutboundOrders(outboundOrders...)
+	}
+	return ledger, nil
+}
@@ -0,0 +1,78 @@
+package lingxing
+
+import (
+	"github.com/hiscaler/lingxing/constant"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInventoryLedger(t *testing.T) {
+	ledger := NewInventoryLedger()
+	ledger.AddInboundOrders(
+		InboundOrder{OrderSN: "IB1", WID: "1", Status: InboundOrderStatusCompleted, Type: InboundOrderTypePurchase, OptTime: "2022-09-01 10:00:00", SourceSN: "PO1", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductGoodNum: 90, ProductBadNum: 10}, {SKU: "B", ProductTotal: 5}}},
+		InboundOrder{OrderSN: "IB1", WID: "1", Status: InboundOrderStatusCompleted, OptTime: "2022-09-01 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 100}}},
+		InboundOrder{OrderSN: "IB2", WID: "1", Status: InboundOrderStatusPendingInbound, OptTime: "2022-09-02 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 100}}},
+		InboundOrder{OrderSN: "IB3", WID: "2", Status: InboundOrderStatusCompleted, OptTime: "2022-09-05 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 20}}},
+	)
+	ledger.AddOutboundOrders(
+		OutboundOrder{OrderSN: "OB1", WID: "1", Status: OutboundOrderStatusCompleted, Type: OutboundOrderTypeFBA, OptTime: "2022-09-08 09:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "a", ProductTotal: 40}}},
+		OutboundOrder{OrderSN: "OB2", WID: "1", Status: OutboundOrderStatusCompleted, OptTime: "2022-09-03 09:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 10}}},
+		OutboundOrder{OrderSN: "OB3", WID: "1", Status: OutboundOrderStatusRevoked, OptTime: "2022-09-04 09:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 10}}},
+	)
+
+	assert.Equal(t, 90, ledger.Balance("1", "a", "2022-09-08"))
+	assert.Equal(t, 50, ledger.Balance("1", "A", ""))
+	assert.Equal(t, 20, ledger.Balance("2", "A", ""))
+
+	opening, movements := ledger.History("1", "A", "2022-09-02", "2022-09-10")
+	assert.Equal(t, 100, opening)
+	if assert.Len(t, movements, 2) {
+		assert.Equal(t, "OB2", movements[0].OrderSN)
+		assert.Equal(t, -10, movements[0].Quantity)
+		assert.Equal(t, 90, movements[0].Balance)
+		assert.Equal(t, "OB1", movements[1].OrderSN)
+		assert.Equal(t, OutboundOrderTypeFBA, movements[1].OrderType)
+		assert.Equal(t, 50, movements[1].Balance)
+	}
+
+	balances := ledger.Balances("", "2022-09-02", "2022-09-10")
+	if assert.Len(t, balances, 3) {
+		assert.Equal(t, InventoryLedgerBalance{WID: "1", SKU: "A", OpeningBalance: 100, OutboundQuantity: 50, ClosingBalance: 50}, balances[0])
+		assert.Equal(t, InventoryLedgerBalance{WID: "1", SKU: "B", OpeningBalance: 5, ClosingBalance: 5}, balances[1])
+		assert.Equal(t, InventoryLedgerBalance{WID: "2", SKU: "A", InboundQuantity: 20, ClosingBalance: 20}, balances[2])
+	}
+	assert.Len(t, ledger.Balances("2", "", ""), 1)
+}
+
+func TestInventoryLedger_SetInventorySnapshot(t *testing.T) {
+	ledger := NewInventoryLedger()
+	snapshotTime, _ := time.ParseInLocation(constant.DatetimeFormat, "2022-09-10 00:00:00", time.Local)
+	ledger.SetInventorySnapshot(WarehouseInventorySnapshot{
+		Time: snapshotTime,
+		Items: []WarehouseInventorySnapshotItem{
+			{WarehouseInventory: WarehouseInventory{WID: 1, SKU: "A", ProductTotal: 60}},
+			{WarehouseInventory: WarehouseInventory{WID: 1, SKU: "a", FNSKU: "X001", ProductTotal: 10}},
+			{WarehouseInventory: WarehouseInventory{WID: 1, SKU: "C", ProductTotal: 8}, WarehouseName: "SZ"},
+		},
+	})
+	ledger.AddInboundOrders(InboundOrder{OrderSN: "IB1", WID: "1", Status: InboundOrderStatusCompleted, OptTime: "2022-09-01 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 100}}})
+	ledger.AddOutboundOrders(
+		OutboundOrder{OrderSN: "OB1", WID: "1", Status: OutboundOrderStatusCompleted, OptTime: "2022-09-05 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 40}}},
+		OutboundOrder{OrderSN: "OB2", WID: "1", Status: OutboundOrderStatusCompleted, OptTime: "2022-09-12 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 30}}},
+	)
+
+	// 快照时间结存 70，倒推第一条变动之前的结存为 70 + 40 - 100 = 10
+	assert.Equal(t, 10, ledger.Balance("1", "A", "2022-09-01"))
+	assert.Equal(t, 70, ledger.Balance("1", "A", "2022-09-10"))
+	assert.Equal(t, 40, ledger.Balance("1", "A", ""))
+	opening, movements := ledger.History("1", "A", "", "2022-09-06")
+	assert.Equal(t, 10, opening)
+	assert.Len(t, movements, 2)
+
+	balances := ledger.Balances("1", "2022-09-01", "2022-09-10")
+	if assert.Len(t, balances, 2) {
+		assert.Equal(t, InventoryLedgerBalance{WID: "1", SKU: "A", OpeningBalance: 10, InboundQuantity: 100, OutboundQuantity: 40, ClosingBalance: 70}, balances[0])
+		assert.Equal(t, InventoryLedgerBalance{WID: "1", WarehouseName: "SZ", SKU: "C", OpeningBalance: 8, ClosingBalance: 8}, balances[1])
+	}
+}
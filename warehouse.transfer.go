@@ -0,0 +1,274 @@
+package lingxing
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/lingxing/constant"
+	jsoniter "github.com/json-iterator/go"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 仓库调拨
+// 调拨由调出仓库的调拨出库单（出库类型 15）和调入仓库的调拨入库单（入库类型 3）组成，
+// 调拨入库单的关联单据号（source_sn）为调拨出库单的单号
+
+// 创建调拨单
+
+type CreateWarehouseTransferItem struct {
+	SKU      string `json:"sku"`                 // SKU
+	FNSKU    string `json:"fnsku,omitempty"`     // FNSKU
+	SellerId string `json:"seller_id,omitempty"` // 店铺 ID
+	Quantity int    `json:"quantity"`            // 调拨量
+}
+
+func (m CreateWarehouseTransferItem) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SKU, validation.Required.Error("SKU 不能为空")),
+		validation.Field(&m.Quantity,
+			validation.Required.Error("调拨量不能为空"),
+			validation.Min(1).Error("调拨量不能小于 {{.threshold}}"),
+		),
+	)
+}
+
+type CreateWarehouseTransferRequest struct {
+	WID    int                           `json:"wid"`              // 调出仓库 ID
+	ToWID  int                           `json:"to_wid"`           // 调入仓库 ID
+	Remark string                        `json:"remark,omitempty"` // 备注
+	Items  []CreateWarehouseTransferItem `json:"product_list"`     // 调拨产品
+}
+
+func (m CreateWarehouseTransferRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.WID, validation.Required.Error("调出仓库不能为空")),
+		validation.Field(&m.ToWID,
+			validation.Required.Error("调入仓库不能为空"),
+			validation.NotIn(m.WID).Error("调入仓库不能与调出仓库相同"),
+		),
+		validation.Field(&m.Items, validation.Required.Error("调拨产品不能为空")),
+	)
+}
+
+// CreateTransfer 创建调拨单，返回调拨出库单号
+func (s warehouseService) CreateTransfer(req CreateWarehouseTransferRequest) (orderSN string, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	res := struct {
+		NormalResponse
+		Data struct {
+			OrderSN string `json:"order_sn"`
+		} `json:"data"`
+	}{}
+	resp, err := s.httpClient.R().
+		SetBody(req).
+		Post("/routing/storage/transfer/create")
+	if err != nil {
+		return
+	}
+
+	if err = jsoniter.Unmarshal(resp.Body(), &res); err == nil {
+		orderSN = res.Data.OrderSN
+	}
+	return
+}
+
+// 调拨视图
+
+// WarehouseTransferItem 调拨产品
+type WarehouseTransferItem struct {
+	SKU               string `json:"sku"`                 // SKU
+	FNSKU             string `json:"fnsku"`               // FNSKU
+	ProductName       string `json:"product_name"`        // 品名
+	ShippedQuantity   int    `json:"shipped_quantity"`    // 调出量
+	ReceivedQuantity  int    `json:"received_quantity"`   // 调入量
+	InTransitQuantity int    `json:"in_transit_quantity"` // 在途量（调出量 - 调入量，负数表示多收）
+}
+
+// WarehouseTransfer 调拨
+type WarehouseTransfer struct {
+	OutboundOrderSN   string                  `json:"outbound_order_sn"`   // 调拨出库单号
+	InboundOrderSNs   []string                `json:"inbound_order_sns"`   // 调拨入库单号
+	WID               string                  `json:"wid"`                 // 调出仓库 ID
+	WarehouseName     string                  `json:"warehouse_name"`      // 调出仓库名称
+	ToWID             string                  `json:"to_wid"`              // 调入仓库 ID
+	ToWarehouseName   string                  `json:"to_warehouse_name"`   // 调入仓库名称
+	OutboundStatus    OutboundOrderStatus     `json:"outbound_status"`     // 出库单状态
+	ShippedTime       string                  `json:"shipped_time"`        // 出库时间
+	ReceivedTime      string                  `json:"received_time"`       // 最后入库时间
+	ShippedQuantity   int                     `json:"shipped_quantity"`    // 调出量
+	ReceivedQuantity  int                     `json:"received_quantity"`   // 调入量
+	InTransitQuantity int                     `json:"in_transit_quantity"` // 在途量
+	IsCompleted       bool                    `json:"is_completed"`        // 是否已完成（已出库且所有产品均已入库）
+	HasDiscrepancy    bool                    `json:"has_discrepancy"`     // 是否存在差异（已出库且调拨入库单均已完成，但入库量与出库量不一致；或入库量超过出库量）
+	Items             []WarehouseTransferItem `json:"items"`               // 调拨产品
+}
+
+// outboundInboundOrderItemQuantity 出入库量，入库量为空时取良品量 + 次品量
+func outboundInboundOrderItemQuantity(item OutboundInboundOrderItem) int {
+	if item.ProductTotal != 0 {
+		return item.ProductTotal
+	}
+	return item.ProductGoodNum + item.ProductBadNum
+}
+
+// PairTransfers 根据关联单据号将调拨出库单和调拨入库单配对为调拨，返回调拨（按调拨出库单号排序）和无法配对的调拨入库单
+// 非调拨类型的出入库单和已撤销的出入库单将被忽略，调出量仅统计已完成的调拨出库单，调入量仅统计已完成的调拨入库单
+// 调拨出库单未完成或存在未完成的调拨入库单时视为调拨中，入库量少于出库量不视为差异
+func PairTransfers(outboundOrders []OutboundOrder, inboundOrders []InboundOrder) (transfers []WarehouseTransfer, orphanInboundOrders []InboundOrder) {
+	transfers = make([]WarehouseTransfer, 0)
+	orphanInboundOrders = make([]InboundOrder, 0)
+	itemKey := func(sku, fnSKU string) string {
+		return strings.ToUpper(sku) + "\x00" + strings.ToUpper(fnSKU)
+	}
+
+	type transfer struct {
+		WarehouseTransfer
+		items   map[string]*WarehouseTransferItem
+		keys    []string
+		pending bool // 是否存在未完成的调拨入库单
+	}
+	outbounds := make(map[string]*transfer)
+	orderSNs := make([]string, 0)
+	for _, order := range outboundOrders {
+		if order.Type != OutboundOrderTypeTransfer || order.Status == OutboundOrderStatusRevoked {
+			continue
+		}
+		if _, ok := outbounds[order.OrderSN]; ok {
+			continue
+		}
+		t := &transfer{
+			WarehouseTransfer: WarehouseTransfer{
+				OutboundOrderSN: order.OrderSN,
+				InboundOrderSNs: make([]string, 0),
+				WID:             order.WID,
+				WarehouseName:   order.WarehouseName,
+				ToWID:           order.ToWID,
+				ToWarehouseName: order.ToWarehouseName,
+				OutboundStatus:  order.Status,
+				ShippedTime:     order.OptTime,
+			},
+			items: make(map[string]*WarehouseTransferItem),
+			keys:  make([]string, 0),
+		}
+		for _, item := range order.ItemList {
+			k := itemKey(item.SKU, item.FnSKU)
+			v, ok := t.items[k]
+			if !ok {
+				v = &WarehouseTransferItem{SKU: item.SKU, FNSKU: item.FnSKU, ProductName: item.ProductName}
+				t.items[k] = v
+				t.keys = append(t.keys, k)
+			}
+			if order.Status == OutboundOrderStatusCompleted {
+				v.ShippedQuantity += outboundInboundOrderItemQuantity(item)
+			}
+		}
+		outbounds[order.OrderSN] = t
+		orderSNs = append(orderSNs, order.OrderSN)
+	}
+
+	for _, order := range inboundOrders {
+		if order.Type != InboundOrderTypeTransfer || order.Status == InboundOrderStatusRevoked {
+			continue
+		}
+		t, ok := outbounds[order.SourceSN]
+		if !ok {
+			orphanInboundOrders = append(orphanInboundOrders, order)
+			continue
+		}
+		t.InboundOrderSNs = append(t.InboundOrderSNs, order.OrderSN)
+		if order.Status != InboundOrderStatusCompleted {
+			// 未完成的入库单尚未入库
+			t.pending = true
+			continue
+		}
+		if order.OptTime > t.ReceivedTime {
+			t.ReceivedTime = order.OptTime
+		}
+		for _, item := range order.ItemList {
+			k := itemKey(item.SKU, item.FnSKU)
+			v, ok := t.items[k]
+			if !ok {
+				v = &WarehouseTransferItem{SKU: item.SKU, FNSKU: item.FnSKU, ProductName: item.ProductName}
+				t.items[k] = v
+				t.keys = append(t.keys, k)
+			}
+			v.ReceivedQuantity += outboundInboundOrderItemQuantity(item)
+		}
+	}
+
+	sort.Strings(orderSNs)
+	for _, orderSN := range orderSNs {
+		t := outbounds[orderSN]
+		shipped := t.OutboundStatus == OutboundOrderStatusCompleted
+		settled := shipped && !t.pending && len(t.InboundOrderSNs) > 0
+		t.Items = make([]WarehouseTransferItem, 0, len(t.keys))
+		for _, k := range t.keys {
+			item := t.items[k]
+			item.InTransitQuantity = item.ShippedQuantity - item.ReceivedQuantity
+			t.ShippedQuantity += item.ShippedQuantity
+			t.ReceivedQuantity += item.ReceivedQuantity
+			if item.InTransitQuantity < 0 || (settled && item.InTransitQuantity != 0) {
+				t.HasDiscrepancy = true
+			}
+			t.Items = append(t.Items, *item)
+		}
+		t.InTransitQuantity = t.ShippedQuantity - t.ReceivedQuantity
+		t.IsCompleted = shipped && t.ReceivedQuantity > 0 && t.InTransitQuantity <= 0
+		transfers = append(transfers, t.WarehouseTransfer)
+	}
+	return
+}
+
+// Transfers 查询指定日期范围（Y-m-d）内创建的调拨
+// 调拨入库单的查询截止日期为当前日期，以便包含在查询范围之后入库的调拨入库单
+func (s warehouseService) Transfers(startDate, endDate string) (transfers []WarehouseTransfer, orphanInboundOrders []InboundOrder, err error) {
+	warehouses, err := s.allWarehouses()
+	if err != nil {
+		return
+	}
+
+	outboundOrders := make([]OutboundOrder, 0)
+	inboundOrders := make([]InboundOrder, 0)
+	// 结束日期为开区间
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(constant.DateFormat)
+	for _, warehouse := range warehouses {
+		wid := strconv.Itoa(warehouse.WID)
+		outboundParams := OutboundOrdersQueryParams{
+			WID:             wid,
+			SearchFieldTime: "create_time",
+			StartDate:       startDate,
+			EndDate:         endDate,
+			Type:            OutboundOrderTypeTransfer,
+		}
+		outbounds, e := queryAll(func(offset int) ([]OutboundOrder, int, bool, error) {
+			outboundParams.Offset = offset
+			return s.OutboundOrders(outboundParams)
+		})
+		if e != nil {
+			return nil, nil, e
+		}
+		outboundOrders = append(outboundOrders, outbounds...)
+
+		inboundParams := InboundOrdersQueryParams{
+			WID:             wid,
+			SearchFieldTime: "create_time",
+			StartDate:       startDate,
+			EndDate:         tomorrow,
+			Type:            InboundOrderTypeTransfer,
+		}
+		inbounds, e := queryAll(func(offset int) ([]InboundOrder, int, bool, error) {
+			inboundParams.Offset = offset
+			return s.InboundOrders(inboundParams)
+		})
+		if e != nil {
+			return nil, nil, e
+		}
+		inboundOrders = append(inboundOrders, inbounds...)
+	}
+	transfers, orphanInboundOrders = PairTransfers(outboundOrders, inboundOrders)
+	return
+}
@@ -0,0 +1,73 @@
+package lingxing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateWarehouseTransferRequest_Validate(t *testing.T) {
+	req := CreateWarehouseTransferRequest{WID: 1, ToWID: 2, Items: []CreateWarehouseTransferItem{{SKU: "A", Quantity: 1}}}
+	assert.Nil(t, req.Validate())
+	req.ToWID = 1
+	assert.NotNil(t, req.Validate())
+	req.ToWID = 2
+	req.Items[0].Quantity = 0
+	assert.NotNil(t, req.Validate())
+}
+
+func TestPairTransfers(t *testing.T) {
+	outboundOrders := []OutboundOrder{
+		{OrderSN: "OB2", Type: OutboundOrderTypeTransfer, Status: 40, WID: "1", ToWID: "2", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 10}, {SKU: "B", ProductTotal: 5}}},
+		{OrderSN: "OB1", Type: OutboundOrderTypeTransfer, Status: 40, WID: "1", ToWID: "3", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 4}}},
+		{OrderSN: "OB3", Type: OutboundOrderTypeFBA, Status: 40},
+		{OrderSN: "OB4", Type: OutboundOrderTypeTransfer, Status: 50},
+		{OrderSN: "OB5", Type: OutboundOrderTypeTransfer, Status: 30, ItemList: []OutboundInboundOrderItem{{SKU: "C", ProductTotal: 6}}},
+		{OrderSN: "OB6", Type: OutboundOrderTypeTransfer, Status: 40, ItemList: []OutboundInboundOrderItem{{SKU: "D", ProductTotal: 8}}},
+	}
+	inboundOrders := []InboundOrder{
+		{OrderSN: "IB1", SourceSN: "OB2", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusCompleted, OptTime: "2022-09-02 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductGoodNum: 9, ProductBadNum: 1}}},
+		{OrderSN: "IB2", SourceSN: "OB2", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusCompleted, OptTime: "2022-09-03 10:00:00", ItemList: []OutboundInboundOrderItem{{SKU: "b", ProductTotal: 4}}},
+		{OrderSN: "IB3", SourceSN: "OB1", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusPendingInbound, ItemList: []OutboundInboundOrderItem{{SKU: "A", ProductTotal: 4}}},
+		{OrderSN: "IB4", SourceSN: "OB9", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusCompleted},
+		{OrderSN: "IB5", SourceSN: "OB2", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusRevoked},
+		{OrderSN: "IB6", Type: InboundOrderTypePurchase, Status: InboundOrderStatusCompleted},
+		{OrderSN: "IB7", SourceSN: "OB6", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusCompleted, ItemList: []OutboundInboundOrderItem{{SKU: "D", ProductTotal: 5}}},
+		{OrderSN: "IB8", SourceSN: "OB6", Type: InboundOrderTypeTransfer, Status: InboundOrderStatusPendingInbound, ItemList: []OutboundInboundOrderItem{{SKU: "D", ProductTotal: 3}}},
+	}
+	transfers, orphans := PairTransfers(outboundOrders, inboundOrders)
+	assert.Len(t, transfers, 4)
+	assert.Len(t, orphans, 1)
+	assert.Equal(t, "IB4", orphans[0].OrderSN)
+
+	ob1 := transfers[0]
+	assert.Equal(t, "OB1", ob1.OutboundOrderSN)
+	assert.Equal(t, []string{"IB3"}, ob1.InboundOrderSNs)
+	assert.Equal(t, 4, ob1.InTransitQuantity)
+	assert.False(t, ob1.IsCompleted)
+	assert.False(t, ob1.HasDiscrepancy)
+
+	ob2 := transfers[1]
+	assert.Equal(t, []string{"IB1", "IB2"}, ob2.InboundOrderSNs)
+	assert.Equal(t, "2022-09-03 10:00:00", ob2.ReceivedTime)
+	assert.Equal(t, 15, ob2.ShippedQuantity)
+	assert.Equal(t, 14, ob2.ReceivedQuantity)
+	assert.Equal(t, 1, ob2.InTransitQuantity)
+	assert.True(t, ob2.HasDiscrepancy)
+	assert.Equal(t, 0, ob2.Items[0].InTransitQuantity)
+	assert.Equal(t, 1, ob2.Items[1].InTransitQuantity)
+
+	// 调拨出库单未完成，不统计调出量
+	ob5 := transfers[2]
+	assert.Equal(t, "OB5", ob5.OutboundOrderSN)
+	assert.Equal(t, 0, ob5.ShippedQuantity)
+	assert.False(t, ob5.IsCompleted)
+	assert.False(t, ob5.HasDiscrepancy)
+
+	// 部分入库，其余调拨入库单未完成
+	ob6 := transfers[3]
+	assert.Equal(t, 8, ob6.ShippedQuantity)
+	assert.Equal(t, 5, ob6.ReceivedQuantity)
+	assert.Equal(t, 3, ob6.InTransitQuantity)
+	assert.False(t, ob6.IsCompleted)
+	assert.False(t, ob6.HasDiscrepancy)
+}